@@ -18,11 +18,21 @@ import (
 )
 
 func main() {
+	// Check config files without starting: server validate-config [-config file | file...]
+	if len(os.Args) > 1 && os.Args[1] == "validate-config" {
+		os.Exit(validateConfig(os.Args[2:]))
+	}
+
 	// Parse command-line flags
 	configFile := flag.String("config", "config.yaml", "Path to configuration file")
+	printConfig := flag.Bool("print-config", false, "Print the effective configuration with secrets redacted and exit")
 
 	flag.Parse()
 
+	if *printConfig {
+		os.Exit(printEffectiveConfig(*configFile))
+	}
+
 	// Setup logger
 	logger := logrus.New()
 	logger.SetFormatter(&logrus.TextFormatter{
@@ -44,8 +54,24 @@ func main() {
 		logger.WithError(err).Fatal("Failed to load config")
 	}
 
-	// Initialize telemetry (use database name as network label)
-	telemetryService := telemetry.NewService(&cfg.Telemetry, cfg.ClickHouse.Database, logger)
+	for _, name := range config.DeprecatedEnv() {
+		logger.WithField("variable", name).Warn("The XATU_CBT_API_ environment prefix is deprecated, use CBT_API_")
+	}
+
+	level, err := logrus.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		logger.WithError(err).Fatal("Invalid log_level")
+	}
+
+	logger.SetLevel(level)
+
+	// Initialize telemetry. A single network labels every span; with several, spans carry their own.
+	networkName := ""
+	if networks, _ := cfg.ResolveNetworks(); len(networks) == 1 {
+		networkName = networks[0].Name
+	}
+
+	telemetryService := telemetry.NewService(&cfg.Telemetry, networkName, logger)
 
 	ctx := context.Background()
 	if serr := telemetryService.Start(ctx); serr != nil {
@@ -63,11 +89,32 @@ func main() {
 	}()
 
 	// Create API server
-	srv, err := server.New(cfg, logger)
+	srv, reloader, closeServer, err := server.New(cfg, logger)
 	if err != nil {
 		logger.WithError(err).Fatal("Failed to create server")
 	}
 
+	// Reload the configuration on SIGHUP or when the file changes
+	reload := func(trigger string) {
+		if rerr := reloader.Reload(*configFile); rerr != nil {
+			logger.WithError(rerr).WithField("trigger", trigger).Error("Config reload failed, keeping current config")
+		}
+	}
+
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+
+	go config.WatchFile(watchCtx, *configFile, cfg.Server.ConfigWatchInterval, func() { reload("file") })
+
+	go func() {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+
+		for range hup {
+			reload("sighup")
+		}
+	}()
+
 	// Create metrics server
 	metricsSrv := server.NewMetrics(cfg)
 
@@ -114,5 +161,10 @@ func main() {
 		logger.WithError(err).Error("Metrics server shutdown error")
 	}
 
+	// Close the slow query log and ClickHouse connections once no request can use them
+	if err := closeServer(); err != nil {
+		logger.WithError(err).Error("Failed to close server resources")
+	}
+
 	logger.Info("Servers stopped")
 }
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
+)
+
+// printEffectiveConfig prints the configuration after defaults and environment overrides, with
+// secrets redacted. Returns the process exit code.
+func printEffectiveConfig(configFile string) int {
+	cfg, err := config.Load(configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", configFile, err)
+
+		return 1
+	}
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(cfg.Redacted()); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode config: %v\n", err)
+
+		return 1
+	}
+
+	return 0
+}
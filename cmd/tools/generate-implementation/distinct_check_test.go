@@ -0,0 +1,56 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDistinctTest(t *testing.T) {
+	distinct := Endpoint{
+		Path:            "/api/v1/fct_block/distinct/{column}",
+		Method:          "GET",
+		OperationID:     "FctBlockService_Distinct",
+		HandlerName:     "FctBlockServiceDistinct",
+		Operation:       "Distinct",
+		ParamsType:      "FctBlockServiceDistinctParams",
+		TableName:       "fct_block",
+		DistinctColumns: []string{"status", "block_version"},
+		Parameters: []Param{
+			{Name: "slot_eq", Field: "slot", Operator: "eq", Type: "integer", Format: "uint32", GoType: "*uint32"},
+			{Name: "slot_gte", Field: "slot", Operator: "gte", Type: "integer", Format: "uint32", GoType: "*uint32"},
+			{Name: "slot_start_date_time_eq", Field: "slot_start_date_time", Operator: "eq", Type: "string", GoType: "*string"},
+			{Name: "gas_ratio_eq", Field: "gas_ratio", Operator: "eq", Type: "number", GoType: "*interface{}"},
+			{Name: "limit", Field: "limit", Type: "integer", Format: "int32", GoType: "*int32"},
+		},
+	}
+
+	// Tables without eligible columns or a List builder are left out
+	noColumns := distinct
+	noColumns.HandlerName = "FctEmptyServiceDistinct"
+	noColumns.DistinctColumns = nil
+
+	noBuilder := distinct
+	noBuilder.HandlerName = "FctOtherServiceDistinct"
+	noBuilder.TableName = "fct_other"
+
+	generator := &CodeGenerator{
+		spec: &OpenAPISpec{Endpoints: []Endpoint{distinct, noColumns, noBuilder}, Types: map[string]*Type{}},
+		protoInfo: &ProtoInfo{
+			QueryBuilders: map[string]string{"fct_block:List": "BuildListFctBlockQuery"},
+		},
+	}
+
+	got := generator.GenerateDistinctTest()
+
+	_, err := parser.ParseFile(token.NewFileSet(), "implementation_test.go", got, 0)
+	require.NoError(t, err)
+
+	assert.Contains(t, got, `s.FctBlockServiceDistinct(w, r, "status", handlers.FctBlockServiceDistinctParams{`+
+		`SlotEq: new(uint32), SlotStartDateTimeEq: distinctTestValue("0")})`)
+	assert.NotContains(t, got, "FctEmptyServiceDistinct")
+	assert.NotContains(t, got, "FctOtherServiceDistinct")
+}
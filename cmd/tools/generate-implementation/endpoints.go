@@ -2,8 +2,21 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
+)
+
+const (
+	// defaultPageSize is the page size used when a List request sets none.
+	defaultPageSize = 100
+
+	// defaultDistinctLimit is the number of values returned when a Distinct request sets no limit.
+	defaultDistinctLimit = 100
 )
 
 // generateEndpoints generates all endpoint implementations.
@@ -21,6 +34,9 @@ func generateEndpoints(spec *OpenAPISpec, protoInfo *ProtoInfo) string {
 		case "Get":
 			sb.WriteString(generateGetEndpoint(endpoint, protoInfo))
 			sb.WriteString("\n\n")
+		case "Distinct":
+			sb.WriteString(generateDistinctEndpoint(endpoint, protoInfo))
+			sb.WriteString("\n\n")
 		}
 	}
 
@@ -56,13 +72,13 @@ func (s *Server) %s(w http.ResponseWriter, r *http.Request, params handlers.%s)
 		),
 	)
 	defer span.End()
-
+%s
 	// Build proto request
 	req := &clickhouse.%s{
-		PageSize: 100, // default
+		PageSize: %d, // default
 	}
 
-%s
+%s%s
 	// Pagination
 	if params.PageSize != nil {
 		req.PageSize = *params.PageSize
@@ -75,25 +91,25 @@ func (s *Server) %s(w http.ResponseWriter, r *http.Request, params handlers.%s)
 	if params.OrderBy != nil {
 		req.OrderBy = *params.OrderBy
 		span.SetAttributes(attribute.String("query.order_by", *params.OrderBy))
-	}
+	}%s
 
 	// Use existing Query Builder
 	_, buildSpan := tracer.Start(ctx, "handler.buildQuery")
-	sqlQuery, err := clickhouse.%s(req, s.buildQueryOptions()...)
+	sqlQuery, err := clickhouse.%s(req, s.buildQueryOptions(ctx)...)
 	buildSpan.End()
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to build query")
-		writeError(w, http.StatusBadRequest, err)
+		s.writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
-
+%s
 	// Execute query (database wrapper creates child span)
 	rows, err := s.db.Query(ctx, sqlQuery.Query, sqlQuery.Args...)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "query failed")
-		writeError(w, http.StatusInternalServerError, err)
+		s.writeError(w, r, http.StatusInternalServerError, err)
 		return
 	}
 	defer rows.Close()
@@ -108,11 +124,20 @@ func (s *Server) %s(w http.ResponseWriter, r *http.Request, params handlers.%s)
 			scanSpan.End()
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "scan failed")
-			writeError(w, http.StatusInternalServerError, err)
+			s.writeError(w, r, http.StatusInternalServerError, err)
 			return
 		}
 		items = append(items, item)
 	}
+	// Exceptions raised mid-stream (e.g. memory limit) surface here rather than from Query
+	if err := rows.Err(); err != nil {
+		scanSpan.RecordError(err)
+		scanSpan.End()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "query failed")
+		s.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
 	scanSpan.SetAttributes(attribute.Int("result.count", len(items)))
 	scanSpan.End()
 
@@ -134,9 +159,14 @@ func (s *Server) %s(w http.ResponseWriter, r *http.Request, params handlers.%s)
 		ep.HandlerName, ep.OperationID, ep.Method, ep.Path,
 		ep.HandlerName, ep.ParamsType,
 		ep.HandlerName, ep.HandlerName,
+		generateDeprecationHeaders(ep.Overrides),
 		requestType,
+		listPageSize(ep.Overrides),
 		generateFilterAssignments(ep, protoInfo),
+		generateProjectionRouting(ep),
+		generateDefaultOrderBy(ep),
 		queryBuilder,
+		generateColumnTransformers(ep),
 		itemType,
 		itemType,
 		ep.ResponseType,
@@ -181,7 +211,7 @@ func (s *Server) %s(w http.ResponseWriter, r *http.Request, %s %s) {
 		),
 	)
 	defer span.End()
-
+%s
 	// Build proto request
 	req := &clickhouse.%s{
 		%s: %s,
@@ -189,21 +219,21 @@ func (s *Server) %s(w http.ResponseWriter, r *http.Request, %s %s) {
 
 	// Use existing Query Builder
 	_, buildSpan := tracer.Start(ctx, "handler.buildQuery")
-	sqlQuery, err := clickhouse.%s(req, s.buildQueryOptions()...)
+	sqlQuery, err := clickhouse.%s(req, s.buildQueryOptions(ctx)...)
 	buildSpan.End()
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "failed to build query")
-		writeError(w, http.StatusBadRequest, err)
+		s.writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
-
+%s
 	// Execute query (database wrapper creates child span)
 	rows, err := s.db.Query(ctx, sqlQuery.Query, sqlQuery.Args...)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "query failed")
-		writeError(w, http.StatusInternalServerError, err)
+		s.writeError(w, r, http.StatusInternalServerError, err)
 		return
 	}
 	defer rows.Close()
@@ -217,15 +247,24 @@ func (s *Server) %s(w http.ResponseWriter, r *http.Request, %s %s) {
 			scanSpan.End()
 			span.RecordError(err)
 			span.SetStatus(codes.Error, "scan failed")
-			writeError(w, http.StatusInternalServerError, err)
+			s.writeError(w, r, http.StatusInternalServerError, err)
 			return
 		}
 	} else {
+		// Next also returns false when the query fails mid-stream
+		if err := rows.Err(); err != nil {
+			scanSpan.RecordError(err)
+			scanSpan.End()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "query failed")
+			s.writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
 		scanSpan.SetAttributes(attribute.Bool("result.found", false))
 		scanSpan.End()
 		span.SetStatus(codes.Ok, "not found")
 		// Not found
-		w.WriteHeader(http.StatusNotFound)
+		%s
 		return
 	}
 	scanSpan.SetAttributes(attribute.Bool("result.found", true))
@@ -237,10 +276,262 @@ func (s *Server) %s(w http.ResponseWriter, r *http.Request, %s %s) {
 		ep.HandlerName, ep.OperationID, ep.Method, ep.Path,
 		ep.HandlerName, pathParamName, pathParamType,
 		ep.HandlerName, ep.HandlerName, pathParamName,
+		generateDeprecationHeaders(ep.Overrides),
 		requestType,
 		toPascalCase(pathParamName), pathParamName,
 		queryBuilder,
-		itemType)
+		generateColumnTransformers(ep),
+		itemType,
+		generateNotFound(ep.TableName, pathParamName))
+}
+
+// generateDistinctEndpoint generates a Distinct endpoint implementation. It builds the table's List
+// query from the same filters and wraps it with handlers.DistinctQuery.
+func generateDistinctEndpoint(ep Endpoint, protoInfo *ProtoInfo) string {
+	// Distinct operations reuse the List query builder and request type
+	key := ep.TableName + ":List"
+	queryBuilder := protoInfo.QueryBuilders[key]
+	requestType := protoInfo.RequestTypes[key]
+
+	if queryBuilder == "" {
+		return fmt.Sprintf("// Skipping %s - no query builder found for table %s:List\n",
+			ep.HandlerName, ep.TableName)
+	}
+
+	if len(ep.DistinctColumns) == 0 {
+		return fmt.Sprintf("// Skipping %s - no distinct columns defined\n", ep.HandlerName)
+	}
+
+	columns := make([]string, 0, len(ep.DistinctColumns))
+	for _, column := range ep.DistinctColumns {
+		columns = append(columns, strconv.Quote(column))
+	}
+
+	return fmt.Sprintf(`// %s implements the %s endpoint
+// %s %s
+func (s *Server) %s(w http.ResponseWriter, r *http.Request, column string, params handlers.%s) {
+	ctx := r.Context()
+	tracer := otel.Tracer("cbt-api/handlers")
+
+	// Create span for handler execution
+	ctx, span := tracer.Start(ctx, "handler.%s",
+		trace.WithAttributes(
+			attribute.String("handler.name", "%s"),
+			attribute.String("handler.operation", "Distinct"),
+			attribute.String("query.column", column),
+		),
+	)
+	defer span.End()
+%s
+	// Only String, LowCardinality and Enum columns
+	switch column {
+	case %s:
+	default:
+		apierrors.BadRequestf("cannot list distinct values of column '%%s'", column).WithFieldViolations([]apierrors.FieldViolation{{
+			Field:       "column",
+			Description: "not a String, LowCardinality or Enum column of %s",
+			Reason:      "UNKNOWN_COLUMN",
+			Value:       column,
+			Expected:    %q,
+		}}).WriteJSON(w)
+		return
+	}
+
+	limit := %d // default
+	if params.Limit != nil {
+		limit = int(*params.Limit)
+	}
+	counts := params.Counts != nil && *params.Counts
+	span.SetAttributes(attribute.Int("query.limit", limit), attribute.Bool("query.counts", counts))
+
+	// Build proto request; the page size only shapes the LIMIT that DistinctQuery removes
+	req := &clickhouse.%s{
+		PageSize: %d,
+	}
+
+%s%s
+	// Use existing Query Builder, without its ordering and paging
+	_, buildSpan := tracer.Start(ctx, "handler.buildQuery")
+	sqlQuery, err := clickhouse.%s(req, s.buildQueryOptions(ctx)...)
+	if err != nil {
+		buildSpan.End()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to build query")
+		s.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	sqlQuery.Query, err = handlers.DistinctQuery(sqlQuery.Query, column, limit, counts)
+	buildSpan.End()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "failed to build query")
+		// A List query DistinctQuery can't rewrite is a server fault, not a bad request
+		status := http.StatusBadRequest
+		if errors.Is(err, handlers.ErrUnsupportedListQuery) {
+			status = http.StatusInternalServerError
+		}
+		s.writeError(w, r, status, err)
+		return
+	}
+
+	// Execute query (database wrapper creates child span)
+	rows, err := s.db.Query(ctx, sqlQuery.Query, sqlQuery.Args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "query failed")
+		s.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	defer rows.Close()
+
+	// Scan value, frequency (NULL without counts) and distinct count
+	_, scanSpan := tracer.Start(ctx, "handler.scanResults")
+	response := handlers.ListDistinctValuesResponse{
+		Values: make([]handlers.DistinctValue, 0, limit),
+	}
+	for rows.Next() {
+		var item handlers.DistinctValue
+		if err := rows.Scan(&item.Value, &item.Count, &response.DistinctCount); err != nil {
+			scanSpan.RecordError(err)
+			scanSpan.End()
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "scan failed")
+			s.writeError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		response.Values = append(response.Values, item)
+	}
+	// Exceptions raised mid-stream (e.g. memory limit) surface here rather than from Query
+	if err := rows.Err(); err != nil {
+		scanSpan.RecordError(err)
+		scanSpan.End()
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "query failed")
+		s.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	scanSpan.SetAttributes(attribute.Int("result.count", len(response.Values)))
+	scanSpan.End()
+
+	span.SetAttributes(attribute.Int("response.item_count", len(response.Values)))
+	span.SetStatus(codes.Ok, "")
+	writeJSON(w, response)
+}`,
+		ep.HandlerName, ep.OperationID, ep.Method, ep.Path,
+		ep.HandlerName, ep.ParamsType,
+		ep.HandlerName, ep.HandlerName,
+		generateDeprecationHeaders(ep.Overrides),
+		strings.Join(columns, ", "),
+		ep.TableName, strings.Join(ep.DistinctColumns, ", "),
+		defaultDistinctLimit,
+		requestType, defaultPageSize,
+		generateFilterAssignments(ep, protoInfo),
+		generateProjectionRouting(ep),
+		queryBuilder)
+}
+
+// listPageSize returns the default page size of a List endpoint, capped at the table's max_page_size.
+func listPageSize(overrides config.TableConfig) int {
+	if overrides.MaxPageSize > 0 && overrides.MaxPageSize < defaultPageSize {
+		return overrides.MaxPageSize
+	}
+
+	return defaultPageSize
+}
+
+// generateDefaultOrderBy generates the fallback to the table's configured order_by, or else to its
+// sorting key.
+func generateDefaultOrderBy(ep Endpoint) string {
+	switch {
+	case ep.Overrides.OrderBy != "":
+		return fmt.Sprintf(` else {
+		req.OrderBy = %q // tables.<table>.order_by
+	}`, ep.Overrides.OrderBy)
+	case ep.DefaultOrderBy != "":
+		return fmt.Sprintf(` else {
+		req.OrderBy = %q // The table's sorting key
+	}`, ep.DefaultOrderBy)
+	}
+
+	return ""
+}
+
+// generateColumnTransformers generates a wrapper query that drops the table's hidden columns, which
+// the generated types can't scan, and casts Enum columns encoded by value to Int16, as clickhouse-go
+// scans Enum columns as names. The subquery keeps its ORDER BY and LIMIT.
+func generateColumnTransformers(ep Endpoint) string {
+	var (
+		transformers []string
+		reasons      []string
+	)
+
+	if len(ep.Overrides.HiddenColumns) > 0 {
+		columns := make([]string, 0, len(ep.Overrides.HiddenColumns))
+		for _, column := range ep.Overrides.HiddenColumns {
+			columns = append(columns, "`"+column+"`")
+		}
+
+		transformers = append(transformers, "EXCEPT ("+strings.Join(columns, ", ")+")")
+		reasons = append(reasons, "Drop hidden columns (tables.<table>.hidden_columns)")
+	}
+
+	if len(ep.EnumValueColumns) > 0 {
+		casts := make([]string, 0, len(ep.EnumValueColumns))
+		for _, column := range ep.EnumValueColumns {
+			castType := "Int16"
+			if column.Nullable {
+				castType = "Nullable(Int16)"
+			}
+
+			casts = append(casts, fmt.Sprintf("CAST(`%s` AS %s) AS `%s`", column.Name, castType, column.Name))
+		}
+
+		transformers = append(transformers, "REPLACE ("+strings.Join(casts, ", ")+")")
+		reasons = append(reasons, "Return Enum values rather than names (api.encoding.enum)")
+	}
+
+	if len(transformers) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(`
+	// %s
+	sqlQuery.Query = %q + sqlQuery.Query + ")"
+`, strings.Join(reasons, "; "), "SELECT * "+strings.Join(transformers, " ")+" FROM (")
+}
+
+// generateDeprecationHeaders generates the Deprecation (RFC 9745) and Sunset (RFC 8594) headers of
+// a deprecated table. Without deprecated_since, Deprecation is "true" as in earlier drafts.
+func generateDeprecationHeaders(overrides config.TableConfig) string {
+	var sb strings.Builder
+
+	if overrides.Deprecated {
+		deprecation := "true"
+		if since, err := time.Parse(time.RFC3339, overrides.DeprecatedSince); err == nil {
+			deprecation = fmt.Sprintf("@%d", since.Unix())
+		}
+
+		fmt.Fprintf(&sb, "	w.Header().Set(\"Deprecation\", %q)\n", deprecation)
+	}
+
+	if sunset, err := time.Parse(time.RFC3339, overrides.Sunset); err == nil {
+		fmt.Fprintf(&sb, "	w.Header().Set(\"Sunset\", %q)\n", sunset.UTC().Format(http.TimeFormat))
+	}
+
+	if sb.Len() == 0 {
+		return ""
+	}
+
+	return "\n\t// Deprecated table (tables.<table>.deprecated)\n" + sb.String()
+}
+
+// generateNotFound generates the Get miss response naming the table and the requested key.
+func generateNotFound(tableName, pathParamName string) string {
+	return fmt.Sprintf(`apierrors.NotFoundf("resource not found: %s with %s=%%v", %s).WithMetadata(map[string]string{
+			"table": %q,
+			"key":   %q,
+		}).WriteJSON(w)`,
+		tableName, pathParamName, pathParamName, tableName, pathParamName)
 }
 
 // generateFilterAssignments generates filter field assignments from HTTP params to proto request.
@@ -285,6 +576,8 @@ func generateFilterAssignments(ep Endpoint, protoInfo *ProtoInfo) string {
 		builderFunc := "build" + filterType
 		builderArgs := generateBuilderArgs(params, filterType)
 
+		sb.WriteString(generateDateTimeParams(params))
+
 		sb.WriteString(fmt.Sprintf("\t// Filter: %s (%s)\n", field, filterType)) //nolint:staticcheck // template readability
 		sb.WriteString(fmt.Sprintf("\treq.%s = %s(%s)\n",                        //nolint:staticcheck // template readability
 			toPascalCase(field),
@@ -296,12 +589,142 @@ func generateFilterAssignments(ep Endpoint, protoInfo *ProtoInfo) string {
 	return sb.String()
 }
 
+// generateDateTimeParams generates code converting the DateTime filter parameters of a field to
+// the integer type of its filter, rejecting values that fail to parse with a 400.
+func generateDateTimeParams(params []Param) string {
+	var sb strings.Builder
+
+	for _, p := range params {
+		if p.DateTimeFormat == "" {
+			continue
+		}
+
+		fmt.Fprintf(&sb, `	%s, err := handlers.DateTimeParam[%s](%q, params.%s, %q, %d)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid parameter")
+		s.writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+`, dateTimeVar(p), p.DateTimeFormat, p.Name, toPascalCase(p.Name), p.DateTimeFormat, p.DateTimePrecision)
+	}
+
+	return sb.String()
+}
+
+// dateTimeVar returns the name of the variable holding a parsed DateTime filter parameter.
+func dateTimeVar(p Param) string {
+	name := toPascalCase(p.Name)
+
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// generateProjectionRouting generates code that routes a query to a projection when the request
+// filters on a field annotated with x-projection-name but not on the key the projection is an alternative for.
+func generateProjectionRouting(ep Endpoint) string {
+	type projection struct {
+		name           string
+		alternativeFor string
+		params         []string
+	}
+
+	// Group projection-annotated filter params by field
+	byField := make(map[string]*projection)
+	keyParams := make(map[string][]string)
+
+	for _, param := range ep.Parameters {
+		if param.Operator == "" {
+			continue
+		}
+
+		keyParams[param.Field] = append(keyParams[param.Field], param.Name)
+
+		if param.ProjectionName == "" {
+			continue
+		}
+
+		p, ok := byField[param.Field]
+		if !ok {
+			p = &projection{name: param.ProjectionName, alternativeFor: param.ProjectionAlternativeFor}
+			byField[param.Field] = p
+		}
+
+		p.params = append(p.params, param.Name)
+	}
+
+	if len(byField) == 0 {
+		return ""
+	}
+
+	// Sort fields for deterministic output
+	fields := make([]string, 0, len(byField))
+	for field := range byField {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+
+	var sb strings.Builder
+
+	sb.WriteString("\n\t// Projection routing: prefer a projection when filtering on its field without the primary key\n")
+
+	for i, field := range fields {
+		p := byField[field]
+
+		condition := paramsSetCondition(p.params)
+		if keys := keyParams[p.alternativeFor]; len(keys) > 0 {
+			condition = fmt.Sprintf("%s && !(%s)", wrapCondition(condition, len(p.params)), paramsSetCondition(keys))
+		}
+
+		if i == 0 {
+			sb.WriteString("\t")
+		} else {
+			sb.WriteString(" else ")
+		}
+
+		fmt.Fprintf(&sb, `if %s {
+		ctx = database.WithProjection(ctx, %q)
+		w.Header().Set("X-Query-Projection", %q)
+		span.SetAttributes(attribute.String("query.projection", %q))
+	}`, condition, p.name, p.name, p.name)
+	}
+
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// paramsSetCondition generates a condition that is true when any of the given params is set.
+func paramsSetCondition(params []string) string {
+	checks := make([]string, 0, len(params))
+	for _, name := range params {
+		checks = append(checks, "params."+toPascalCase(name)+" != nil")
+	}
+
+	return strings.Join(checks, " || ")
+}
+
+// wrapCondition parenthesises a condition joined from more than one check.
+func wrapCondition(condition string, checks int) string {
+	if checks > 1 {
+		return "(" + condition + ")"
+	}
+
+	return condition
+}
+
 // generateBuilderArgs generates the arguments for a filter builder function in the correct order.
 func generateBuilderArgs(params []Param, filterType string) string {
 	// Map operator → parameter
 	argMap := make(map[string]string)
 	for _, p := range params {
 		argMap[p.Operator] = "params." + toPascalCase(p.Name)
+
+		// DateTime filters arrive as strings, parsed by generateDateTimeParams
+		if p.DateTimeFormat != "" {
+			argMap[p.Operator] = dateTimeVar(p)
+		}
 	}
 
 	// Generate args in expected order based on filter type
@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
 )
 
 func TestGetItemType(t *testing.T) {
@@ -118,6 +120,15 @@ func TestGenerateBuilderArgs(t *testing.T) {
 			filterType: "UInt32Filter",
 			expected:   "nil, nil, nil, params.SlotLte, nil, params.SlotGte, nil, nil",
 		},
+		{
+			name: "datetime filter passed as string",
+			params: []Param{
+				{Name: "slot_start_date_time_gte", Operator: "gte", DateTimeFormat: "uint32"},
+				{Name: "event_date_time_lt", Operator: "lt", DateTimeFormat: "int64", DateTimePrecision: 3},
+			},
+			filterType: "UInt32Filter",
+			expected:   "nil, nil, eventDateTimeLt, nil, nil, slotStartDateTimeGte, nil, nil",
+		},
 		{
 			name: "string filter with contains",
 			params: []Param{
@@ -240,6 +251,28 @@ func TestGenerateFilterAssignments(t *testing.T) {
 			},
 			notInCode: []string{},
 		},
+		{
+			name: "endpoint with datetime filter",
+			endpoint: Endpoint{
+				TableName: "fct_block",
+				Parameters: []Param{
+					{Name: "slot_start_date_time_gte", Field: "slot_start_date_time", Operator: "gte", DateTimeFormat: "uint32"},
+				},
+			},
+			protoInfo: &ProtoInfo{
+				RequestFields: map[string]map[string]string{
+					"fct_block": {
+						"slot_start_date_time": "UInt32Filter",
+					},
+				},
+			},
+			expectedInCode: []string{
+				`slotStartDateTimeGte, err := handlers.DateTimeParam[uint32]("slot_start_date_time_gte", params.SlotStartDateTimeGte, "uint32", 0)`,
+				"s.writeError(w, r, http.StatusBadRequest, err)",
+				"req.SlotStartDateTime = buildUInt32Filter(nil, nil, nil, nil, nil, slotStartDateTimeGte, nil, nil)",
+			},
+			notInCode: []string{},
+		},
 		{
 			name: "endpoint with unmapped filter",
 			endpoint: Endpoint{
@@ -281,6 +314,83 @@ func TestGenerateFilterAssignments(t *testing.T) {
 	}
 }
 
+func TestGenerateProjectionRouting(t *testing.T) {
+	tests := []struct {
+		name           string
+		endpoint       Endpoint
+		expectedInCode []string
+		notInCode      []string
+	}{
+		{
+			name: "no projection annotations",
+			endpoint: Endpoint{
+				Parameters: []Param{
+					{Name: "slot_eq", Field: "slot", Operator: "eq"},
+				},
+			},
+			notInCode: []string{"WithProjection"},
+		},
+		{
+			name: "projection with alternative key",
+			endpoint: Endpoint{
+				Parameters: []Param{
+					{Name: "slot_start_date_time_eq", Field: "slot_start_date_time", Operator: "eq"},
+					{Name: "slot_start_date_time_gte", Field: "slot_start_date_time", Operator: "gte"},
+					{Name: "slot_eq", Field: "slot", Operator: "eq", ProjectionName: "p_by_slot", ProjectionAlternativeFor: "slot_start_date_time"},
+					{Name: "slot_gte", Field: "slot", Operator: "gte", ProjectionName: "p_by_slot", ProjectionAlternativeFor: "slot_start_date_time"},
+					{Name: "page_size", Field: "page_size"},
+				},
+			},
+			expectedInCode: []string{
+				"if (params.SlotEq != nil || params.SlotGte != nil) && !(params.SlotStartDateTimeEq != nil || params.SlotStartDateTimeGte != nil) {",
+				`ctx = database.WithProjection(ctx, "p_by_slot")`,
+				`w.Header().Set("X-Query-Projection", "p_by_slot")`,
+				`span.SetAttributes(attribute.String("query.projection", "p_by_slot"))`,
+			},
+			notInCode: []string{"PageSize"},
+		},
+		{
+			name: "projection without alternative key params",
+			endpoint: Endpoint{
+				Parameters: []Param{
+					{Name: "block_root_eq", Field: "block_root", Operator: "eq", ProjectionName: "p_by_root", ProjectionAlternativeFor: "slot"},
+				},
+			},
+			expectedInCode: []string{
+				"if params.BlockRootEq != nil {",
+				`ctx = database.WithProjection(ctx, "p_by_root")`,
+			},
+		},
+		{
+			name: "multiple projections are chained",
+			endpoint: Endpoint{
+				Parameters: []Param{
+					{Name: "slot_eq", Field: "slot", Operator: "eq", ProjectionName: "p_by_slot"},
+					{Name: "block_root_eq", Field: "block_root", Operator: "eq", ProjectionName: "p_by_root"},
+				},
+			},
+			expectedInCode: []string{
+				"\tif params.BlockRootEq != nil {",
+				"} else if params.SlotEq != nil {",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := generateProjectionRouting(tt.endpoint)
+
+			for _, expected := range tt.expectedInCode {
+				assert.Contains(t, got, expected, "generated code should contain: %q", expected)
+			}
+
+			for _, notExpected := range tt.notInCode {
+				assert.NotContains(t, got, notExpected, "generated code should NOT contain: %q", notExpected)
+			}
+		})
+	}
+}
+
 func TestGenerateEndpoint(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -321,14 +431,73 @@ func TestGenerateEndpoint(t *testing.T) {
 				"func (s *Server) FctBlockServiceList(w http.ResponseWriter, r *http.Request, params handlers.FctBlockServiceListParams)",
 				"req := &clickhouse.ListFctBlockRequest{",
 				"PageSize: 100,",
-				"clickhouse.BuildListFctBlockQuery(req, s.buildQueryOptions()...)",
+				"clickhouse.BuildListFctBlockQuery(req, s.buildQueryOptions(ctx)...)",
 				"s.db.Query(ctx, sqlQuery.Query, sqlQuery.Args...)",
 				"items := make([]handlers.FctBlock, 0, req.PageSize)",
 				"var item handlers.FctBlock",
 				"items = append(items, item)",
+				"if err := rows.Err(); err != nil {",
 				"response := handlers.ListFctBlockResponse{",
 			},
-			notInCode: []string{},
+			notInCode: []string{"Deprecation", "EXCEPT", "} else {"},
+		},
+		{
+			name: "list endpoint with table overrides",
+			endpoint: Endpoint{
+				Path:         "/api/v1/blocks",
+				Method:       "GET",
+				OperationID:  "FctBlockService_List",
+				HandlerName:  "FctBlockServiceList",
+				Operation:    "List",
+				ParamsType:   "FctBlockServiceListParams",
+				ResponseType: "ListFctBlockResponse",
+				TableName:    "fct_block",
+				Overrides: config.TableConfig{
+					HiddenColumns:   []string{"internal_note", "raw"},
+					OrderBy:         "slot DESC",
+					MaxPageSize:     50,
+					Deprecated:      true,
+					DeprecatedSince: "2025-01-01T00:00:00Z",
+					Sunset:          "2025-07-01T00:00:00Z",
+				},
+			},
+			protoInfo: &ProtoInfo{
+				QueryBuilders: map[string]string{"fct_block:List": "BuildListFctBlockQuery"},
+				RequestTypes:  map[string]string{"fct_block:List": "ListFctBlockRequest"},
+			},
+			expectedInCode: []string{
+				"PageSize: 50,",
+				`req.OrderBy = "slot DESC"`,
+				"sqlQuery.Query = \"SELECT * EXCEPT (`internal_note`, `raw`) FROM (\" + sqlQuery.Query + \")\"",
+				`w.Header().Set("Deprecation", "@1735689600")`,
+				`w.Header().Set("Sunset", "Tue, 01 Jul 2025 00:00:00 GMT")`,
+			},
+		},
+		{
+			name: "list endpoint with enum values and a hidden column",
+			endpoint: Endpoint{
+				Path:             "/api/v1/fct_data_types_complex",
+				Method:           "GET",
+				OperationID:      "FctDataTypesComplexService_List",
+				HandlerName:      "FctDataTypesComplexServiceList",
+				Operation:        "List",
+				ParamsType:       "FctDataTypesComplexServiceListParams",
+				ResponseType:     "ListFctDataTypesComplexResponse",
+				TableName:        "fct_data_types_complex",
+				Overrides:        config.TableConfig{HiddenColumns: []string{"raw"}},
+				EnumValueColumns: []Field{{Name: "enum16_value", Nullable: true}, {Name: "enum8_value"}},
+				DefaultOrderBy:   "id",
+			},
+			protoInfo: &ProtoInfo{
+				QueryBuilders: map[string]string{"fct_data_types_complex:List": "BuildListFctDataTypesComplexQuery"},
+				RequestTypes:  map[string]string{"fct_data_types_complex:List": "ListFctDataTypesComplexRequest"},
+			},
+			expectedInCode: []string{
+				"// Drop hidden columns (tables.<table>.hidden_columns); Return Enum values rather than names (api.encoding.enum)",
+				"sqlQuery.Query = \"SELECT * EXCEPT (`raw`) REPLACE (CAST(`enum16_value` AS Nullable(Int16)) AS `enum16_value`, " +
+					"CAST(`enum8_value` AS Int16) AS `enum8_value`) FROM (\" + sqlQuery.Query + \")\"",
+				`req.OrderBy = "id" // The table's sorting key`,
+			},
 		},
 	}
 
@@ -385,7 +554,9 @@ func TestGenerateGetEndpoint(t *testing.T) {
 				"clickhouse.BuildGetFctBlockQuery(req,",
 				"var item handlers.FctBlock",
 				"if rows.Next() {",
-				"w.WriteHeader(http.StatusNotFound)",
+				`apierrors.NotFoundf("resource not found: fct_block with slot=%v", slot)`,
+				`"table": "fct_block",`,
+				"if err := rows.Err(); err != nil {",
 				"writeJSON(w, item)",
 			},
 		},
@@ -403,6 +574,52 @@ func TestGenerateGetEndpoint(t *testing.T) {
 	}
 }
 
+func TestGenerateDistinctEndpoint(t *testing.T) {
+	endpoint := Endpoint{
+		Path:            "/api/v1/fct_block/distinct/{column}",
+		Method:          "GET",
+		OperationID:     "FctBlockService_Distinct",
+		HandlerName:     "FctBlockServiceDistinct",
+		Operation:       "Distinct",
+		ParamsType:      "FctBlockServiceDistinctParams",
+		TableName:       "fct_block",
+		DistinctColumns: []string{"block_version", "status"},
+		Parameters: []Param{
+			{Name: "status_eq", Field: "status", Operator: "eq", Type: "string", GoType: "*string"},
+			{Name: "limit", Field: "limit", Type: "integer", Format: "int32", GoType: "*int32"},
+			{Name: "counts", Field: "counts", Type: "boolean", GoType: "*bool"},
+		},
+	}
+	protoInfo := &ProtoInfo{
+		QueryBuilders: map[string]string{"fct_block:List": "BuildListFctBlockQuery"},
+		RequestTypes:  map[string]string{"fct_block:List": "ListFctBlockRequest"},
+		RequestFields: map[string]map[string]string{"fct_block": {"status": "StringFilter"}},
+	}
+
+	got := generateDistinctEndpoint(endpoint, protoInfo)
+
+	for _, expected := range []string{
+		"func (s *Server) FctBlockServiceDistinct(w http.ResponseWriter, r *http.Request, column string, params handlers.FctBlockServiceDistinctParams)",
+		`case "block_version", "status":`,
+		`Expected:    "block_version, status",`,
+		"limit := 100 // default",
+		"req := &clickhouse.ListFctBlockRequest{",
+		"req.Status = buildStringFilter(params.StatusEq,",
+		"clickhouse.BuildListFctBlockQuery(req, s.buildQueryOptions(ctx)...)",
+		"sqlQuery.Query, err = handlers.DistinctQuery(sqlQuery.Query, column, limit, counts)",
+		"if errors.Is(err, handlers.ErrUnsupportedListQuery) {",
+		"status = http.StatusInternalServerError",
+		"rows.Scan(&item.Value, &item.Count, &response.DistinctCount)",
+		"writeJSON(w, response)",
+	} {
+		assert.Contains(t, got, expected, "generated code should contain: %q", expected)
+	}
+
+	// Tables without eligible columns get no handler body
+	endpoint.DistinctColumns = nil
+	assert.Contains(t, generateDistinctEndpoint(endpoint, protoInfo), "// Skipping FctBlockServiceDistinct")
+}
+
 func TestGenerateEndpoints(t *testing.T) {
 	tests := []struct {
 		name           string
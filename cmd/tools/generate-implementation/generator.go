@@ -54,7 +54,9 @@ func (g *CodeGenerator) generateHeader() string {
 // Source: openapi.yaml + proto files
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -63,7 +65,10 @@ import (
 	"github.com/ethpandaops/cbt-api/internal/database"
 	apierrors "github.com/ethpandaops/cbt-api/internal/errors"
 	"github.com/ethpandaops/cbt-api/internal/handlers"
+	"github.com/ethpandaops/cbt-api/internal/network"
+	"github.com/ethpandaops/cbt-api/internal/requestid"
 	clickhouse "github.com/ethpandaops/cbt-api/pkg/proto/clickhouse"
+	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -79,6 +84,7 @@ func (g *CodeGenerator) generateServerStruct() string {
 type Server struct {
 	db     database.DatabaseClient
 	config *config.Config
+	logger logrus.FieldLogger
 }`
 }
 
@@ -203,6 +209,12 @@ func protoToOpenAPI%s(p *clickhouse.%s) handlers.%s {
 	// Generate field mappings
 	for _, field := range schema.Fields {
 		fieldName := toPascalCase(field.Name)
+		if field.GoType != "" {
+			sb.WriteString(g.generateScannerMapping(fieldName, field.Nullable))
+
+			continue
+		}
+
 		sb.WriteString(g.generateFieldMapping(fieldName, field.Type, field.Nullable))
 	}
 
@@ -247,6 +259,21 @@ func (g *CodeGenerator) generateFieldMapping(fieldName string, fieldType string,
 `, fieldName, fieldName)
 }
 
+// generateScannerMapping generates field mapping code for columns with a handlers encoding type
+// (BigIntString, RFC3339Time, DecimalString, ...). Their Scan accepts the proto field types as well
+// as the values clickhouse-go scans.
+func (g *CodeGenerator) generateScannerMapping(fieldName string, nullable bool) string {
+	if nullable {
+		return fmt.Sprintf(`	if p.%s != nil {
+		_ = result.%s.Scan(p.%s.GetValue())
+	}
+`, fieldName, fieldName, fieldName)
+	}
+
+	return fmt.Sprintf(`	_ = result.%s.Scan(p.%s)
+`, fieldName, fieldName)
+}
+
 // generateUtilities generates utility functions for HTTP handling.
 func (g *CodeGenerator) generateUtilities() string {
 	return `// Utility functions
@@ -259,7 +286,16 @@ func writeJSON(w http.ResponseWriter, data any) {
 	}
 }
 
-func writeError(w http.ResponseWriter, status int, err error) {
+// writeError writes err as a Status. Errors without a client-facing message are logged with the
+// request ID before the sanitized 500 is written, as the span only records them when tracing is on.
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	// ClickHouse exceptions, timeouts and circuit breaker rejections get their own codes
+	// with sanitized messages
+	if apiErr := apierrors.FromError(err); apiErr != nil {
+		apiErr.WriteJSON(w)
+		return
+	}
+
 	// Map HTTP status to appropriate Status error
 	var apiErr *apierrors.Status
 	switch status {
@@ -267,10 +303,13 @@ func writeError(w http.ResponseWriter, status int, err error) {
 		apiErr = apierrors.BadRequest(err.Error())
 	case http.StatusNotFound:
 		apiErr = apierrors.NotFound(err.Error())
-	case http.StatusInternalServerError:
-		apiErr = apierrors.Internal(err.Error())
 	default:
-		apiErr = apierrors.Internal(err.Error())
+		// Don't expose driver or scan errors to clients
+		s.logger.WithFields(logrus.Fields{
+			"path":       r.URL.Path,
+			"request_id": requestid.FromContext(r.Context()),
+		}).WithError(err).Error("request failed")
+		apiErr = apierrors.Internal("An internal error occurred")
 	}
 
 	apiErr.WriteJSON(w)
@@ -290,10 +329,15 @@ func generateNextPageToken(currentToken string, itemCount int) string {
 	return clickhouse.EncodePageToken(nextOffset)
 }
 
-// buildQueryOptions creates query options with conditional WithFinal.
-func (s *Server) buildQueryOptions() []clickhouse.QueryOption {
+// buildQueryOptions creates query options for the request's network database with conditional WithFinal.
+func (s *Server) buildQueryOptions(ctx context.Context) []clickhouse.QueryOption {
+	dbName := s.config.ClickHouse.Database
+	if n, ok := network.FromContext(ctx); ok {
+		dbName = n.Database
+	}
+
 	opts := []clickhouse.QueryOption{
-		clickhouse.WithDatabase(s.config.ClickHouse.Database),
+		clickhouse.WithDatabase(dbName),
 	}
 	if s.config.ClickHouse.UseFinal {
 		opts = append(opts, clickhouse.WithFinal())
@@ -23,6 +23,7 @@ func TestCodeGenerator_generateHeader(t *testing.T) {
 	assert.Contains(t, got, "github.com/ethpandaops/cbt-api/internal/config")
 	assert.Contains(t, got, "github.com/ethpandaops/cbt-api/internal/database")
 	assert.Contains(t, got, "github.com/ethpandaops/cbt-api/internal/handlers")
+	assert.Contains(t, got, "github.com/ethpandaops/cbt-api/internal/network")
 	assert.Contains(t, got, "github.com/ethpandaops/cbt-api/pkg/proto/clickhouse")
 	assert.Contains(t, got, "google.golang.org/protobuf/types/known/emptypb")
 	assert.Contains(t, got, "google.golang.org/protobuf/types/known/wrapperspb")
@@ -38,6 +39,7 @@ func TestCodeGenerator_generateServerStruct(t *testing.T) {
 	assert.Contains(t, got, "type Server struct {")
 	assert.Contains(t, got, "db     database.DatabaseClient")
 	assert.Contains(t, got, "config *config.Config")
+	assert.Contains(t, got, "logger logrus.FieldLogger")
 }
 
 func TestCodeGenerator_generateHelpers(t *testing.T) {
@@ -64,9 +66,14 @@ func TestCodeGenerator_generateUtilities(t *testing.T) {
 
 	// Check for utility function signatures
 	assert.Contains(t, got, "func writeJSON(w http.ResponseWriter, data any)")
-	assert.Contains(t, got, "func writeError(w http.ResponseWriter, status int, err error)")
+	assert.Contains(t, got, "func (s *Server) writeError(w http.ResponseWriter, r *http.Request, status int, err error)")
 	assert.Contains(t, got, "func generateNextPageToken(currentToken string, itemCount int) string")
-	assert.Contains(t, got, "func (s *Server) buildQueryOptions() []clickhouse.QueryOption")
+	assert.Contains(t, got, "func (s *Server) buildQueryOptions(ctx context.Context) []clickhouse.QueryOption")
+
+	// Verify database errors are classified and internal errors are sanitized
+	assert.Contains(t, got, "apierrors.FromError(err)")
+	assert.Contains(t, got, `apierrors.Internal("An internal error occurred")`)
+	assert.Contains(t, got, `"request_id": requestid.FromContext(r.Context()),`)
 
 	// Verify JSON encoding is used
 	assert.Contains(t, got, "json.NewEncoder(w).Encode")
@@ -74,8 +81,9 @@ func TestCodeGenerator_generateUtilities(t *testing.T) {
 	// Verify content type is set
 	assert.Contains(t, got, "application/json")
 
-	// Verify query options include database and final
-	assert.Contains(t, got, "clickhouse.WithDatabase(s.config.ClickHouse.Database)")
+	// Verify query options use the request's network database and final
+	assert.Contains(t, got, "network.FromContext(ctx)")
+	assert.Contains(t, got, "clickhouse.WithDatabase(dbName)")
 	assert.Contains(t, got, "clickhouse.WithFinal()")
 }
 
@@ -133,6 +141,8 @@ func TestCodeGenerator_generateConverter(t *testing.T) {
 						Fields: []Field{
 							{Name: "slot", Nullable: false},
 							{Name: "block_root", Nullable: true},
+							{Name: "block_number", GoType: "BigIntString"},
+							{Name: "execution_payload_value", Nullable: true, GoType: "BigIntString"},
 						},
 					},
 				},
@@ -143,6 +153,8 @@ func TestCodeGenerator_generateConverter(t *testing.T) {
 				"result := handlers.FctBlock{}",
 				"result.Slot = &p.Slot",
 				"if p.BlockRoot != nil {",
+				"_ = result.BlockNumber.Scan(p.BlockNumber)",
+				"_ = result.ExecutionPayloadValue.Scan(p.ExecutionPayloadValue.GetValue())",
 				"return result",
 			},
 		},
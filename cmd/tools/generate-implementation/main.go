@@ -21,14 +21,17 @@ func main() {
 	protoPath := flag.String("proto-path", "pkg/proto/clickhouse",
 		"Path to proto files")
 	output := flag.String("output", "internal/server/implementation.go", "Output file")
+	testOutput := flag.String("test-output", "", "Output file for the test of the Distinct queries (optional)")
 	configFile := flag.String("config", "config.yaml", "Path to configuration file")
 	basePath := flag.String("base-path", "/api/v1", "API base path (defaults to /api/v1, overridden by config if present)")
 
 	flag.Parse()
 
-	// 0. Load config to get api.base_path (optional - falls back to flag default)
+	// 0. Load config to get api.base_path and table overrides (optional - falls back to flag default)
 	apiBasePath := *basePath
 
+	var tables map[string]config.TableConfig
+
 	if _, err := os.Stat(*configFile); err == nil {
 		cfg, err := config.Load(*configFile)
 		if err != nil {
@@ -37,6 +40,7 @@ func main() {
 		}
 
 		apiBasePath = cfg.API.BasePath
+		tables = cfg.Tables
 	}
 
 	// 1. Load OpenAPI spec
@@ -46,6 +50,8 @@ func main() {
 		os.Exit(1)
 	}
 
+	spec.applyTableConfig(tables)
+
 	// 2. Analyze proto files
 	protoInfo, err := analyzeProtos(*protoPath)
 	if err != nil {
@@ -74,4 +80,14 @@ func main() {
 
 	lines := len(strings.Split(code, "\n"))
 	fmt.Printf("%s✓ Generated %d lines: %s%s\n", colorGreen, lines, *output, colorReset)
+
+	// 5. Write the test checking DistinctQuery against the query builders
+	if *testOutput != "" {
+		if err := os.WriteFile(*testOutput, []byte(generator.GenerateDistinctTest()), 0600); err != nil {
+			fmt.Printf("Error writing test output: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("%s✓ Generated: %s%s\n", colorGreen, *testOutput, colorReset)
+	}
 }
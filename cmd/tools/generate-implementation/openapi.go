@@ -5,8 +5,13 @@ import (
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
 )
 
+// enumValueGoType is the handlers type of Enum columns encoded as values.
+const enumValueGoType = "EnumValue"
+
 // OpenAPISpec represents the parsed OpenAPI specification.
 type OpenAPISpec struct {
 	Endpoints []Endpoint
@@ -24,7 +29,13 @@ type Endpoint struct {
 	ResponseType  string // Item type for responses (e.g., "FctBlock")
 	TableName     string // "fct_block"
 	Parameters    []Param
-	PathParameter *Param // For Get operations: the primary key path parameter
+	PathParameter *Param             // For Get operations: the primary key path parameter
+	Overrides     config.TableConfig // From the config's tables section
+
+	DefaultOrderBy  string   // "slot_start_date_time,slot", the order_by default set by openapi-preprocess
+	DistinctColumns []string // For Distinct operations: the columns values can be listed for
+
+	EnumValueColumns []Field // Enum columns encoded as values (handlers.EnumValue), cast in the query
 }
 
 // Param represents a parameter.
@@ -35,6 +46,12 @@ type Param struct {
 	Type     string // "integer"
 	Format   string // "uint32"
 	GoType   string // "*uint32"
+
+	ProjectionName           string // "p_by_slot" (from x-projection-name)
+	ProjectionAlternativeFor string // "slot_start_date_time" (from x-projection-alternative-for)
+
+	DateTimeFormat    string // "uint32" for a DateTime filter passed as a string (from x-datetime-format)
+	DateTimePrecision int    // 3 for DateTime64(3) (from x-datetime-precision)
 }
 
 // Type represents a schema type.
@@ -49,6 +66,7 @@ type Field struct {
 	Type     string
 	JSONTag  string
 	Nullable bool
+	GoType   string // handlers type from x-go-type, e.g. "BigIntString"; implements sql.Scanner
 }
 
 // loadOpenAPI loads and parses an OpenAPI specification file.
@@ -89,9 +107,38 @@ func loadOpenAPI(basePath string, path string) (*OpenAPISpec, error) {
 		}
 	}
 
+	spec.resolveEnumValueColumns()
+
 	return spec, nil
 }
 
+// resolveEnumValueColumns lists the handlers.EnumValue columns of each endpoint's item type.
+func (s *OpenAPISpec) resolveEnumValueColumns() {
+	for i := range s.Endpoints {
+		itemType, ok := s.Types[getItemType(s.Endpoints[i].TableName)]
+		if !ok {
+			continue
+		}
+
+		for _, field := range itemType.Fields {
+			if field.GoType == enumValueGoType {
+				s.Endpoints[i].EnumValueColumns = append(s.Endpoints[i].EnumValueColumns, field)
+			}
+		}
+
+		sort.Slice(s.Endpoints[i].EnumValueColumns, func(a, b int) bool {
+			return s.Endpoints[i].EnumValueColumns[a].Name < s.Endpoints[i].EnumValueColumns[b].Name
+		})
+	}
+}
+
+// applyTableConfig attaches the per-table overrides from config to the endpoints.
+func (s *OpenAPISpec) applyTableConfig(tables map[string]config.TableConfig) {
+	for i := range s.Endpoints {
+		s.Endpoints[i].Overrides = tables[s.Endpoints[i].TableName]
+	}
+}
+
 // parseEndpoint parses an OpenAPI operation into an Endpoint struct.
 func parseEndpoint(basePath string, path, method string, op *openapi3.Operation) Endpoint {
 	endpoint := Endpoint{
@@ -109,11 +156,18 @@ func parseEndpoint(basePath string, path, method string, op *openapi3.Operation)
 	parts := strings.Split(trimmedPath, "/")
 	endpoint.TableName = parts[0]
 
+	// Aliased paths carry the table name in x-table (set by openapi-preprocess)
+	if table := stringExtension(op.Extensions, "x-table"); table != "" {
+		endpoint.TableName = table
+	}
+
 	// Determine operation type from OperationID: "FctBlockService_List" → "List"
 	if strings.HasSuffix(op.OperationID, "_List") {
 		endpoint.Operation = "List"
 	} else if strings.HasSuffix(op.OperationID, "_Get") {
 		endpoint.Operation = "Get"
+	} else if strings.HasSuffix(op.OperationID, "_Distinct") {
+		endpoint.Operation = "Distinct"
 	}
 
 	// Parse parameters
@@ -121,9 +175,23 @@ func parseEndpoint(basePath string, path, method string, op *openapi3.Operation)
 		if paramRef.Value != nil {
 			param := parseParam(paramRef.Value)
 
-			// Check if this is a path parameter (for Get operations)
+			// order_by defaults to tables.<table>.order_by or the table's sorting key
+			if paramRef.Value.Name == "order_by" && paramRef.Value.Schema != nil && paramRef.Value.Schema.Value != nil {
+				endpoint.DefaultOrderBy, _ = paramRef.Value.Schema.Value.Default.(string)
+			}
+
+			// Check if this is a path parameter (for Get and Distinct operations)
 			if paramRef.Value.In == "path" {
 				endpoint.PathParameter = &param
+
+				// The column path parameter of Distinct operations enumerates the columns
+				if paramRef.Value.Schema != nil && paramRef.Value.Schema.Value != nil {
+					for _, value := range paramRef.Value.Schema.Value.Enum {
+						if column, ok := value.(string); ok {
+							endpoint.DistinctColumns = append(endpoint.DistinctColumns, column)
+						}
+					}
+				}
 			} else {
 				// Query parameters (for List operations)
 				endpoint.Parameters = append(endpoint.Parameters, param)
@@ -200,9 +268,38 @@ func parseParam(p *openapi3.Parameter) Param {
 		param.GoType = toGoType(param.Type, param.Format, true) // pointer type
 	}
 
+	// Projection annotations carried through by openapi-preprocess
+	param.ProjectionName = stringExtension(p.Extensions, "x-projection-name")
+	param.ProjectionAlternativeFor = stringExtension(p.Extensions, "x-projection-alternative-for")
+
+	// DateTime filters rewritten to strings by openapi-preprocess
+	param.DateTimeFormat = stringExtension(p.Extensions, "x-datetime-format")
+	param.DateTimePrecision = intExtension(p.Extensions, "x-datetime-precision")
+
 	return param
 }
 
+// stringExtension returns a string-valued OpenAPI extension, or "" if absent.
+func stringExtension(extensions map[string]any, name string) string {
+	if v, ok := extensions[name].(string); ok {
+		return v
+	}
+
+	return ""
+}
+
+// intExtension returns an integer-valued OpenAPI extension, or 0 if absent.
+func intExtension(extensions map[string]any, name string) int {
+	switch v := extensions[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+
+	return 0
+}
+
 // isFilterOperator checks if a string is a known filter operator.
 func isFilterOperator(s string) bool {
 	operators := map[string]bool{
@@ -329,6 +426,7 @@ func parseType(name string, schema *openapi3.Schema) *Type {
 					Type:     propType,
 					JSONTag:  propName,
 					Nullable: propRef.Value.Nullable,
+					GoType:   stringExtension(propRef.Value.Extensions, "x-go-type"),
 				}
 				t.Fields = append(t.Fields, field)
 			}
@@ -506,6 +506,56 @@ func TestParseParam(t *testing.T) {
 				GoType:   "*string",
 			},
 		},
+		{
+			name: "filter with projection annotations",
+			param: &openapi3.Parameter{
+				Name: "slot_eq",
+				Schema: &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type:   &openapi3.Types{"integer"},
+						Format: "uint32",
+					},
+				},
+				Extensions: map[string]any{
+					"x-projection-name":            "p_by_slot",
+					"x-projection-alternative-for": "slot_start_date_time",
+				},
+			},
+			expected: Param{
+				Name:                     "slot_eq",
+				Field:                    "slot",
+				Operator:                 "eq",
+				Type:                     "integer",
+				Format:                   "uint32",
+				GoType:                   "*uint32",
+				ProjectionName:           "p_by_slot",
+				ProjectionAlternativeFor: "slot_start_date_time",
+			},
+		},
+		{
+			name: "datetime filter",
+			param: &openapi3.Parameter{
+				Name: "event_date_time_gte",
+				Schema: &openapi3.SchemaRef{
+					Value: &openapi3.Schema{
+						Type: &openapi3.Types{"string"},
+					},
+				},
+				Extensions: map[string]any{
+					"x-datetime-format":    "int64",
+					"x-datetime-precision": float64(3),
+				},
+			},
+			expected: Param{
+				Name:              "event_date_time_gte",
+				Field:             "event_date_time",
+				Operator:          "gte",
+				Type:              "string",
+				GoType:            "*string",
+				DateTimeFormat:    "int64",
+				DateTimePrecision: 3,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -517,6 +567,10 @@ func TestParseParam(t *testing.T) {
 			assert.Equal(t, tt.expected.Type, got.Type, "Type mismatch")
 			assert.Equal(t, tt.expected.Format, got.Format, "Format mismatch")
 			assert.Equal(t, tt.expected.GoType, got.GoType, "GoType mismatch")
+			assert.Equal(t, tt.expected.ProjectionName, got.ProjectionName, "ProjectionName mismatch")
+			assert.Equal(t, tt.expected.ProjectionAlternativeFor, got.ProjectionAlternativeFor, "ProjectionAlternativeFor mismatch")
+			assert.Equal(t, tt.expected.DateTimeFormat, got.DateTimeFormat, "DateTimeFormat mismatch")
+			assert.Equal(t, tt.expected.DateTimePrecision, got.DateTimePrecision, "DateTimePrecision mismatch")
 		})
 	}
 }
@@ -594,6 +648,41 @@ func TestParseEndpoint(t *testing.T) {
 				ResponseType: "ListFctBlockResponse",
 			},
 		},
+		{
+			name:   "distinct endpoint on an aliased path",
+			path:   "/api/v1/blocks/distinct/{column}",
+			method: "GET",
+			op: &openapi3.Operation{
+				OperationID: "FctBlockService_Distinct",
+				Extensions:  map[string]any{"x-table": "fct_block"},
+				Parameters: []*openapi3.ParameterRef{
+					{
+						Value: &openapi3.Parameter{
+							Name: "column",
+							In:   "path",
+							Schema: &openapi3.SchemaRef{
+								Value: &openapi3.Schema{
+									Type: &openapi3.Types{"string"},
+									Enum: []any{"block_version", "status"},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: Endpoint{
+				Path:            "/api/v1/blocks/distinct/{column}",
+				Method:          "GET",
+				OperationID:     "FctBlockService_Distinct",
+				HandlerName:     "FctBlockServiceDistinct",
+				Operation:       "Distinct",
+				TableName:       "fct_block",
+				ParamsType:      "FctBlockServiceDistinctParams",
+				ResponseType:    "ListFctBlockResponse",
+				PathParameter:   &Param{Name: "column"},
+				DistinctColumns: []string{"block_version", "status"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -608,6 +697,7 @@ func TestParseEndpoint(t *testing.T) {
 			assert.Equal(t, tt.expected.TableName, got.TableName, "TableName mismatch")
 			assert.Equal(t, tt.expected.ParamsType, got.ParamsType, "ParamsType mismatch")
 			assert.Equal(t, tt.expected.ResponseType, got.ResponseType, "ResponseType mismatch")
+			assert.Equal(t, tt.expected.DistinctColumns, got.DistinctColumns, "DistinctColumns mismatch")
 
 			if tt.expected.PathParameter != nil {
 				require.NotNil(t, got.PathParameter, "PathParameter should not be nil")
@@ -616,3 +706,28 @@ func TestParseEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveEnumValueColumns(t *testing.T) {
+	spec := &OpenAPISpec{
+		Endpoints: []Endpoint{
+			{TableName: "fct_data_types_complex", Operation: "List"},
+			{TableName: "fct_block", Operation: "List"},
+		},
+		Types: map[string]*Type{
+			"FctDataTypesComplex": {Fields: []Field{
+				{Name: "id"},
+				{Name: "enum8_value", GoType: "EnumValue"},
+				{Name: "decimal64_value", GoType: "DecimalNumber"},
+				{Name: "enum16_value", GoType: "EnumValue", Nullable: true},
+			}},
+		},
+	}
+
+	spec.resolveEnumValueColumns()
+
+	assert.Equal(t, []Field{
+		{Name: "enum16_value", GoType: "EnumValue", Nullable: true},
+		{Name: "enum8_value", GoType: "EnumValue"},
+	}, spec.Endpoints[0].EnumValueColumns)
+	assert.Empty(t, spec.Endpoints[1].EnumValueColumns)
+}
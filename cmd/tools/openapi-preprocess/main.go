@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -17,6 +18,7 @@ import (
 	"google.golang.org/protobuf/types/descriptorpb"
 	"gopkg.in/yaml.v3"
 
+	"github.com/ethpandaops/cbt-api/internal/config"
 	"github.com/ethpandaops/cbt-api/internal/version"
 )
 
@@ -40,6 +42,9 @@ type FieldAnnotations struct {
 // ProtoFieldAnnotations maps message.field -> FieldAnnotations.
 type ProtoFieldAnnotations map[string]FieldAnnotations
 
+// ProtoSortingKeys maps service name -> the columns of the table's MergeTree sorting key, in order.
+type ProtoSortingKeys map[string][]string
+
 // WrapperTypeMapping defines correct OpenAPI type/format for google.protobuf wrapper types.
 type WrapperTypeMapping struct {
 	Type   string
@@ -55,6 +60,14 @@ const (
 	colorReset = "\033[0m"
 )
 
+// Error response schemas. Status is generated by protoc-gen-openapi; Problem is added for
+// clients negotiating RFC 9457 problem details.
+const (
+	statusSchemaRef    = "#/components/schemas/Status"
+	problemSchemaName  = "Problem"
+	problemContentType = "application/problem+json"
+)
+
 // Mapping of google.protobuf wrapper types to correct OpenAPI type/format.
 // protoc-gen-openapi generates incorrect mappings, causing oapi-codegen to
 // generate wrong Go types that break ClickHouse scanning.
@@ -77,6 +90,8 @@ var (
 	fieldCommentPattern = regexp.MustCompile(`^\s*//\s*(.+)`)
 	fieldPattern        = regexp.MustCompile(`^\s*(?:\w+)\s+(\w+)\s+=\s+\d+`)
 	wrapperFieldPattern = regexp.MustCompile(`^\s*google\.protobuf\.(\w+)\s+(\w+)\s+=\s+\d+`)
+	listRequestPattern  = regexp.MustCompile(`^message\s+List\w+Request\b`)
+	sortingKeyPattern   = regexp.MustCompile(`\((PRIMARY KEY|ORDER BY column (\d+))\b[^)]*\)\s*$`)
 )
 
 // ============================================================================
@@ -98,12 +113,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Load API exclude patterns from config
-	excludePatterns, err := loadAPIExcludePatterns(*configFile)
-	if err != nil {
-		fmt.Printf("Warning: Could not load API exclude patterns: %v\n", err)
+	// Load API exclude patterns, bigint fields, encodings and table overrides from config
+	// (optional - without a config file the spec is left as generated)
+	cfg := &config.Config{}
 
-		excludePatterns = []string{}
+	if _, err := os.Stat(*configFile); err == nil {
+		cfg, err = config.Load(*configFile)
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
 	// Load proto data
@@ -115,6 +134,14 @@ func main() {
 		fieldTypes = make(ProtoFieldTypes)
 	}
 
+	// Load the MergeTree sorting keys noted on the List request filters
+	sortingKeys, err := loadSortingKeys(*protoPath)
+	if err != nil {
+		fmt.Printf("Warning: Could not load sorting keys: %v\n", err)
+
+		sortingKeys = make(ProtoSortingKeys)
+	}
+
 	// Load custom annotations from descriptor
 	annotations, err := loadProtoAnnotations(*descriptorPath)
 	if err != nil {
@@ -133,7 +160,7 @@ func main() {
 	}
 
 	// Apply transformations
-	_ = applyTransformations(doc, descriptions, fieldTypes, annotations, excludePatterns)
+	_ = applyTransformations(doc, descriptions, fieldTypes, annotations, sortingKeys, cfg)
 
 	// Ensure proper OpenAPI metadata
 	ensureOpenAPIMetadata(doc)
@@ -153,14 +180,21 @@ func main() {
 
 // TransformationStats tracks what was changed.
 type TransformationStats struct {
-	FiltersFlatted int
-	SchemasFixed   int
-	TypesFixed     int
-	PathsExcluded  int
+	FiltersFlatted     int
+	SchemasFixed       int
+	TypesFixed         int
+	PathsExcluded      int
+	ColumnsEncoded     int
+	BigintsStringified int
+	DateTimeFilters    int
+	TablesOverridden   int
+	OrderByDocumented  int
+	DistinctOperations int
+	ProblemResponses   int
 }
 
 // applyTransformations applies all OpenAPI transformations.
-func applyTransformations(doc *openapi3.T, descriptions ProtoDescriptions, fieldTypes ProtoFieldTypes, annotations ProtoFieldAnnotations, excludePatterns []string) TransformationStats {
+func applyTransformations(doc *openapi3.T, descriptions ProtoDescriptions, fieldTypes ProtoFieldTypes, annotations ProtoFieldAnnotations, sortingKeys ProtoSortingKeys, cfg *config.Config) TransformationStats {
 	stats := TransformationStats{}
 
 	// 1. Flatten filter parameters (dot notation -> underscore notation)
@@ -176,8 +210,30 @@ func applyTransformations(doc *openapi3.T, descriptions ProtoDescriptions, field
 	addAnnotationExtensions(doc, annotations)
 
 	// 5. Filter out excluded paths and tags
-	stats.PathsExcluded = filterExcludedPaths(doc, excludePatterns)
-	filterExcludedTags(doc, excludePatterns)
+	stats.PathsExcluded = filterExcludedPaths(doc, cfg.API.Exclude)
+	filterExcludedTags(doc, cfg.API.Exclude)
+
+	// 6. Encode DateTime, Decimal and Enum columns per api.encoding (before bigints, so
+	// DateTime64 columns keep a time encoding under bigint_to_string_all)
+	stats.ColumnsEncoded = applyColumnEncodings(doc, cfg.API.Encoding.DateTime, cfg.API.Encoding.Decimal, cfg.API.Encoding.Enum)
+
+	// 7. Encode configured 64-bit integer columns as strings
+	stats.BigintsStringified = stringifyBigints(doc, cfg.API.BigintToStringFields, cfg.API.BigintToStringAll)
+
+	// 8. Accept RFC 3339 and relative values on DateTime filters
+	stats.DateTimeFilters = markDateTimeFilters(doc)
+
+	// 9. Apply per-table overrides (aliases, hidden columns, defaults, deprecation)
+	stats.TablesOverridden = applyTableOverrides(doc, cfg.Tables)
+
+	// 10. Document sortable columns and default ordering (after overrides drop hidden columns)
+	stats.OrderByDocumented = documentOrderBy(doc, sortingKeys)
+
+	// 11. Add distinct-values operations (after filters and overrides are final)
+	stats.DistinctOperations = addDistinctOperations(doc)
+
+	// 12. Document problem+json error responses
+	stats.ProblemResponses = addProblemDetails(doc)
 
 	return stats
 }
@@ -316,6 +372,94 @@ func extractFieldDescription(comment string) string {
 	return comment
 }
 
+// loadSortingKeys reads the sorting key of each table from its List request, whose filter comments
+// end in "(PRIMARY KEY - required)" for the first key column and "(ORDER BY column N - optional)"
+// for the others.
+func loadSortingKeys(protoPath string) (ProtoSortingKeys, error) {
+	sortingKeys := make(ProtoSortingKeys)
+
+	files, err := filepath.Glob(filepath.Join(protoPath, "*.proto"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		serviceName := extractServiceName(content)
+		if serviceName == "" {
+			continue
+		}
+
+		if key := parseSortingKey(string(content)); len(key) > 0 {
+			sortingKeys[strings.ToLower(serviceName)] = key
+		}
+	}
+
+	return sortingKeys, nil
+}
+
+// parseSortingKey returns the sorting key columns noted in the List request of a proto file.
+func parseSortingKey(content string) []string {
+	type keyColumn struct {
+		position int
+		name     string
+	}
+
+	var (
+		columns       []keyColumn
+		inList        bool
+		lastPosition  int
+		pendingColumn bool
+	)
+
+	for line := range strings.Lines(content) {
+		if strings.HasPrefix(line, "message ") {
+			inList = listRequestPattern.MatchString(line)
+
+			continue
+		}
+
+		if !inList {
+			continue
+		}
+
+		if matches := fieldCommentPattern.FindStringSubmatch(line); matches != nil {
+			keyMatches := sortingKeyPattern.FindStringSubmatch(strings.TrimSpace(matches[1]))
+
+			pendingColumn = keyMatches != nil
+			if pendingColumn {
+				lastPosition = 1
+				if keyMatches[2] != "" {
+					fmt.Sscanf(keyMatches[2], "%d", &lastPosition) //nolint:errcheck // the pattern only matches digits
+				}
+			}
+
+			continue
+		}
+
+		if matches := fieldPattern.FindStringSubmatch(line); matches != nil && pendingColumn {
+			columns = append(columns, keyColumn{position: lastPosition, name: matches[1]})
+		}
+
+		pendingColumn = false
+	}
+
+	slices.SortStableFunc(columns, func(a, b keyColumn) int {
+		return a.position - b.position
+	})
+
+	key := make([]string, 0, len(columns))
+	for _, column := range columns {
+		key = append(key, column.name)
+	}
+
+	return key
+}
+
 // ============================================================================
 // OpenAPI Transformations
 // ============================================================================
@@ -540,6 +684,67 @@ func needsTypeUpdate(schema *openapi3.Schema, correctMapping WrapperTypeMapping)
 	return false
 }
 
+// addProblemDetails documents the RFC 9457 problem details error format: it adds a Problem schema
+// and, wherever a response returns a Status as application/json, an application/problem+json
+// alternative. Clients choose the format with the Accept header.
+func addProblemDetails(doc *openapi3.T) int {
+	if doc.Components == nil {
+		doc.Components = &openapi3.Components{}
+	}
+
+	if doc.Components.Schemas == nil {
+		doc.Components.Schemas = make(openapi3.Schemas)
+	}
+
+	doc.Components.Schemas[problemSchemaName] = openapi3.NewSchemaRef("", problemSchema())
+
+	count := 0
+
+	for _, pathItem := range doc.Paths.Map() {
+		for _, op := range pathItem.Operations() {
+			if op.Responses == nil {
+				continue
+			}
+
+			for _, respRef := range op.Responses.Map() {
+				if respRef == nil || respRef.Value == nil {
+					continue
+				}
+
+				mediaType := respRef.Value.Content.Get("application/json")
+				if mediaType == nil || mediaType.Schema == nil || mediaType.Schema.Ref != statusSchemaRef {
+					continue
+				}
+
+				respRef.Value.Content[problemContentType] = openapi3.NewMediaType().
+					WithSchemaRef(openapi3.NewSchemaRef("#/components/schemas/"+problemSchemaName, nil))
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// problemSchema describes an RFC 9457 problem details object as written by the API.
+func problemSchema() *openapi3.Schema {
+	schema := openapi3.NewObjectSchema().
+		WithProperty("type", openapi3.NewStringSchema().WithFormat("uri")).
+		WithProperty("title", openapi3.NewStringSchema()).
+		WithProperty("status", openapi3.NewInt32Schema()).
+		WithProperty("detail", openapi3.NewStringSchema()).
+		WithProperty("instance", openapi3.NewStringSchema()).
+		WithProperty("code", openapi3.NewInt32Schema()).
+		WithAnyAdditionalProperties()
+
+	schema.Description = "RFC 9457 problem details, returned instead of Status when the request sends " +
+		"`Accept: application/problem+json`. `code` is the google.rpc.Code and each Status detail is an " +
+		"extension member named after its type, e.g. `errorInfo` and `requestInfo`."
+	schema.Properties["code"].Value.Description = "The google.rpc.Code of the error."
+
+	return schema
+}
+
 // ============================================================================
 // Schema Reference Updates
 // ============================================================================
@@ -672,6 +877,320 @@ func updateSchemaRef(schemaRef *openapi3.SchemaRef, renamedSchemas map[string]st
 	}
 }
 
+// ============================================================================
+// Ordering
+// ============================================================================
+
+// orderBySyntax describes the order_by parameter of List operations.
+const orderBySyntax = "Comma-separated columns to sort by, each optionally followed by asc or desc, " +
+	"e.g. \"slot desc,block_root asc\"."
+
+// documentOrderBy lists the sortable columns of each List operation in an x-sortable-columns
+// extension on its order_by parameter, which QueryParameterValidation checks requests against.
+// For other clients they are also listed in the description and restricted by a schema pattern.
+// Unless tables.<table>.order_by set one, the default ordering is the table's sorting key, so
+// unordered pages are read in the order ClickHouse stores them.
+func documentOrderBy(doc *openapi3.T, sortingKeys ProtoSortingKeys) int {
+	if doc.Components == nil {
+		return 0
+	}
+
+	count := 0
+
+	for path, pathItem := range doc.Paths.Map() {
+		for _, op := range pathItem.Operations() {
+			tableName := extractTableNameFromPath(path)
+			if table, ok := op.Extensions["x-table"].(string); ok && table != "" {
+				tableName = table
+			}
+
+			schemaRef, ok := doc.Components.Schemas[tableSchemaName(tableName)]
+			if !ok || schemaRef.Value == nil {
+				continue
+			}
+
+			columns := sortableColumns(schemaRef.Value)
+			serviceName := strings.ToLower(extractServiceNameFromOperationID(op.OperationID))
+
+			for _, paramRef := range op.Parameters {
+				param := paramRef.Value
+				if param == nil || param.Name != "order_by" || param.Schema == nil || param.Schema.Value == nil {
+					continue
+				}
+
+				if param.Extensions == nil {
+					param.Extensions = make(map[string]interface{})
+				}
+
+				param.Extensions["x-sortable-columns"] = columns
+				param.Schema.Value.Pattern = orderByPattern(columns)
+				param.Description = strings.TrimSpace(orderBySyntax + " " + param.Description)
+				param.Description += fmt.Sprintf(" Sortable columns: %s.", strings.Join(columns, ", "))
+
+				key := slices.DeleteFunc(slices.Clone(sortingKeys[serviceName]), func(column string) bool {
+					return !slices.Contains(columns, column)
+				})
+
+				if param.Schema.Value.Default == nil && len(key) > 0 {
+					param.Schema.Value.Default = strings.Join(key, ",")
+					param.Description += fmt.Sprintf(" Defaults to the table's sorting key %q.", strings.Join(key, ","))
+				}
+
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// orderByPattern returns a pattern matching order_by values made of the given columns. Directions
+// are case-insensitive, as in the middleware; listing a column twice is only caught there.
+func orderByPattern(columns []string) string {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = regexp.QuoteMeta(column)
+	}
+
+	term := `(?:` + strings.Join(quoted, "|") + `)(?:\s+(?:[Aa][Ss][Cc]|[Dd][Ee][Ss][Cc]))?`
+
+	return `^\s*` + term + `\s*(?:,\s*` + term + `\s*)*$`
+}
+
+// sortableColumns returns the sorted scalar columns of a table's item schema.
+func sortableColumns(schema *openapi3.Schema) []string {
+	columns := make([]string, 0, len(schema.Properties))
+
+	for name, prop := range schema.Properties {
+		if prop.Value != nil && prop.Value.Type != nil && (prop.Value.Type.Is("array") || prop.Value.Type.Is("object")) {
+			continue
+		}
+
+		columns = append(columns, name)
+	}
+
+	slices.Sort(columns)
+
+	return columns
+}
+
+// ============================================================================
+// Distinct Values
+// ============================================================================
+
+// Distinct-values operations and their shared response schemas.
+const (
+	distinctValueSchemaName    = "DistinctValue"
+	distinctResponseSchemaName = "ListDistinctValuesResponse"
+	defaultDistinctLimit       = 100
+	maxDistinctLimit           = 1000
+)
+
+// distinctSkippedParams are List parameters that don't apply to distinct values.
+var distinctSkippedParams = []string{"page_size", "page_token", "order_by"}
+
+// addDistinctOperations adds a GET {table}/distinct/{column} operation next to each List operation,
+// returning the most frequent values of a String, LowCardinality or Enum column for building filter
+// dropdowns. It takes the List operation's filters, a limit and a counts flag; the column path
+// parameter is an enum of the eligible columns. Operations get an x-table extension, as the table
+// is not the last literal segment of their path.
+func addDistinctOperations(doc *openapi3.T) int {
+	if doc.Components == nil {
+		return 0
+	}
+
+	type listOperation struct {
+		path      string
+		tableName string
+		op        *openapi3.Operation
+	}
+
+	var lists []listOperation
+
+	for path, pathItem := range doc.Paths.Map() {
+		if pathItem.Get == nil || !strings.HasSuffix(pathItem.Get.OperationID, "_List") {
+			continue
+		}
+
+		tableName := extractTableNameFromPath(path)
+		if table, ok := pathItem.Get.Extensions["x-table"].(string); ok && table != "" {
+			tableName = table
+		}
+
+		lists = append(lists, listOperation{path: path, tableName: tableName, op: pathItem.Get})
+	}
+
+	count := 0
+
+	for _, list := range lists {
+		schemaRef, ok := doc.Components.Schemas[tableSchemaName(list.tableName)]
+		if !ok || schemaRef.Value == nil {
+			continue
+		}
+
+		columns := distinctColumns(schemaRef.Value, list.op)
+		if len(columns) == 0 {
+			continue
+		}
+
+		doc.Paths.Set(strings.TrimSuffix(list.path, "/")+"/distinct/{column}", &openapi3.PathItem{
+			Get: distinctOperation(doc, list.tableName, list.op, columns),
+		})
+		count++
+	}
+
+	if count > 0 {
+		doc.Components.Schemas[distinctValueSchemaName] = openapi3.NewSchemaRef("", distinctValueSchema())
+		doc.Components.Schemas[distinctResponseSchemaName] = openapi3.NewSchemaRef("", distinctResponseSchema())
+	}
+
+	return count
+}
+
+// distinctColumns returns the sorted columns of a table that distinct values can be listed for:
+// strings, which include LowCardinality and Enum columns under the name encoding, and Enum
+// columns under the value encoding. Columns mapped to another handlers type, and 64-bit integers
+// the proto generator converted to strings (recognised by the format of their filters), are left out.
+func distinctColumns(schema *openapi3.Schema, listOp *openapi3.Operation) []string {
+	integerFilters := make(map[string]bool)
+
+	for _, paramRef := range listOp.Parameters {
+		if paramRef.Value == nil || paramRef.Value.Schema == nil || paramRef.Value.Schema.Value == nil {
+			continue
+		}
+
+		if format := paramRef.Value.Schema.Value.Format; format == "int64" || format == "uint64" {
+			integerFilters[convertParamToFieldName(paramRef.Value.Name)] = true
+		}
+	}
+
+	columns := make([]string, 0, len(schema.Properties))
+
+	for name, propRef := range schema.Properties {
+		prop := propRef.Value
+		if prop == nil || prop.Type == nil {
+			continue
+		}
+
+		goType, _ := prop.Extensions["x-go-type"].(string)
+
+		switch {
+		case goType == enumValueGoType:
+		case goType == "" && prop.Type.Is("string") && prop.Format == "" && !integerFilters[name]:
+		default:
+			continue
+		}
+
+		columns = append(columns, name)
+	}
+
+	slices.Sort(columns)
+
+	return columns
+}
+
+// distinctOperation builds the distinct-values operation of a table from its List operation.
+func distinctOperation(doc *openapi3.T, tableName string, listOp *openapi3.Operation, columns []string) *openapi3.Operation {
+	enum := make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		enum = append(enum, column)
+	}
+
+	columnSchema := openapi3.NewStringSchema()
+	columnSchema.Enum = enum
+	columnSchema.Extensions = map[string]interface{}{"x-go-type": "string"}
+
+	params := openapi3.Parameters{{Value: &openapi3.Parameter{
+		Name:        "column",
+		In:          openapi3.ParameterInPath,
+		Required:    true,
+		Description: "The column to list values of. Enum columns are listed by name.",
+		Schema:      openapi3.NewSchemaRef("", columnSchema),
+	}}}
+
+	for _, paramRef := range listOp.Parameters {
+		if paramRef.Value != nil && (paramRef.Value.In != openapi3.ParameterInQuery || slices.Contains(distinctSkippedParams, paramRef.Value.Name)) {
+			continue
+		}
+
+		params = append(params, paramRef)
+	}
+
+	limitSchema := openapi3.NewInt32Schema().WithMin(1).WithMax(maxDistinctLimit)
+	limitSchema.Default = defaultDistinctLimit
+
+	params = append(params,
+		&openapi3.ParameterRef{Value: &openapi3.Parameter{
+			Name:        "limit",
+			In:          openapi3.ParameterInQuery,
+			Description: fmt.Sprintf("Maximum number of values to return (default: %d, max: %d)", defaultDistinctLimit, maxDistinctLimit),
+			Schema:      openapi3.NewSchemaRef("", limitSchema),
+		}},
+		&openapi3.ParameterRef{Value: &openapi3.Parameter{
+			Name: "counts",
+			In:   openapi3.ParameterInQuery,
+			Description: "Return the number of matching rows for each value and an exact distinct_count. " +
+				"Without counts, values come from topK and distinct_count from uniq, both approximate but cheaper.",
+			Schema: openapi3.NewSchemaRef("", openapi3.NewBoolSchema()),
+		}},
+	)
+
+	responses := openapi3.NewResponses()
+	responses.Set("200", &openapi3.ResponseRef{Value: openapi3.NewResponse().
+		WithDescription("OK").
+		WithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/"+distinctResponseSchemaName, nil))})
+
+	if _, ok := doc.Components.Schemas["Status"]; ok {
+		responses.Set("default", &openapi3.ResponseRef{Value: openapi3.NewResponse().
+			WithDescription("Default error response").
+			WithJSONSchemaRef(openapi3.NewSchemaRef(statusSchemaRef, nil))})
+	}
+
+	return &openapi3.Operation{
+		Tags:        listOp.Tags,
+		Summary:     "List distinct values of a column",
+		Description: "Returns the most frequent values of a String, LowCardinality or Enum column among the rows matching the filters, most frequent first.",
+		OperationID: extractServiceNameFromOperationID(listOp.OperationID) + "_Distinct",
+		Parameters:  params,
+		Responses:   responses,
+		Deprecated:  listOp.Deprecated,
+		Extensions:  map[string]interface{}{"x-table": tableName},
+	}
+}
+
+// distinctValueSchema returns the schema of a single distinct value.
+func distinctValueSchema() *openapi3.Schema {
+	value := openapi3.NewStringSchema()
+	value.Nullable = true
+	value.Description = "The column value, or null for NULL"
+
+	count := openapi3.NewIntegerSchema().WithFormat("uint64")
+	count.Description = "Number of matching rows with the value, when counts is set"
+
+	schema := openapi3.NewObjectSchema().
+		WithProperty("value", value).
+		WithProperty("count", count)
+	schema.Required = []string{"value"}
+
+	return schema
+}
+
+// distinctResponseSchema returns the schema of a distinct-values response.
+func distinctResponseSchema() *openapi3.Schema {
+	distinctCount := openapi3.NewIntegerSchema().WithFormat("uint64")
+	distinctCount.Description = "Number of distinct values among the matching rows, approximate unless counts is set"
+
+	values := openapi3.NewArraySchema()
+	values.Items = openapi3.NewSchemaRef("#/components/schemas/"+distinctValueSchemaName, nil)
+
+	schema := openapi3.NewObjectSchema().
+		WithProperty("values", values).
+		WithProperty("distinct_count", distinctCount)
+	schema.Required = []string{"values", "distinct_count"}
+
+	return schema
+}
+
 // ============================================================================
 // Utility Functions
 // ============================================================================
@@ -915,14 +1434,30 @@ func convertParamToFieldName(paramName string) string {
 	// Remove filter suffixes (longest first to handle multi-part suffixes)
 	suffixes := []string{
 		"_between_max_value",
+		"_has_all_values",
+		"_has_any_values",
 		"_not_in_values",
+		"_has_all_keys",
+		"_has_any_key",
+		"_not_has_key",
+		"_is_not_empty",
 		"_starts_with",
+		"_is_not_null",
+		"_length_gte",
+		"_length_lte",
 		"_ends_with",
 		"_in_values",
+		"_length_eq",
+		"_length_gt",
+		"_length_lt",
 		"_between_min",
+		"_is_empty",
 		"_not_like",
 		"_contains",
+		"_has_key",
+		"_is_null",
 		"_like",
+		"_has",
 		"_gte",
 		"_lte",
 		"_eq",
@@ -1030,26 +1565,6 @@ func writeOpenAPIYAML(doc *openapi3.T, filename string) error {
 // Path Filtering
 // ============================================================================
 
-// loadAPIExcludePatterns loads API exclude patterns from config file.
-func loadAPIExcludePatterns(configFile string) ([]string, error) {
-	data, err := os.ReadFile(configFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
-	}
-
-	var config struct {
-		API struct {
-			Exclude []string `yaml:"exclude"`
-		} `yaml:"api"`
-	}
-
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
-	}
-
-	return config.API.Exclude, nil
-}
-
 // filterExcludedPaths removes paths from the OpenAPI spec that match exclude patterns.
 // Patterns support shell-style wildcards (* matches any characters).
 func filterExcludedPaths(doc *openapi3.T, excludePatterns []string) int {
@@ -1171,3 +1686,468 @@ func extractTableNameFromServiceTag(tagName string) string {
 	// Convert PascalCase to snake_case
 	return camelToSnake(tagName)
 }
+
+// ============================================================================
+// Table Overrides
+// ============================================================================
+
+// applyTableOverrides applies per-table overrides to the paths, schemas and tags of each
+// configured table. Operations of an overridden table get an x-table extension naming the table,
+// so routes keep resolving to it when its path uses an alias.
+func applyTableOverrides(doc *openapi3.T, tables map[string]config.TableConfig) int {
+	if len(tables) == 0 {
+		return 0
+	}
+
+	overridden := make(map[string]bool)
+	renames := make(map[string]string)
+
+	for path, pathItem := range doc.Paths.Map() {
+		tableName := extractTableNameFromPath(path)
+
+		override, ok := tables[tableName]
+		if !ok {
+			continue
+		}
+
+		for _, op := range pathItem.Operations() {
+			applyOperationOverride(op, tableName, override)
+		}
+
+		if override.Alias != "" {
+			renames[path] = aliasPath(path, override.Alias)
+		}
+
+		overridden[tableName] = true
+	}
+
+	for oldPath, newPath := range renames {
+		pathItem := doc.Paths.Value(oldPath)
+		doc.Paths.Delete(oldPath)
+		doc.Paths.Set(newPath, pathItem)
+	}
+
+	for tableName := range overridden {
+		applySchemaOverride(doc, tableName, tables[tableName])
+	}
+
+	for _, tag := range doc.Tags {
+		if override, ok := tables[extractTableNameFromServiceTag(tag.Name)]; ok && override.Description != "" {
+			tag.Description = override.Description
+		}
+	}
+
+	return len(overridden)
+}
+
+// applyOperationOverride removes filters on hidden columns and documents the page size limit,
+// default ordering and deprecation of a single operation.
+func applyOperationOverride(op *openapi3.Operation, tableName string, override config.TableConfig) {
+	if op.Extensions == nil {
+		op.Extensions = make(map[string]interface{})
+	}
+
+	op.Extensions["x-table"] = tableName
+
+	params := make(openapi3.Parameters, 0, len(op.Parameters))
+
+	for _, paramRef := range op.Parameters {
+		param := paramRef.Value
+		if param == nil {
+			params = append(params, paramRef)
+
+			continue
+		}
+
+		// Path parameters are the primary key and can't be hidden
+		if param.In == openapi3.ParameterInQuery && slices.Contains(override.HiddenColumns, convertParamToFieldName(param.Name)) {
+			continue
+		}
+
+		switch {
+		case param.Name == "page_size" && override.MaxPageSize > 0 && param.Schema != nil && param.Schema.Value != nil:
+			param.Schema.Value.Max = openapi3.Float64Ptr(float64(override.MaxPageSize))
+			param.Description = strings.TrimSpace(fmt.Sprintf("%s (at most %d)", param.Description, override.MaxPageSize))
+		case param.Name == "order_by" && override.OrderBy != "" && param.Schema != nil && param.Schema.Value != nil:
+			param.Schema.Value.Default = override.OrderBy
+			param.Description = strings.TrimSpace(fmt.Sprintf("%s (defaults to %q)", param.Description, override.OrderBy))
+		}
+
+		params = append(params, paramRef)
+	}
+
+	op.Parameters = params
+
+	if override.Deprecated {
+		op.Deprecated = true
+
+		if override.Sunset != "" {
+			op.Description = strings.TrimSpace(fmt.Sprintf("%s\n\nDeprecated: this endpoint will be removed after %s.", op.Description, override.Sunset))
+		}
+	}
+}
+
+// applySchemaOverride removes hidden columns from a table's item schema and replaces its description.
+func applySchemaOverride(doc *openapi3.T, tableName string, override config.TableConfig) {
+	if doc.Components == nil {
+		return
+	}
+
+	schemaRef, ok := doc.Components.Schemas[tableSchemaName(tableName)]
+	if !ok || schemaRef.Value == nil {
+		return
+	}
+
+	for _, column := range override.HiddenColumns {
+		delete(schemaRef.Value.Properties, column)
+		schemaRef.Value.Required = slices.DeleteFunc(schemaRef.Value.Required, func(name string) bool { return name == column })
+	}
+
+	if override.Description != "" {
+		schemaRef.Value.Description = override.Description
+	}
+}
+
+// aliasPath replaces the table segment of a path with its alias.
+// e.g. "/api/v1/fct_block/{slot}" with alias "blocks" -> "/api/v1/blocks/{slot}".
+func aliasPath(path, alias string) string {
+	parts := strings.Split(path, "/")
+	if len(parts) < 4 {
+		return path
+	}
+
+	// parts[0] is empty (leading slash), the table is the third segment after /api/v1/
+	parts[3] = alias
+
+	return strings.Join(parts, "/")
+}
+
+// tableSchemaName returns the item schema name of a table as generated by protoc-gen-openapi
+// and fixSchemaNames.
+// e.g. "fct_attestation_first_seen_chunked_50ms" -> "FctAttestationFirstSeenChunked50Ms".
+func tableSchemaName(tableName string) string {
+	parts := strings.Split(tableName, "_")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+
+	return fixCapitalization(strings.Join(parts, ""))
+}
+
+// ============================================================================
+// Bigint Encoding
+// ============================================================================
+
+// bigintGoType is the handlers type that encodes 64-bit integers as JSON strings.
+const bigintGoType = "BigIntString"
+
+// stringifyBigints changes 64-bit integer columns matching the configured "field" or
+// "table.field" patterns, or all of them when all is set, to strings with an int64/uint64 format.
+// Columns the proto generator already converted to strings keep the format of their filters.
+// The x-go-type extension makes oapi-codegen use handlers.BigIntString for them.
+func stringifyBigints(doc *openapi3.T, patterns []string, all bool) int {
+	if (len(patterns) == 0 && !all) || doc.Components == nil {
+		return 0
+	}
+
+	// Filter formats by table and field, e.g. "fct_block" -> "execution_payload_value" -> "uint64"
+	filterFormats := make(map[string]map[string]string)
+
+	for path, pathItem := range doc.Paths.Map() {
+		tableName := extractTableNameFromPath(path)
+		if tableName == "" {
+			continue
+		}
+
+		if _, ok := filterFormats[tableName]; !ok {
+			filterFormats[tableName] = make(map[string]string)
+		}
+
+		for _, op := range pathItem.Operations() {
+			for _, paramRef := range op.Parameters {
+				if paramRef.Value == nil || paramRef.Value.Schema == nil || paramRef.Value.Schema.Value == nil {
+					continue
+				}
+
+				if format := paramRef.Value.Schema.Value.Format; format == "int64" || format == "uint64" {
+					filterFormats[tableName][convertParamToFieldName(paramRef.Value.Name)] = format
+				}
+			}
+		}
+	}
+
+	count := 0
+
+	for tableName, formats := range filterFormats {
+		schemaRef, ok := doc.Components.Schemas[tableSchemaName(tableName)]
+		if !ok || schemaRef.Value == nil {
+			continue
+		}
+
+		for name, propRef := range schemaRef.Value.Properties {
+			prop := propRef.Value
+			if prop == nil || prop.Type == nil {
+				continue
+			}
+
+			matched := matchesBigintField(tableName, name, patterns)
+
+			var format string
+
+			switch {
+			case prop.Type.Is("integer") && (prop.Format == "int64" || prop.Format == "uint64") && (all || matched):
+				format = prop.Format
+			case prop.Type.Is("string") && prop.Format == "" && matched:
+				format = formats[name]
+				if format == "" {
+					format = "uint64"
+				}
+			default:
+				continue
+			}
+
+			prop.Type = &openapi3.Types{"string"}
+			prop.Format = format
+			prop.Pattern = `^-?\d+$`
+
+			if prop.Extensions == nil {
+				prop.Extensions = make(map[string]interface{})
+			}
+
+			prop.Extensions["x-go-type"] = bigintGoType
+			prop.Extensions["x-go-type-skip-optional-pointer"] = true
+			count++
+		}
+	}
+
+	return count
+}
+
+// matchesBigintField reports whether a column matches any "field" or "table.field" pattern.
+func matchesBigintField(tableName, field string, patterns []string) bool {
+	for _, pattern := range patterns {
+		tablePattern, fieldPattern, ok := strings.Cut(pattern, ".")
+		if !ok {
+			tablePattern, fieldPattern = "*", pattern
+		}
+
+		if matchesPattern(tableName, tablePattern) && matchesPattern(field, fieldPattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ============================================================================
+// Column Encodings
+// ============================================================================
+
+// Encoding types in the handlers package, selected per column with x-go-type.
+const (
+	rfc3339TimeGoType   = "RFC3339Time"
+	epochTimeGoType     = "EpochTime"
+	decimalStringGoType = "DecimalString"
+	decimalNumberGoType = "DecimalNumber"
+	enumValueGoType     = "EnumValue"
+)
+
+// Patterns recognising Decimal and Enum columns from their description or name.
+var (
+	decimalColumnPattern = regexp.MustCompile(`(?i)\bdecimal`)
+	enumColumnPattern    = regexp.MustCompile(`(?i)\benum(8|16)?\b`)
+)
+
+// applyColumnEncodings applies the api.encoding policy to DateTime, DateTime64, Decimal and Enum
+// columns: it sets the schema type and format clients see and, through x-go-type, the handlers type
+// that scans and encodes the column. DateTime columns stay uint32 Unix seconds under the epoch
+// encoding, and Enum columns stay strings under the name encoding. Columns already mapped to a
+// handlers type, such as bigints, are left alone.
+func applyColumnEncodings(doc *openapi3.T, dateTime, decimal, enum string) int {
+	if doc.Components == nil {
+		return 0
+	}
+
+	tables := make(map[string]struct{})
+
+	for path := range doc.Paths.Map() {
+		if tableName := extractTableNameFromPath(path); tableName != "" {
+			tables[tableName] = struct{}{}
+		}
+	}
+
+	count := 0
+
+	for tableName := range tables {
+		schemaRef, ok := doc.Components.Schemas[tableSchemaName(tableName)]
+		if !ok || schemaRef.Value == nil {
+			continue
+		}
+
+		for name, propRef := range schemaRef.Value.Properties {
+			prop := propRef.Value
+			if prop == nil || prop.Type == nil {
+				continue
+			}
+
+			if _, ok := prop.Extensions["x-go-type"]; ok {
+				continue
+			}
+
+			var goType string
+
+			switch kind := columnKind(name, prop); {
+			case (kind == "datetime" || kind == "datetime64") && dateTime == "rfc3339":
+				prop.Type = &openapi3.Types{"string"}
+				prop.Format = "date-time"
+				goType = rfc3339TimeGoType
+			case kind == "datetime64":
+				prop.Type = &openapi3.Types{"number"}
+				prop.Format = "unix-time"
+				goType = epochTimeGoType
+			case kind == "decimal" && decimal == "string":
+				prop.Type = &openapi3.Types{"string"}
+				prop.Format = "decimal"
+				prop.Pattern = `^-?\d+(\.\d+)?$`
+				goType = decimalStringGoType
+			case kind == "decimal":
+				prop.Type = &openapi3.Types{"number"}
+				prop.Format = "decimal"
+				goType = decimalNumberGoType
+			case kind == "enum" && enum == "value":
+				prop.Type = &openapi3.Types{"integer"}
+				prop.Format = "int16"
+				goType = enumValueGoType
+			default:
+				continue
+			}
+
+			if prop.Extensions == nil {
+				prop.Extensions = make(map[string]interface{})
+			}
+
+			prop.Extensions["x-go-type"] = goType
+			prop.Extensions["x-go-type-skip-optional-pointer"] = true
+			count++
+		}
+	}
+
+	return count
+}
+
+// columnKind classifies a column as "datetime", "datetime64", "decimal" or "enum", or "" for
+// anything else. clickhouse-proto-gen maps DateTime to uint32, DateTime64 to a 64-bit integer,
+// Decimal to a double and Enum to a string, so the ClickHouse type is recognised from the column
+// comment carried into the description, or from the *_date_time naming convention.
+func columnKind(name string, prop *openapi3.Schema) string {
+	switch {
+	case prop.Type.Is("integer") && (strings.Contains(prop.Description, "DateTime") || strings.HasSuffix(name, "_date_time")):
+		if strings.Contains(prop.Description, "DateTime64") || prop.Format == "int64" || prop.Format == "uint64" {
+			return "datetime64"
+		}
+
+		return "datetime"
+	case prop.Type.Is("number") &&
+		(decimalColumnPattern.MatchString(prop.Description) || decimalColumnPattern.MatchString(name)):
+		return "decimal"
+	case prop.Type.Is("string") && prop.Format == "" &&
+		(enumColumnPattern.MatchString(prop.Description) || len(prop.Enum) > 0):
+		return "enum"
+	}
+
+	return ""
+}
+
+// ============================================================================
+// DateTime Filters
+// ============================================================================
+
+// dateTimeOperators are the filter operators that accept RFC 3339 and relative DateTime values.
+var dateTimeOperators = []string{"eq", "ne", "lt", "lte", "gt", "gte"}
+
+// dateTimePrecisionPattern extracts the precision of a DateTime64 column from its description.
+var dateTimePrecisionPattern = regexp.MustCompile(`DateTime64\((\d)`)
+
+// dateTimeFilterNote documents the accepted DateTime values on each filter parameter.
+const dateTimeFilterNote = "Accepts Unix time, an RFC 3339 timestamp (2024-01-01T00:00:00Z) " +
+	"or a time relative to now in UTC (now-1h, now-30m, now-7d/d to round down to the start of the day; " +
+	"units s, m, h, d, w, M, y). Encode + as %2B (now%2B1h, 2024-01-01T00:00:00%2B02:00), " +
+	"as an unencoded + is read as a space."
+
+// markDateTimeFilters changes the comparison filters on DateTime and DateTime64 columns to strings,
+// so they accept RFC 3339 timestamps and relative expressions as well as Unix time.
+// QueryParameterValidation normalizes the values to Unix time using the x-datetime-format and
+// x-datetime-precision extensions, and the generated handlers convert them for the filter builders.
+func markDateTimeFilters(doc *openapi3.T) int {
+	count := 0
+
+	for _, pathItem := range doc.Paths.Map() {
+		for _, op := range pathItem.Operations() {
+			for _, paramRef := range op.Parameters {
+				param := paramRef.Value
+				if param == nil || param.In != openapi3.ParameterInQuery || param.Schema == nil || param.Schema.Value == nil {
+					continue
+				}
+
+				schema := param.Schema.Value
+				if schema.Type == nil || !schema.Type.Is("integer") {
+					continue
+				}
+
+				if schema.Format != "uint32" && schema.Format != "uint64" && schema.Format != "int64" {
+					continue
+				}
+
+				field := convertParamToFieldName(param.Name)
+				if !slices.Contains(dateTimeOperators, strings.TrimPrefix(param.Name, field+"_")) {
+					continue
+				}
+
+				precision, ok := dateTimePrecision(field, schema.Format, param.Description)
+				if !ok {
+					continue
+				}
+
+				if param.Extensions == nil {
+					param.Extensions = make(map[string]interface{})
+				}
+
+				param.Extensions["x-datetime-format"] = schema.Format
+				if precision > 0 {
+					param.Extensions["x-datetime-precision"] = precision
+				}
+
+				param.Schema = &openapi3.SchemaRef{Value: &openapi3.Schema{
+					Type:    &openapi3.Types{"string"},
+					Example: "now-1h",
+				}}
+				param.Description = strings.TrimSpace(param.Description + " " + dateTimeFilterNote)
+				count++
+			}
+		}
+	}
+
+	return count
+}
+
+// dateTimePrecision reports whether a filtered column is a DateTime, and the number of fractional
+// digits of its Unix time. clickhouse-proto-gen maps DateTime to uint32 and DateTime64 to 64-bit
+// integers; columns are recognised by a DateTime type in their description or by the *_date_time
+// naming convention. DateTime64 columns without an explicit precision are assumed to be DateTime64(3).
+func dateTimePrecision(field, format, description string) (int, bool) {
+	if matches := dateTimePrecisionPattern.FindStringSubmatch(description); matches != nil {
+		return int(matches[1][0] - '0'), true
+	}
+
+	if !strings.Contains(description, "DateTime") && !strings.HasSuffix(field, "_date_time") {
+		return 0, false
+	}
+
+	if format == "uint32" {
+		return 0, true
+	}
+
+	return 3, true
+}
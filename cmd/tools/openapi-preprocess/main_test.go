@@ -3,11 +3,14 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"regexp"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
 )
 
 // ============================================================================
@@ -899,9 +902,7 @@ func TestApplyTransformations(t *testing.T) {
 
 	annotations := ProtoFieldAnnotations{}
 
-	excludePatterns := []string{} // No exclusions for this test
-
-	stats := applyTransformations(doc, descriptions, fieldTypes, annotations, excludePatterns)
+	stats := applyTransformations(doc, descriptions, fieldTypes, annotations, ProtoSortingKeys{}, &config.Config{})
 
 	// Verify stats
 	assert.Equal(t, 1, stats.FiltersFlatted, "expected 1 parameter to be flattened")
@@ -924,6 +925,495 @@ func TestApplyTransformations(t *testing.T) {
 	assert.Equal(t, "int64", countProp.Format)
 }
 
+func TestApplyTableOverrides(t *testing.T) {
+	queryParam := func(name string) *openapi3.ParameterRef {
+		return &openapi3.ParameterRef{Value: &openapi3.Parameter{
+			Name:   name,
+			In:     openapi3.ParameterInQuery,
+			Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+		}}
+	}
+
+	doc := &openapi3.T{
+		Paths: openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{
+				"FctBlock": &openapi3.SchemaRef{Value: &openapi3.Schema{
+					Properties: openapi3.Schemas{
+						"slot":          &openapi3.SchemaRef{Value: &openapi3.Schema{}},
+						"internal_note": &openapi3.SchemaRef{Value: &openapi3.Schema{}},
+					},
+				}},
+			},
+		},
+		Tags: openapi3.Tags{{Name: "FctBlockService"}, {Name: "FctAttestationService"}},
+	}
+	doc.Paths.Set("/api/v1/fct_block", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			OperationID: "FctBlockService_List",
+			Parameters: openapi3.Parameters{
+				queryParam("slot_eq"),
+				queryParam("internal_note_eq"),
+				queryParam("internal_note_is_null"),
+				queryParam("page_size"),
+				queryParam("order_by"),
+			},
+		},
+	})
+	doc.Paths.Set("/api/v1/fct_block/{slot}", &openapi3.PathItem{
+		Get: &openapi3.Operation{OperationID: "FctBlockService_Get"},
+	})
+	doc.Paths.Set("/api/v1/fct_attestation", &openapi3.PathItem{
+		Get: &openapi3.Operation{OperationID: "FctAttestationService_List"},
+	})
+
+	count := applyTableOverrides(doc, map[string]config.TableConfig{
+		"fct_block": {
+			Alias:         "blocks",
+			Description:   "Canonical beacon blocks",
+			HiddenColumns: []string{"internal_note"},
+			OrderBy:       "slot DESC",
+			MaxPageSize:   500,
+			Deprecated:    true,
+			Sunset:        "2026-01-01T00:00:00Z",
+		},
+	})
+	assert.Equal(t, 1, count)
+
+	// Paths are served under the alias
+	assert.Nil(t, doc.Paths.Value("/api/v1/fct_block"))
+	require.NotNil(t, doc.Paths.Value("/api/v1/blocks"))
+	require.NotNil(t, doc.Paths.Value("/api/v1/blocks/{slot}"))
+	require.NotNil(t, doc.Paths.Value("/api/v1/fct_attestation"))
+
+	list := doc.Paths.Value("/api/v1/blocks").Get
+	assert.Equal(t, "fct_block", list.Extensions["x-table"])
+	assert.True(t, list.Deprecated)
+	assert.Contains(t, list.Description, "2026-01-01T00:00:00Z")
+	assert.False(t, doc.Paths.Value("/api/v1/fct_attestation").Get.Deprecated)
+
+	// Filters on hidden columns are removed
+	names := make([]string, 0, len(list.Parameters))
+	for _, p := range list.Parameters {
+		names = append(names, p.Value.Name)
+	}
+
+	assert.Equal(t, []string{"slot_eq", "page_size", "order_by"}, names)
+
+	pageSize := list.Parameters.GetByInAndName(openapi3.ParameterInQuery, "page_size")
+	require.NotNil(t, pageSize.Schema.Value.Max)
+	assert.InDelta(t, 500, *pageSize.Schema.Value.Max, 0)
+	assert.Equal(t, "slot DESC", list.Parameters.GetByInAndName(openapi3.ParameterInQuery, "order_by").Schema.Value.Default)
+
+	// The item schema and tag are updated
+	schema := doc.Components.Schemas["FctBlock"].Value
+	assert.NotContains(t, schema.Properties, "internal_note")
+	assert.Contains(t, schema.Properties, "slot")
+	assert.Equal(t, "Canonical beacon blocks", schema.Description)
+	assert.Equal(t, "Canonical beacon blocks", doc.Tags.Get("FctBlockService").Description)
+	assert.Empty(t, doc.Tags.Get("FctAttestationService").Description)
+}
+
+func TestStringifyBigints(t *testing.T) {
+	newDoc := func() *openapi3.T {
+		doc := &openapi3.T{
+			Paths: openapi3.NewPaths(),
+			Components: &openapi3.Components{
+				Schemas: openapi3.Schemas{
+					"FctBlock": &openapi3.SchemaRef{Value: &openapi3.Schema{
+						Properties: openapi3.Schemas{
+							"slot":                    openapi3.NewSchemaRef("", openapi3.NewIntegerSchema().WithFormat("uint32")),
+							"block_number":            openapi3.NewSchemaRef("", openapi3.NewIntegerSchema().WithFormat("uint64")),
+							"gas_used":                openapi3.NewSchemaRef("", openapi3.NewIntegerSchema().WithFormat("int64")),
+							"execution_payload_value": openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+							"block_root":              openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+						},
+					}},
+				},
+			},
+		}
+		doc.Paths.Set("/api/v1/fct_block", &openapi3.PathItem{Get: &openapi3.Operation{
+			OperationID: "FctBlockService_List",
+			Parameters: openapi3.Parameters{
+				{Value: openapi3.NewQueryParameter("execution_payload_value_gte").
+					WithSchema(openapi3.NewIntegerSchema().WithFormat("uint64"))},
+			},
+		}})
+
+		return doc
+	}
+
+	t.Run("configured fields", func(t *testing.T) {
+		doc := newDoc()
+
+		count := stringifyBigints(doc, []string{"block_number", "fct_*.execution_payload_value", "other_table.gas_used"}, false)
+		assert.Equal(t, 2, count)
+
+		props := doc.Components.Schemas["FctBlock"].Value.Properties
+
+		// Integer columns become strings with their integer format
+		assert.True(t, props["block_number"].Value.Type.Is("string"))
+		assert.Equal(t, "uint64", props["block_number"].Value.Format)
+		assert.Equal(t, bigintGoType, props["block_number"].Value.Extensions["x-go-type"])
+		assert.Equal(t, true, props["block_number"].Value.Extensions["x-go-type-skip-optional-pointer"])
+
+		// Columns already converted by the proto generator take the format of their filters
+		assert.Equal(t, "uint64", props["execution_payload_value"].Value.Format)
+		assert.Equal(t, bigintGoType, props["execution_payload_value"].Value.Extensions["x-go-type"])
+
+		// Unmatched columns are unchanged
+		assert.True(t, props["gas_used"].Value.Type.Is("integer"))
+		assert.Nil(t, props["block_root"].Value.Extensions)
+	})
+
+	t.Run("all 64-bit integers", func(t *testing.T) {
+		doc := newDoc()
+
+		count := stringifyBigints(doc, nil, true)
+		assert.Equal(t, 2, count)
+
+		props := doc.Components.Schemas["FctBlock"].Value.Properties
+		assert.Equal(t, "int64", props["gas_used"].Value.Format)
+		assert.True(t, props["gas_used"].Value.Type.Is("string"))
+		assert.True(t, props["slot"].Value.Type.Is("integer"))
+		assert.Nil(t, props["execution_payload_value"].Value.Extensions)
+	})
+}
+
+func TestApplyColumnEncodings(t *testing.T) {
+	prop := func(schema *openapi3.Schema, description string) *openapi3.SchemaRef {
+		schema.Description = description
+
+		return openapi3.NewSchemaRef("", schema)
+	}
+
+	newDoc := func() *openapi3.T {
+		doc := &openapi3.T{
+			Paths: openapi3.NewPaths(),
+			Components: &openapi3.Components{
+				Schemas: openapi3.Schemas{
+					"FctDataTypesComplex": &openapi3.SchemaRef{Value: &openapi3.Schema{
+						Properties: openapi3.Schemas{
+							"id":                prop(openapi3.NewIntegerSchema().WithFormat("uint64"), "Primary identifier"),
+							"updated_date_time": prop(openapi3.NewIntegerSchema().WithFormat("uint32"), "Last update"),
+							"datetime64_nanos":  prop(openapi3.NewIntegerSchema().WithFormat("int64"), "DateTime with nanosecond precision"),
+							"decimal128_value":  prop(openapi3.NewFloat64Schema().WithFormat("double"), "High-precision decimal"),
+							"float64_value":     prop(openapi3.NewFloat64Schema().WithFormat("double"), "Double precision float"),
+							"enum8_value":       prop(openapi3.NewStringSchema(), "Enum with 8-bit storage"),
+							"string_value":      prop(openapi3.NewStringSchema(), "Variable length string"),
+						},
+					}},
+				},
+			},
+		}
+		doc.Paths.Set("/api/v1/fct_data_types_complex", &openapi3.PathItem{Get: &openapi3.Operation{
+			OperationID: "FctDataTypesComplexService_List",
+		}})
+		doc.Paths.Set("/api/v1/fct_data_types_complex/{id}", &openapi3.PathItem{Get: &openapi3.Operation{
+			OperationID: "FctDataTypesComplexService_Get",
+		}})
+
+		return doc
+	}
+
+	goType := func(doc *openapi3.T, name string) any {
+		return doc.Components.Schemas["FctDataTypesComplex"].Value.Properties[name].Value.Extensions["x-go-type"]
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		doc := newDoc()
+
+		assert.Equal(t, 2, applyColumnEncodings(doc, "epoch", "number", "name"))
+
+		props := doc.Components.Schemas["FctDataTypesComplex"].Value.Properties
+
+		// DateTime stays Unix seconds; DateTime64 becomes fractional Unix seconds
+		assert.True(t, props["updated_date_time"].Value.Type.Is("integer"))
+		assert.Nil(t, goType(doc, "updated_date_time"))
+		assert.True(t, props["datetime64_nanos"].Value.Type.Is("number"))
+		assert.Equal(t, "unix-time", props["datetime64_nanos"].Value.Format)
+		assert.Equal(t, epochTimeGoType, goType(doc, "datetime64_nanos"))
+
+		// Decimals keep their digits; floats and enum names are unchanged
+		assert.Equal(t, "decimal", props["decimal128_value"].Value.Format)
+		assert.Equal(t, decimalNumberGoType, goType(doc, "decimal128_value"))
+		assert.Nil(t, goType(doc, "float64_value"))
+		assert.Nil(t, goType(doc, "enum8_value"))
+	})
+
+	t.Run("rfc3339, string decimals and enum values", func(t *testing.T) {
+		doc := newDoc()
+
+		assert.Equal(t, 4, applyColumnEncodings(doc, "rfc3339", "string", "value"))
+
+		props := doc.Components.Schemas["FctDataTypesComplex"].Value.Properties
+
+		for _, name := range []string{"updated_date_time", "datetime64_nanos"} {
+			assert.True(t, props[name].Value.Type.Is("string"))
+			assert.Equal(t, "date-time", props[name].Value.Format)
+			assert.Equal(t, rfc3339TimeGoType, goType(doc, name))
+		}
+
+		assert.True(t, props["decimal128_value"].Value.Type.Is("string"))
+		assert.Equal(t, decimalStringGoType, goType(doc, "decimal128_value"))
+		assert.True(t, props["enum8_value"].Value.Type.Is("integer"))
+		assert.Equal(t, enumValueGoType, goType(doc, "enum8_value"))
+		assert.Equal(t, true, props["enum8_value"].Value.Extensions["x-go-type-skip-optional-pointer"])
+		assert.Nil(t, goType(doc, "string_value"))
+		assert.Nil(t, goType(doc, "id"))
+	})
+}
+
+func TestMarkDateTimeFilters(t *testing.T) {
+	param := func(name, format, description string) *openapi3.ParameterRef {
+		p := openapi3.NewQueryParameter(name).WithSchema(openapi3.NewIntegerSchema().WithFormat(format))
+		p.Description = description
+
+		return &openapi3.ParameterRef{Value: p}
+	}
+
+	doc := &openapi3.T{Paths: openapi3.NewPaths()}
+	doc.Paths.Set("/api/v1/fct_block", &openapi3.PathItem{Get: &openapi3.Operation{
+		OperationID: "FctBlockService_List",
+		Parameters: openapi3.Parameters{
+			param("slot_start_date_time_gte", "uint32", "The wall clock time when the slot started (filter: gte)"),
+			param("slot_start_date_time_in_values", "uint32", ""),
+			param("seen_at_lt", "int64", "When the block was seen, DateTime64(6) (filter: lt)"),
+			param("event_date_time_eq", "int64", ""),
+			param("slot_eq", "uint32", "The slot number (filter: eq)"),
+		},
+	}})
+
+	assert.Equal(t, 3, markDateTimeFilters(doc))
+
+	params := doc.Paths.Find("/api/v1/fct_block").Get.Parameters
+
+	// DateTime columns are detected by name and keep their filter format in an extension
+	gte := params.GetByInAndName("query", "slot_start_date_time_gte")
+	assert.True(t, gte.Schema.Value.Type.Is("string"))
+	assert.Equal(t, "uint32", gte.Extensions["x-datetime-format"])
+	assert.NotContains(t, gte.Extensions, "x-datetime-precision")
+	assert.Contains(t, gte.Description, "now-7d/d")
+	assert.Contains(t, gte.Description, "Encode + as %2B")
+
+	// DateTime64 precision comes from the description, defaulting to milliseconds
+	assert.Equal(t, 6, params.GetByInAndName("query", "seen_at_lt").Extensions["x-datetime-precision"])
+	assert.Equal(t, 3, params.GetByInAndName("query", "event_date_time_eq").Extensions["x-datetime-precision"])
+
+	// List operators and other columns are unchanged
+	assert.True(t, params.GetByInAndName("query", "slot_start_date_time_in_values").Schema.Value.Type.Is("integer"))
+	assert.True(t, params.GetByInAndName("query", "slot_eq").Schema.Value.Type.Is("integer"))
+}
+
+func TestLoadSortingKeys(t *testing.T) {
+	tmpDir := t.TempDir()
+	proto := `syntax = "proto3";
+service FctBlockService {
+  rpc List(ListFctBlockRequest) returns (ListFctBlockResponse);
+}
+message ListFctBlockRequest {
+  // Filter by block_root - The root of the block (ORDER BY column 3 - optional)
+  StringFilter block_root = 3;
+  // Filter by slot_start_date_time - The wall clock time when the slot started
+  // (PRIMARY KEY - required)
+  UInt32Filter slot_start_date_time = 1;
+  // Filter by slot - The slot number (ORDER BY column 2 - optional)
+  UInt32Filter slot = 2;
+  // Filter by proposer_index - The proposer
+  UInt32Filter proposer_index = 4;
+}
+message GetFctBlockRequest {
+  // Primary key (PRIMARY KEY - required)
+  uint32 epoch = 1;
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "fct_block.proto"), []byte(proto), 0600))
+
+	sortingKeys, err := loadSortingKeys(tmpDir)
+	require.NoError(t, err)
+	assert.Equal(t, ProtoSortingKeys{
+		"fctblockservice": {"slot_start_date_time", "slot", "block_root"},
+	}, sortingKeys)
+}
+
+func TestDocumentOrderBy(t *testing.T) {
+	orderBy := func(defaultValue any) *openapi3.ParameterRef {
+		schema := openapi3.NewStringSchema()
+		schema.Default = defaultValue
+
+		return &openapi3.ParameterRef{Value: openapi3.NewQueryParameter("order_by").WithSchema(schema)}
+	}
+
+	doc := &openapi3.T{
+		Paths: openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{
+				"FctBlock": openapi3.NewObjectSchema().
+					WithProperty("slot", openapi3.NewIntegerSchema()).
+					WithProperty("block_root", openapi3.NewStringSchema()).
+					WithProperty("blob_hashes", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).NewRef(),
+			},
+		},
+	}
+	doc.Paths.Set("/api/v1/fct_block", &openapi3.PathItem{Get: &openapi3.Operation{
+		OperationID: "FctBlockService_List",
+		Parameters:  openapi3.Parameters{orderBy(nil)},
+	}})
+	doc.Paths.Set("/api/v1/blocks", &openapi3.PathItem{Get: &openapi3.Operation{
+		OperationID: "FctBlockService_List",
+		Extensions:  map[string]interface{}{"x-table": "fct_block"},
+		Parameters:  openapi3.Parameters{orderBy("slot DESC")},
+	}})
+
+	// Key columns missing from the schema, e.g. hidden ones, are left out of the default
+	count := documentOrderBy(doc, ProtoSortingKeys{"fctblockservice": {"slot", "hidden", "block_root"}})
+	assert.Equal(t, 2, count)
+
+	param := doc.Paths.Value("/api/v1/fct_block").Get.Parameters.GetByInAndName("query", "order_by")
+	assert.Equal(t, []string{"block_root", "slot"}, param.Extensions["x-sortable-columns"])
+	assert.Equal(t, "slot,block_root", param.Schema.Value.Default)
+	assert.Contains(t, param.Description, "asc or desc")
+	assert.Contains(t, param.Description, "Sortable columns: block_root, slot.")
+
+	// The pattern accepts what the middleware does, apart from repeated columns
+	pattern := regexp.MustCompile(param.Schema.Value.Pattern)
+	for _, valid := range []string{"slot", "slot desc,block_root ASC", " block_root , slot Desc "} {
+		assert.True(t, pattern.MatchString(valid), valid)
+	}
+
+	for _, invalid := range []string{"", "blob_hashes", "slot sideways", "slot,", "slots", "slot;DROP"} {
+		assert.False(t, pattern.MatchString(invalid), invalid)
+	}
+
+	// A configured default is kept
+	aliased := doc.Paths.Value("/api/v1/blocks").Get.Parameters.GetByInAndName("query", "order_by")
+	assert.Equal(t, []string{"block_root", "slot"}, aliased.Extensions["x-sortable-columns"])
+	assert.Equal(t, "slot DESC", aliased.Schema.Value.Default)
+	assert.Regexp(t, aliased.Schema.Value.Pattern, "slot DESC")
+}
+
+func TestAddDistinctOperations(t *testing.T) {
+	filter := func(name, format string) *openapi3.ParameterRef {
+		schema := openapi3.NewStringSchema()
+		schema.Format = format
+
+		return &openapi3.ParameterRef{Value: openapi3.NewQueryParameter(name).WithSchema(schema)}
+	}
+
+	enumValue := openapi3.NewIntegerSchema().WithFormat("int16")
+	enumValue.Extensions = map[string]any{"x-go-type": enumValueGoType}
+
+	bigint := openapi3.NewStringSchema().WithFormat("uint64")
+	bigint.Extensions = map[string]any{"x-go-type": bigintGoType}
+
+	doc := &openapi3.T{
+		Paths: openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{
+				"Status": openapi3.NewObjectSchema().NewRef(),
+				"FctBlock": openapi3.NewObjectSchema().
+					WithProperty("slot", openapi3.NewIntegerSchema().WithFormat("uint32")).
+					WithProperty("meta_client_name", openapi3.NewStringSchema()).
+					WithProperty("status", enumValue).
+					WithProperty("execution_payload_value", bigint).
+					WithProperty("gas_used", openapi3.NewStringSchema()).
+					WithProperty("block_root", openapi3.NewStringSchema().WithFormat("byte")).NewRef(),
+				"FctEmpty": openapi3.NewObjectSchema().
+					WithProperty("slot", openapi3.NewIntegerSchema()).NewRef(),
+			},
+		},
+	}
+	doc.Paths.Set("/api/v1/blocks", &openapi3.PathItem{Get: &openapi3.Operation{
+		OperationID: "FctBlockService_List",
+		Tags:        []string{"FctBlockService"},
+		Extensions:  map[string]any{"x-table": "fct_block"},
+		Deprecated:  true,
+		Parameters: openapi3.Parameters{
+			filter("meta_client_name_eq", ""),
+			filter("gas_used_eq", "uint64"),
+			filter("page_size", ""),
+			filter("page_token", ""),
+			filter("order_by", ""),
+		},
+	}})
+	doc.Paths.Set("/api/v1/blocks/{slot}", &openapi3.PathItem{Get: &openapi3.Operation{OperationID: "FctBlockService_Get"}})
+	doc.Paths.Set("/api/v1/fct_empty", &openapi3.PathItem{Get: &openapi3.Operation{OperationID: "FctEmptyService_List"}})
+
+	assert.Equal(t, 1, addDistinctOperations(doc))
+	assert.Nil(t, doc.Paths.Value("/api/v1/fct_empty/distinct/{column}"), "tables without eligible columns are skipped")
+
+	pathItem := doc.Paths.Value("/api/v1/blocks/distinct/{column}")
+	require.NotNil(t, pathItem)
+
+	op := pathItem.Get
+	assert.Equal(t, "FctBlockService_Distinct", op.OperationID)
+	assert.Equal(t, "fct_block", op.Extensions["x-table"])
+	assert.Equal(t, []string{"FctBlockService"}, op.Tags)
+	assert.True(t, op.Deprecated)
+
+	// Strings and Enum values are eligible; bytes and stringified integers are not
+	column := op.Parameters.GetByInAndName(openapi3.ParameterInPath, "column")
+	require.NotNil(t, column)
+	assert.Equal(t, []any{"meta_client_name", "status"}, column.Schema.Value.Enum)
+	assert.Equal(t, "string", column.Schema.Value.Extensions["x-go-type"])
+
+	// Filters are kept, paging and ordering replaced by limit and counts
+	names := make([]string, 0, len(op.Parameters))
+	for _, p := range op.Parameters {
+		names = append(names, p.Value.Name)
+	}
+
+	assert.Equal(t, []string{"column", "meta_client_name_eq", "gas_used_eq", "limit", "counts"}, names)
+
+	limit := op.Parameters.GetByInAndName(openapi3.ParameterInQuery, "limit")
+	assert.InDelta(t, maxDistinctLimit, *limit.Schema.Value.Max, 0)
+
+	assert.Equal(t, "#/components/schemas/ListDistinctValuesResponse",
+		op.Responses.Value("200").Value.Content.Get("application/json").Schema.Ref)
+	assert.Equal(t, statusSchemaRef, op.Responses.Default().Value.Content.Get("application/json").Schema.Ref)
+	assert.Contains(t, doc.Components.Schemas, distinctValueSchemaName)
+	assert.Contains(t, doc.Components.Schemas, distinctResponseSchemaName)
+}
+
+func TestTableSchemaName(t *testing.T) {
+	assert.Equal(t, "FctBlock", tableSchemaName("fct_block"))
+	assert.Equal(t, "FctAttestationFirstSeenChunked50Ms", tableSchemaName("fct_attestation_first_seen_chunked_50ms"))
+}
+
+func TestAddProblemDetails(t *testing.T) {
+	statusResponse := openapi3.NewResponse().WithDescription("Default error response").
+		WithContent(openapi3.NewContentWithJSONSchemaRef(openapi3.NewSchemaRef(statusSchemaRef, nil)))
+
+	okResponse := &openapi3.ResponseRef{
+		Value: openapi3.NewResponse().WithDescription("OK").
+			WithContent(openapi3.NewContentWithJSONSchemaRef(openapi3.NewSchemaRef("#/components/schemas/ListFctBlockResponse", nil))),
+	}
+
+	doc := &openapi3.T{Paths: openapi3.NewPaths()}
+	doc.Paths.Set("/api/v1/fct_block", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			Responses: openapi3.NewResponses(
+				openapi3.WithStatus(200, okResponse),
+				openapi3.WithName("default", statusResponse),
+			),
+		},
+	})
+
+	count := addProblemDetails(doc)
+	assert.Equal(t, 1, count)
+
+	require.Contains(t, doc.Components.Schemas, problemSchemaName)
+	assert.Contains(t, doc.Components.Schemas[problemSchemaName].Value.Properties, "instance")
+
+	responses := doc.Paths.Value("/api/v1/fct_block").Get.Responses
+
+	problem := responses.Default().Value.Content.Get(problemContentType)
+	require.NotNil(t, problem)
+	assert.Equal(t, "#/components/schemas/Problem", problem.Schema.Ref)
+	assert.NotNil(t, responses.Default().Value.Content.Get("application/json"), "Status stays the default")
+
+	assert.Nil(t, responses.Status(200).Value.Content.Get(problemContentType))
+}
+
 // ============================================================================
 // Integration Tests
 // ============================================================================
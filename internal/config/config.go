@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/spf13/viper"
@@ -9,12 +10,85 @@ import (
 
 // Config holds the application configuration.
 type Config struct {
+	LogLevel   string           `mapstructure:"log_level"`
 	Server     ServerConfig     `mapstructure:"server"`
 	ClickHouse ClickHouseConfig `mapstructure:"clickhouse"`
 	Proto      ProtoConfig      `mapstructure:"proto"`
 	API        APIConfig        `mapstructure:"api"`
 	Telemetry  TelemetryConfig  `mapstructure:"telemetry"`
 	Headers    HeadersConfig    `mapstructure:"headers"`
+	CORS       CORSConfig       `mapstructure:"cors"`
+
+	// Per-table overrides of the generated API, by table name. Applied by openapi-preprocess and
+	// generate-implementation, so changes need a `make generate`.
+	Tables map[string]TableConfig `mapstructure:"tables"`
+
+	// Networks served from this instance, by name. Without any, the API serves clickhouse.database.
+	Networks       map[string]NetworkConfig `mapstructure:"networks"`
+	DefaultNetwork string                   `mapstructure:"default_network"` // Served on paths without a network segment
+}
+
+// NetworkConfig maps a network to the ClickHouse database holding its tables.
+type NetworkConfig struct {
+	Database string `mapstructure:"database"` // Defaults to the network name
+	DSN      string `mapstructure:"dsn"`      // Defaults to clickhouse.dsn
+}
+
+// TableConfig overrides how a table is exposed by the API.
+type TableConfig struct {
+	Alias         string   `mapstructure:"alias"`          // Public path segment used instead of the table name
+	Description   string   `mapstructure:"description"`    // Replaces the description taken from the schema
+	HiddenColumns []string `mapstructure:"hidden_columns"` // Omitted from the schema, SELECT and filters
+	OrderBy       string   `mapstructure:"order_by"`       // Used when a request sets no order_by
+	MaxPageSize   int      `mapstructure:"max_page_size"`  // Largest page_size accepted (0 means no limit)
+
+	// Deprecation: marks operations deprecated and sets the Deprecation and Sunset headers
+	Deprecated      bool   `mapstructure:"deprecated"`
+	DeprecatedSince string `mapstructure:"deprecated_since"` // RFC 3339 time for the Deprecation header
+	Sunset          string `mapstructure:"sunset"`           // RFC 3339 time for the Sunset header
+}
+
+// Network is a resolved network: its name and where its tables live.
+type Network struct {
+	Name     string
+	Database string
+	DSN      string
+}
+
+// ResolveNetworks returns the networks to serve, sorted by name, with defaults filled in, and the
+// name of the default network. Without a networks section, the single network is named after
+// clickhouse.database.
+func (c *Config) ResolveNetworks() (networks []Network, defaultNetwork string) {
+	if len(c.Networks) == 0 {
+		return []Network{{
+			Name:     c.ClickHouse.Database,
+			Database: c.ClickHouse.Database,
+			DSN:      c.ClickHouse.DSN,
+		}}, c.ClickHouse.Database
+	}
+
+	for name, n := range c.Networks {
+		network := Network{Name: name, Database: n.Database, DSN: n.DSN}
+
+		if network.Database == "" {
+			network.Database = name
+		}
+
+		if network.DSN == "" {
+			network.DSN = c.ClickHouse.DSN
+		}
+
+		networks = append(networks, network)
+	}
+
+	sort.Slice(networks, func(i, j int) bool { return networks[i].Name < networks[j].Name })
+
+	defaultNetwork = c.DefaultNetwork
+	if defaultNetwork == "" && len(networks) == 1 {
+		defaultNetwork = networks[0].Name
+	}
+
+	return networks, defaultNetwork
 }
 
 // ProtoConfig holds Protocol Buffer generation configuration.
@@ -30,6 +104,25 @@ type APIConfig struct {
 	BasePath       string   `mapstructure:"base_path"`
 	ExposePrefixes []string `mapstructure:"expose_prefixes"`
 	Exclude        []string `mapstructure:"exclude"`
+
+	// QueryStatsHeaders exposes ClickHouse read rows/bytes, memory and elapsed time in X-ClickHouse-* headers
+	QueryStatsHeaders bool `mapstructure:"query_stats_headers"`
+
+	// 64-bit integer columns encoded as JSON strings, as JavaScript numbers lose precision above 2^53.
+	// Entries are "field" or "table.field" patterns with "*" wildcards.
+	BigintToStringFields []string `mapstructure:"bigint_to_string_fields"`
+	BigintToStringAll    bool     `mapstructure:"bigint_to_string_all"` // Every Int64/UInt64 column
+
+	// Encoding is the JSON encoding of DateTime, Decimal and Enum columns
+	Encoding EncodingConfig `mapstructure:"encoding"`
+}
+
+// EncodingConfig selects how column types without a lossless JSON mapping are encoded.
+// It is applied by openapi-preprocess, so the generated types and schemas follow it.
+type EncodingConfig struct {
+	DateTime string `mapstructure:"datetime"` // epoch or rfc3339
+	Decimal  string `mapstructure:"decimal"`  // number or string
+	Enum     string `mapstructure:"enum"`     // name or value
 }
 
 // ServerConfig holds server-specific configuration.
@@ -43,6 +136,13 @@ type ServerConfig struct {
 	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
+
+	// How often the config file is checked for changes to reload (0 disables; SIGHUP always reloads)
+	ConfigWatchInterval time.Duration `mapstructure:"config_watch_interval"`
+
+	// Readiness checks (/readyz)
+	ReadinessCacheTTL     time.Duration `mapstructure:"readiness_cache_ttl"`     // How long a readiness result is reused
+	ReadinessSampleTables int           `mapstructure:"readiness_sample_tables"` // Exposed tables checked per readiness run
 }
 
 // ClickHouseConfig holds ClickHouse database configuration.
@@ -52,6 +152,13 @@ type ClickHouseConfig struct {
 	UseFinal  bool                 `mapstructure:"use_final"`
 	Discovery TableDiscoveryConfig `mapstructure:"discovery"`
 
+	// Replicas: extra hosts can be listed in the DSN (host1:9000,host2:9000) or here,
+	// in which case they replace the DSN host
+	Hosts               []string      `mapstructure:"hosts"`
+	LoadBalancing       string        `mapstructure:"load_balancing"`        // round_robin, random or in_order
+	HealthCheckInterval time.Duration `mapstructure:"health_check_interval"` // How often replicas are pinged, 0 to readmit lazily
+	MaxRetries          int           `mapstructure:"max_retries"`           // Retries of idempotent SELECTs on another replica
+
 	// Connection pool settings
 	MaxOpenConns    int           `mapstructure:"max_open_conns"`
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
@@ -65,6 +172,37 @@ type ClickHouseConfig struct {
 
 	// TLS settings
 	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
+
+	// Slow query logging
+	SlowQueries SlowQueryConfig `mapstructure:"slow_queries"`
+
+	// Fast-fail queries while ClickHouse is unhealthy
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+}
+
+// CircuitBreakerConfig configures the ClickHouse circuit breaker.
+type CircuitBreakerConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	Window           time.Duration `mapstructure:"window"`            // Period over which the error rate is measured
+	MinRequests      int           `mapstructure:"min_requests"`      // Queries needed in a window before it can trip
+	ErrorRate        float64       `mapstructure:"error_rate"`        // Failure share (0.0 to 1.0) that trips the breaker
+	LatencyThreshold time.Duration `mapstructure:"latency_threshold"` // Queries at least this slow count as failures (0 disables)
+	OpenDuration     time.Duration `mapstructure:"open_duration"`     // How long to fast-fail before probing
+	HalfOpenProbes   int           `mapstructure:"half_open_probes"`  // Successful probes needed to close again
+}
+
+// SlowQueryConfig configures logging of queries slower than a threshold.
+type SlowQueryConfig struct {
+	Threshold      time.Duration `mapstructure:"threshold"`       // Log queries taking at least this long (0 disables)
+	RedactArgs     bool          `mapstructure:"redact_args"`     // Replace every bound arg with [REDACTED]
+	RedactPatterns []string      `mapstructure:"redact_patterns"` // Regexes; args whose value matches are redacted
+	BufferSize     int           `mapstructure:"buffer_size"`     // Number of recent entries kept in memory
+	AdminEndpoint  bool          `mapstructure:"admin_endpoint"`  // Serve recent entries at /admin/slow-queries
+
+	// Optional JSONL file output, rotated by size
+	File       string `mapstructure:"file"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`
 }
 
 // TableDiscoveryConfig holds table discovery configuration for proto generation.
@@ -108,63 +246,144 @@ type HeaderPolicy struct {
 	Headers     map[string]string `mapstructure:"headers"`      // Headers to set (key: value)
 }
 
-// Load loads configuration from file and environment variables.
+// CORSConfig configures cross-origin requests. The top-level options apply to every path;
+// policies override them for paths matching a pattern, first match wins.
+type CORSConfig struct {
+	CORSOptions `mapstructure:",squash"`
+
+	Policies []CORSPolicy `mapstructure:"policies"`
+}
+
+// CORSPolicy overrides CORS options for requests matching a path pattern. Options left unset
+// are inherited from the top-level cors section.
+type CORSPolicy struct {
+	Name        string `mapstructure:"name"`         // Policy name for logging/debugging
+	PathPattern string `mapstructure:"path_pattern"` // Regex pattern to match request paths
+
+	CORSOptions `mapstructure:",squash"`
+}
+
+// CORSOptions are the CORS settings of the top-level section or of a per-path policy.
+type CORSOptions struct {
+	AllowedOrigins        []string      `mapstructure:"allowed_origins"`         // Exact origins, "*", or one wildcard (https://*.example.com)
+	AllowedOriginPatterns []string      `mapstructure:"allowed_origin_patterns"` // Regexes matched against the Origin header
+	AllowedMethods        []string      `mapstructure:"allowed_methods"`
+	AllowedHeaders        []string      `mapstructure:"allowed_headers"` // Request headers clients may send ("*" for any)
+	ExposedHeaders        []string      `mapstructure:"exposed_headers"` // Response headers browsers let clients read
+	AllowCredentials      *bool         `mapstructure:"allow_credentials"`
+	MaxAge                time.Duration `mapstructure:"max_age"` // How long preflight results may be cached
+}
+
+// Load loads configuration from file and environment variables (see bindEnv). Unknown keys and invalid values
+// are rejected.
 func Load(configFile string) (*Config, error) {
-	viper.SetConfigFile(configFile)
-	viper.SetEnvPrefix("XATU_CBT_API")
-	viper.AutomaticEnv()
+	v := viper.New()
+	v.SetConfigFile(configFile)
+
+	if err := bindEnv(v); err != nil {
+		return nil, err
+	}
+
+	v.SetDefault("log_level", "info")
 
 	// Server defaults
-	viper.SetDefault("server.port", 8080)
-	viper.SetDefault("server.metrics_port", 9090)
-	viper.SetDefault("server.host", "0.0.0.0")
-	viper.SetDefault("server.read_header_timeout", 10*time.Second)
-	viper.SetDefault("server.read_timeout", 30*time.Second)
-	viper.SetDefault("server.write_timeout", 30*time.Second)
-	viper.SetDefault("server.idle_timeout", 120*time.Second)
+	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.metrics_port", 9090)
+	v.SetDefault("server.host", "0.0.0.0")
+	v.SetDefault("server.read_header_timeout", 10*time.Second)
+	v.SetDefault("server.read_timeout", 30*time.Second)
+	v.SetDefault("server.write_timeout", 30*time.Second)
+	v.SetDefault("server.idle_timeout", 120*time.Second)
+	v.SetDefault("server.config_watch_interval", 5*time.Second)
+	v.SetDefault("server.readiness_cache_ttl", 2*time.Second)
+	v.SetDefault("server.readiness_sample_tables", 3)
 
 	// ClickHouse defaults
-	viper.SetDefault("clickhouse.max_open_conns", 10)
-	viper.SetDefault("clickhouse.max_idle_conns", 5)
-	viper.SetDefault("clickhouse.conn_max_lifetime", 60*time.Second)
-	viper.SetDefault("clickhouse.dial_timeout", 10*time.Second)
-	viper.SetDefault("clickhouse.read_timeout", 30*time.Second)
-	viper.SetDefault("clickhouse.write_timeout", 30*time.Second)
-	viper.SetDefault("clickhouse.max_execution_time", 60)
-	viper.SetDefault("clickhouse.use_final", false)
-	viper.SetDefault("clickhouse.insecure_skip_verify", false)
+	v.SetDefault("clickhouse.max_open_conns", 10)
+	v.SetDefault("clickhouse.max_idle_conns", 5)
+	v.SetDefault("clickhouse.conn_max_lifetime", 60*time.Second)
+	v.SetDefault("clickhouse.dial_timeout", 10*time.Second)
+	v.SetDefault("clickhouse.read_timeout", 30*time.Second)
+	v.SetDefault("clickhouse.write_timeout", 30*time.Second)
+	v.SetDefault("clickhouse.max_execution_time", 60)
+	v.SetDefault("clickhouse.use_final", false)
+	v.SetDefault("clickhouse.insecure_skip_verify", false)
+	v.SetDefault("clickhouse.load_balancing", "round_robin")
+	v.SetDefault("clickhouse.health_check_interval", 10*time.Second)
+	v.SetDefault("clickhouse.max_retries", 1)
+	v.SetDefault("clickhouse.circuit_breaker.enabled", false)
+	v.SetDefault("clickhouse.circuit_breaker.window", 10*time.Second)
+	v.SetDefault("clickhouse.circuit_breaker.min_requests", 20)
+	v.SetDefault("clickhouse.circuit_breaker.error_rate", 0.5)
+	v.SetDefault("clickhouse.circuit_breaker.latency_threshold", 0)
+	v.SetDefault("clickhouse.circuit_breaker.open_duration", 30*time.Second)
+	v.SetDefault("clickhouse.circuit_breaker.half_open_probes", 3)
+	v.SetDefault("clickhouse.slow_queries.threshold", 0)
+	v.SetDefault("clickhouse.slow_queries.buffer_size", 100)
+	v.SetDefault("clickhouse.slow_queries.admin_endpoint", false)
+	v.SetDefault("clickhouse.slow_queries.max_size_mb", 100)
+	v.SetDefault("clickhouse.slow_queries.max_backups", 3)
 
 	// Proto defaults
-	viper.SetDefault("proto.output_dir", "./pkg/proto/clickhouse")
-	viper.SetDefault("proto.package", "cbt.v1")
-	viper.SetDefault("proto.go_package", "github.com/ethpandaops/cbt-api/pkg/proto/clickhouse")
-	viper.SetDefault("proto.include_comments", true)
+	v.SetDefault("proto.output_dir", "./pkg/proto/clickhouse")
+	v.SetDefault("proto.package", "cbt.v1")
+	v.SetDefault("proto.go_package", "github.com/ethpandaops/cbt-api/pkg/proto/clickhouse")
+	v.SetDefault("proto.include_comments", true)
 
 	// API defaults
-	viper.SetDefault("api.base_path", "/api/v1")
-	viper.SetDefault("api.expose_prefixes", []string{"fct"})
+	v.SetDefault("api.base_path", "/api/v1")
+	v.SetDefault("api.expose_prefixes", []string{"fct"})
+	v.SetDefault("api.query_stats_headers", false)
+	v.SetDefault("api.bigint_to_string_all", false)
+	v.SetDefault("api.encoding.datetime", "epoch")
+	v.SetDefault("api.encoding.decimal", "number")
+	v.SetDefault("api.encoding.enum", "name")
+
+	// CORS defaults
+	v.SetDefault("cors.allowed_origins", []string{"*"})
+	v.SetDefault("cors.allowed_methods", []string{"GET", "HEAD", "OPTIONS"})
+	v.SetDefault("cors.allowed_headers", []string{"*"})
+	v.SetDefault("cors.exposed_headers", []string{
+		"X-Request-ID",
+		"Retry-After",
+		"X-Query-Projection",
+		"Deprecation",
+		"Sunset",
+		"X-ClickHouse-Query-Id",
+		"X-ClickHouse-Read-Rows",
+		"X-ClickHouse-Read-Bytes",
+		"X-ClickHouse-Memory-Usage",
+		"X-ClickHouse-Elapsed-Ms",
+	})
+	v.SetDefault("cors.allow_credentials", false)
+	v.SetDefault("cors.max_age", 24*time.Hour)
 
 	// Telemetry defaults
-	viper.SetDefault("telemetry.enabled", false)
-	viper.SetDefault("telemetry.service_name", "cbt-api")
-	viper.SetDefault("telemetry.service_version", "unknown")
-	viper.SetDefault("telemetry.environment", "unknown")
-	viper.SetDefault("telemetry.sample_rate", 0.1)
-	viper.SetDefault("telemetry.always_sample_errors", true)
-	viper.SetDefault("telemetry.export_timeout", 10*time.Second)
-	viper.SetDefault("telemetry.export_batch_size", 512)
-	viper.SetDefault("telemetry.insecure", false)
-
-	if err := viper.ReadInConfig(); err != nil {
+	v.SetDefault("telemetry.enabled", false)
+	v.SetDefault("telemetry.service_name", "cbt-api")
+	v.SetDefault("telemetry.service_version", "unknown")
+	v.SetDefault("telemetry.environment", "unknown")
+	v.SetDefault("telemetry.sample_rate", 0.1)
+	v.SetDefault("telemetry.always_sample_errors", true)
+	v.SetDefault("telemetry.export_timeout", 10*time.Second)
+	v.SetDefault("telemetry.export_batch_size", 512)
+	v.SetDefault("telemetry.insecure", false)
+
+	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, err
 		}
 	}
 
+	// Unknown keys are rejected so typos are not silently ignored
 	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := v.UnmarshalExact(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
 	return &cfg, nil
 }
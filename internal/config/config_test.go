@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveNetworks(t *testing.T) {
+	t.Run("without networks", func(t *testing.T) {
+		cfg := &Config{ClickHouse: ClickHouseConfig{DSN: "clickhouse://main:9000", Database: "mainnet"}}
+
+		networks, defaultNetwork := cfg.ResolveNetworks()
+
+		assert.Equal(t, []Network{{Name: "mainnet", Database: "mainnet", DSN: "clickhouse://main:9000"}}, networks)
+		assert.Equal(t, "mainnet", defaultNetwork)
+	})
+
+	t.Run("with networks", func(t *testing.T) {
+		cfg := &Config{
+			ClickHouse: ClickHouseConfig{DSN: "clickhouse://main:9000", Database: "mainnet"},
+			Networks: map[string]NetworkConfig{
+				"mainnet": {},
+				"holesky": {Database: "holesky_v2", DSN: "clickhouse://testnets:9000"},
+			},
+			DefaultNetwork: "mainnet",
+		}
+
+		networks, defaultNetwork := cfg.ResolveNetworks()
+
+		assert.Equal(t, []Network{
+			{Name: "holesky", Database: "holesky_v2", DSN: "clickhouse://testnets:9000"},
+			{Name: "mainnet", Database: "mainnet", DSN: "clickhouse://main:9000"},
+		}, networks)
+		assert.Equal(t, "mainnet", defaultNetwork)
+	})
+
+	t.Run("single network is the default", func(t *testing.T) {
+		cfg := &Config{Networks: map[string]NetworkConfig{"sepolia": {}}}
+
+		_, defaultNetwork := cfg.ResolveNetworks()
+
+		assert.Equal(t, "sepolia", defaultNetwork)
+	})
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Hash returns a SHA-256 of the resolved configuration, including defaults and environment
+// overrides, so deployments can tell which configuration a process is running.
+func (c *Config) Hash() string {
+	values := c.flatten()
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	h := sha256.New()
+
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s=%s\n", key, values[key])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Diff returns the sorted config keys (e.g. "server.port" or "headers.policies") whose values
+// differ between old and updated.
+func Diff(old, updated *Config) []string {
+	oldValues, newValues := old.flatten(), updated.flatten()
+
+	var changed []string
+
+	for key, value := range newValues {
+		if oldValue, ok := oldValues[key]; !ok || oldValue != value {
+			changed = append(changed, key)
+		}
+	}
+
+	for key := range oldValues {
+		if _, ok := newValues[key]; !ok {
+			changed = append(changed, key)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+// flatten maps every leaf config key, named by its mapstructure path, to a printable value.
+// Slices and maps are compared as a whole under their own key.
+func (c *Config) flatten() map[string]string {
+	values := make(map[string]string)
+	flattenValue(reflect.ValueOf(*c), "", values)
+
+	return values
+}
+
+// flattenValue adds the leaves of v under prefix to values.
+func flattenValue(v reflect.Value, prefix string, values map[string]string) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			values[prefix] = "null"
+
+			return
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		// JSON rather than %v so pointers inside slices print their values and map keys are sorted
+		encoded, err := json.Marshal(v.Interface())
+		if err != nil {
+			encoded = []byte(fmt.Sprintf("%v", v.Interface()))
+		}
+
+		values[prefix] = string(encoded)
+
+		return
+	}
+
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		if opts == "squash" {
+			key = prefix
+		}
+
+		flattenValue(v.Field(i), key, values)
+	}
+}
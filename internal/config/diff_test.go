@@ -0,0 +1,48 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	allow := true
+
+	old := &Config{
+		LogLevel: "info",
+		Server:   ServerConfig{Port: 8080},
+		Headers: HeadersConfig{Policies: []HeaderPolicy{
+			{Name: "api", PathPattern: "^/api/", Headers: map[string]string{"Cache-Control": "no-cache"}},
+		}},
+	}
+
+	updated := &Config{
+		LogLevel: "debug",
+		Server:   ServerConfig{Port: 8080},
+		Headers: HeadersConfig{Policies: []HeaderPolicy{
+			{Name: "api", PathPattern: "^/api/", Headers: map[string]string{"Cache-Control": "max-age=60"}},
+		}},
+		CORS: CORSConfig{CORSOptions: CORSOptions{AllowCredentials: &allow, MaxAge: time.Hour}},
+	}
+
+	assert.Empty(t, Diff(old, old))
+	assert.Equal(t, []string{
+		"cors.allow_credentials",
+		"cors.max_age",
+		"headers.policies",
+		"log_level",
+	}, Diff(old, updated))
+}
+
+func TestHash(t *testing.T) {
+	a := &Config{LogLevel: "info", Server: ServerConfig{Port: 8080}}
+	b := &Config{LogLevel: "info", Server: ServerConfig{Port: 8080}}
+
+	assert.Len(t, a.Hash(), 64)
+	assert.Equal(t, a.Hash(), b.Hash())
+
+	b.Server.Port = 8081
+	assert.NotEqual(t, a.Hash(), b.Hash())
+}
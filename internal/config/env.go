@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// envPrefix prefixes environment variable overrides, e.g. CBT_API_CLICKHOUSE_DSN.
+	envPrefix = "CBT_API"
+
+	// legacyEnvPrefix is the deprecated prefix still accepted as an alias of envPrefix.
+	legacyEnvPrefix = "XATU_CBT_API"
+
+	// fileEnvSuffix marks a variable holding the path of a file to read the value from.
+	fileEnvSuffix = "_FILE"
+)
+
+// bindEnv makes every scalar and list key overridable from the environment, either directly
+// (CBT_API_CLICKHOUSE_DSN) or from a file (CBT_API_CLICKHOUSE_DSN_FILE). Lists are comma
+// separated. Lists of objects and maps can only be set in the config file.
+func bindEnv(v *viper.Viper) error {
+	v.SetEnvPrefix(envPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	for _, key := range envKeys(reflect.TypeFor[Config](), "") {
+		names := []string{envName(envPrefix, key), envName(legacyEnvPrefix, key)}
+
+		if err := v.BindEnv(append([]string{key}, names...)...); err != nil {
+			return fmt.Errorf("failed to bind %s: %w", key, err)
+		}
+
+		for _, name := range names {
+			path, ok := os.LookupEnv(name + fileEnvSuffix)
+			if !ok {
+				continue
+			}
+
+			if _, set := os.LookupEnv(name); set {
+				return fmt.Errorf("both %s and %s%s are set", name, name, fileEnvSuffix)
+			}
+
+			data, err := os.ReadFile(path) //nolint:gosec // path comes from the environment
+			if err != nil {
+				return fmt.Errorf("failed to read %s%s: %w", name, fileEnvSuffix, err)
+			}
+
+			v.Set(key, strings.TrimRight(string(data), "\r\n"))
+
+			break
+		}
+	}
+
+	return nil
+}
+
+// DeprecatedEnv returns the set environment variables that use the deprecated XATU_CBT_API
+// prefix, so callers can warn about them.
+func DeprecatedEnv() []string {
+	var names []string
+
+	for _, env := range os.Environ() {
+		name, _, _ := strings.Cut(env, "=")
+		if strings.HasPrefix(name, legacyEnvPrefix+"_") {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// envName returns the environment variable for a config key, e.g. CBT_API_SERVER_PORT.
+func envName(prefix, key string) string {
+	return prefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// envKeys returns the config keys under prefix that can be set from a single string.
+func envKeys(t reflect.Type, prefix string) []string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == reflect.TypeFor[time.Duration]():
+		return []string{prefix}
+	case t.Kind() == reflect.Struct:
+		var keys []string
+
+		for i := range t.NumField() {
+			field := t.Field(i)
+
+			name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+
+			key := name
+			if prefix != "" {
+				key = prefix + "." + name
+			}
+
+			if opts == "squash" {
+				key = prefix
+			}
+
+			keys = append(keys, envKeys(field.Type, key)...)
+		}
+
+		return keys
+	case t.Kind() == reflect.Map:
+		return nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Struct:
+		return nil
+	default:
+		return []string{prefix}
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoad_EnvOverrides(t *testing.T) {
+	path := writeConfig(t, "server:\n  port: 8080\n")
+
+	t.Setenv("CBT_API_SERVER_PORT", "8181")
+	t.Setenv("CBT_API_CLICKHOUSE_DSN", "clickhouse://default:pw@ch:9000") // No default or file value
+	t.Setenv("CBT_API_CLICKHOUSE_SLOW_QUERIES_THRESHOLD", "2s")           // Nested, duration
+	t.Setenv("CBT_API_API_EXPOSE_PREFIXES", "fct,dim")                    // List
+	t.Setenv("XATU_CBT_API_CLICKHOUSE_DATABASE", "mainnet")               // Deprecated prefix
+	t.Setenv("XATU_CBT_API_LOG_LEVEL", "warn")                            // Overridden by the new prefix
+	t.Setenv("CBT_API_LOG_LEVEL", "debug")
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, 8181, cfg.Server.Port)
+	assert.Equal(t, "clickhouse://default:pw@ch:9000", cfg.ClickHouse.DSN)
+	assert.Equal(t, 2*time.Second, cfg.ClickHouse.SlowQueries.Threshold)
+	assert.Equal(t, []string{"fct", "dim"}, cfg.API.ExposePrefixes)
+	assert.Equal(t, "mainnet", cfg.ClickHouse.Database)
+	assert.Equal(t, "debug", cfg.LogLevel)
+
+	assert.Equal(t, []string{"XATU_CBT_API_CLICKHOUSE_DATABASE", "XATU_CBT_API_LOG_LEVEL"}, DeprecatedEnv())
+}
+
+func TestLoad_EnvFile(t *testing.T) {
+	path := writeConfig(t, "")
+
+	secret := filepath.Join(t.TempDir(), "dsn")
+	require.NoError(t, os.WriteFile(secret, []byte("clickhouse://default:secret@ch:9000\n"), 0o600))
+
+	t.Setenv("CBT_API_CLICKHOUSE_DSN_FILE", secret)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "clickhouse://default:secret@ch:9000", cfg.ClickHouse.DSN)
+
+	t.Run("conflicts with the variable", func(t *testing.T) {
+		t.Setenv("CBT_API_CLICKHOUSE_DSN", "clickhouse://ch:9000")
+
+		_, err := Load(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "both CBT_API_CLICKHOUSE_DSN and CBT_API_CLICKHOUSE_DSN_FILE are set")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Setenv("CBT_API_CLICKHOUSE_DSN_FILE", filepath.Join(t.TempDir(), "missing"))
+
+		_, err := Load(path)
+		require.Error(t, err)
+	})
+}
+
+func TestEnvKeys(t *testing.T) {
+	keys := envKeys(reflect.TypeFor[Config](), "")
+
+	assert.Contains(t, keys, "clickhouse.dsn")
+	assert.Contains(t, keys, "clickhouse.circuit_breaker.error_rate")
+	assert.Contains(t, keys, "cors.allowed_origins") // Squashed options
+	assert.NotContains(t, keys, "headers.policies")  // List of objects
+	assert.NotContains(t, keys, "telemetry.headers") // Map
+}
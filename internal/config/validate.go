@@ -0,0 +1,316 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Validate checks value ranges, regexes and glob patterns, returning every problem found.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+		v.addf("log_level: %v", err)
+	}
+
+	v.port("server.port", c.Server.Port)
+	v.port("server.metrics_port", c.Server.MetricsPort)
+
+	if c.Server.Port == c.Server.MetricsPort {
+		v.addf("server.metrics_port: must differ from server.port (%d)", c.Server.Port)
+	}
+
+	v.positive("server.read_header_timeout", c.Server.ReadHeaderTimeout)
+	v.positive("server.read_timeout", c.Server.ReadTimeout)
+	v.positive("server.write_timeout", c.Server.WriteTimeout)
+	v.positive("server.idle_timeout", c.Server.IdleTimeout)
+	v.nonNegative("server.config_watch_interval", c.Server.ConfigWatchInterval)
+	v.nonNegative("server.readiness_cache_ttl", c.Server.ReadinessCacheTTL)
+	v.min("server.readiness_sample_tables", c.Server.ReadinessSampleTables, 0)
+
+	c.ClickHouse.validate(v)
+
+	v.globs("api.exclude", c.API.Exclude)
+	v.globs("api.bigint_to_string_fields", c.API.BigintToStringFields)
+
+	for i, pattern := range c.API.BigintToStringFields {
+		if strings.Count(pattern, ".") > 1 {
+			v.addf("api.bigint_to_string_fields[%d]: must be \"field\" or \"table.field\", got %q", i, pattern)
+		}
+	}
+
+	c.API.Encoding.validate(v)
+
+	if c.API.BasePath != "" && !strings.HasPrefix(c.API.BasePath, "/") {
+		v.addf("api.base_path: must start with \"/\", got %q", c.API.BasePath)
+	}
+
+	c.Telemetry.validate(v)
+
+	for i, p := range c.Headers.Policies {
+		v.regex(fmt.Sprintf("headers.policies[%d].path_pattern", i), p.PathPattern)
+	}
+
+	c.CORS.validate(v)
+
+	c.validateNetworks(v)
+	c.validateTables(v)
+
+	return errors.Join(v.errs...)
+}
+
+// pathSegmentPattern restricts network names and table aliases to values usable as a path segment.
+var pathSegmentPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func (c *Config) validateNetworks(v *validator) {
+	for _, name := range slices.Sorted(maps.Keys(c.Networks)) {
+		if !pathSegmentPattern.MatchString(name) {
+			v.addf("networks.%s: name must match %s", name, pathSegmentPattern)
+		}
+	}
+
+	if len(c.Networks) == 0 {
+		if c.DefaultNetwork != "" {
+			v.addf("default_network: requires a networks section")
+		}
+
+		return
+	}
+
+	networks, defaultNetwork := c.ResolveNetworks()
+
+	if defaultNetwork == "" {
+		v.addf("default_network: required when more than one network is configured")
+
+		return
+	}
+
+	if _, ok := c.Networks[defaultNetwork]; !ok {
+		v.addf("default_network: %q is not a configured network", defaultNetwork)
+
+		return
+	}
+
+	// Readiness checks ping clickhouse.dsn for the default network
+	for _, n := range networks {
+		if n.Name == defaultNetwork && n.DSN != c.ClickHouse.DSN {
+			v.addf("networks.%s.dsn: the default network must use clickhouse.dsn", n.Name)
+		}
+	}
+}
+
+func (c *Config) validateTables(v *validator) {
+	paths := make(map[string]string, len(c.Tables))
+	for name, t := range c.Tables {
+		if t.Alias == "" {
+			paths[name] = name
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Tables)) {
+		t := c.Tables[name]
+		key := "tables." + name
+
+		if t.Alias != "" {
+			switch {
+			case !pathSegmentPattern.MatchString(t.Alias):
+				v.addf("%s.alias: must match %s, got %q", key, pathSegmentPattern, t.Alias)
+			case paths[t.Alias] != "":
+				v.addf("%s.alias: %q is already the path of %s", key, t.Alias, paths[t.Alias])
+			default:
+				paths[t.Alias] = name
+			}
+
+			// The networks middleware would take the alias for a network segment
+			if _, ok := c.Networks[t.Alias]; ok {
+				v.addf("%s.alias: %q is a network name", key, t.Alias)
+			}
+		}
+
+		for i, column := range t.HiddenColumns {
+			if column == "" {
+				v.addf("%s.hidden_columns[%d]: must not be empty", key, i)
+			}
+		}
+
+		v.min(key+".max_page_size", t.MaxPageSize, 0)
+		v.timestamp(key+".deprecated_since", t.DeprecatedSince)
+		v.timestamp(key+".sunset", t.Sunset)
+
+		if t.DeprecatedSince != "" && !t.Deprecated {
+			v.addf("%s.deprecated_since: requires deprecated: true", key)
+		}
+	}
+}
+
+func (c *ClickHouseConfig) validate(v *validator) {
+	if !slices.Contains([]string{"", "round_robin", "random", "in_order"}, c.LoadBalancing) {
+		v.addf("clickhouse.load_balancing: must be round_robin, random or in_order, got %q", c.LoadBalancing)
+	}
+
+	v.nonNegative("clickhouse.health_check_interval", c.HealthCheckInterval)
+	v.min("clickhouse.max_retries", c.MaxRetries, 0)
+	v.min("clickhouse.max_open_conns", c.MaxOpenConns, 0)
+	v.min("clickhouse.max_idle_conns", c.MaxIdleConns, 0)
+	v.nonNegative("clickhouse.conn_max_lifetime", c.ConnMaxLifetime)
+	v.positive("clickhouse.dial_timeout", c.DialTimeout)
+	v.positive("clickhouse.read_timeout", c.ReadTimeout)
+	v.positive("clickhouse.write_timeout", c.WriteTimeout)
+	v.min("clickhouse.max_execution_time", c.MaxExecutionTime, 0)
+
+	v.globs("clickhouse.discovery.exclude", c.Discovery.Exclude)
+
+	sq := c.SlowQueries
+	v.nonNegative("clickhouse.slow_queries.threshold", sq.Threshold)
+	v.min("clickhouse.slow_queries.buffer_size", sq.BufferSize, 0)
+	v.min("clickhouse.slow_queries.max_size_mb", sq.MaxSizeMB, 0)
+	v.min("clickhouse.slow_queries.max_backups", sq.MaxBackups, 0)
+
+	for i, pattern := range sq.RedactPatterns {
+		v.regex(fmt.Sprintf("clickhouse.slow_queries.redact_patterns[%d]", i), pattern)
+	}
+
+	cb := c.CircuitBreaker
+	v.fraction("clickhouse.circuit_breaker.error_rate", cb.ErrorRate)
+	v.nonNegative("clickhouse.circuit_breaker.latency_threshold", cb.LatencyThreshold)
+	v.min("clickhouse.circuit_breaker.min_requests", cb.MinRequests, 0)
+	v.min("clickhouse.circuit_breaker.half_open_probes", cb.HalfOpenProbes, 0)
+
+	if cb.Enabled {
+		v.positive("clickhouse.circuit_breaker.window", cb.Window)
+		v.positive("clickhouse.circuit_breaker.open_duration", cb.OpenDuration)
+	}
+}
+
+func (c *EncodingConfig) validate(v *validator) {
+	if !slices.Contains([]string{"", "epoch", "rfc3339"}, c.DateTime) {
+		v.addf("api.encoding.datetime: must be epoch or rfc3339, got %q", c.DateTime)
+	}
+
+	if !slices.Contains([]string{"", "number", "string"}, c.Decimal) {
+		v.addf("api.encoding.decimal: must be number or string, got %q", c.Decimal)
+	}
+
+	if !slices.Contains([]string{"", "name", "value"}, c.Enum) {
+		v.addf("api.encoding.enum: must be name or value, got %q", c.Enum)
+	}
+}
+
+func (c *TelemetryConfig) validate(v *validator) {
+	v.fraction("telemetry.sample_rate", c.SampleRate)
+
+	if !c.Enabled {
+		return
+	}
+
+	if c.Endpoint == "" {
+		v.addf("telemetry.endpoint: required when telemetry is enabled")
+	}
+
+	v.positive("telemetry.export_timeout", c.ExportTimeout)
+	v.min("telemetry.export_batch_size", c.ExportBatchSize, 1)
+}
+
+func (c *CORSConfig) validate(v *validator) {
+	c.CORSOptions.validate(v, "cors")
+
+	for i, p := range c.Policies {
+		key := fmt.Sprintf("cors.policies[%d]", i)
+
+		v.regex(key+".path_pattern", p.PathPattern)
+		p.CORSOptions.validate(v, key)
+	}
+}
+
+func (o *CORSOptions) validate(v *validator, key string) {
+	for i, pattern := range o.AllowedOriginPatterns {
+		v.regex(fmt.Sprintf("%s.allowed_origin_patterns[%d]", key, i), pattern)
+	}
+
+	if o.AllowCredentials != nil && *o.AllowCredentials && slices.Contains(o.AllowedOrigins, "*") {
+		v.addf("%s.allow_credentials: cannot be used with allowed_origins \"*\"", key)
+	}
+
+	v.nonNegative(key+".max_age", o.MaxAge)
+}
+
+// validator collects validation errors.
+type validator struct {
+	errs []error
+}
+
+func (v *validator) addf(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+func (v *validator) port(key string, port int) {
+	if port < 1 || port > 65535 {
+		v.addf("%s: must be between 1 and 65535, got %d", key, port)
+	}
+}
+
+func (v *validator) positive(key string, d time.Duration) {
+	if d <= 0 {
+		v.addf("%s: must be positive, got %s", key, d)
+	}
+}
+
+func (v *validator) nonNegative(key string, d time.Duration) {
+	if d < 0 {
+		v.addf("%s: must not be negative, got %s", key, d)
+	}
+}
+
+func (v *validator) min(key string, n, minimum int) {
+	if n < minimum {
+		v.addf("%s: must be at least %d, got %d", key, minimum, n)
+	}
+}
+
+func (v *validator) fraction(key string, f float64) {
+	if f < 0 || f > 1 {
+		v.addf("%s: must be between 0 and 1, got %g", key, f)
+	}
+}
+
+func (v *validator) regex(key, pattern string) {
+	if pattern == "" {
+		v.addf("%s: must not be empty", key)
+
+		return
+	}
+
+	if _, err := regexp.Compile(pattern); err != nil {
+		v.addf("%s: invalid regex: %v", key, err)
+	}
+}
+
+// timestamp checks an optional RFC 3339 time.
+func (v *validator) timestamp(key, value string) {
+	if value == "" {
+		return
+	}
+
+	if _, err := time.Parse(time.RFC3339, value); err != nil {
+		v.addf("%s: must be an RFC 3339 time, got %q", key, value)
+	}
+}
+
+// globs checks table exclude patterns, which only support "*" wildcards.
+func (v *validator) globs(key string, patterns []string) {
+	for i, pattern := range patterns {
+		switch {
+		case pattern == "":
+			v.addf("%s[%d]: must not be empty", key, i)
+		case strings.ContainsAny(pattern, "?[]"):
+			v.addf("%s[%d]: only \"*\" wildcards are supported, got %q", key, i, pattern)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() *Config {
+	return &Config{
+		LogLevel: "info",
+		Server: ServerConfig{
+			Port:              8080,
+			MetricsPort:       9090,
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+		},
+		ClickHouse: ClickHouseConfig{
+			DialTimeout:  10 * time.Second,
+			ReadTimeout:  30 * time.Second,
+			WriteTimeout: 30 * time.Second,
+		},
+		Telemetry: TelemetryConfig{SampleRate: 0.1},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	allow := true
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr []string
+	}{
+		{
+			name:   "valid config",
+			modify: func(_ *Config) {},
+		},
+		{
+			name:    "invalid log level",
+			modify:  func(cfg *Config) { cfg.LogLevel = "loud" },
+			wantErr: []string{"log_level"},
+		},
+		{
+			name: "ports out of range",
+			modify: func(cfg *Config) {
+				cfg.Server.Port = 0
+				cfg.Server.MetricsPort = 70000
+			},
+			wantErr: []string{"server.port: must be between 1 and 65535", "server.metrics_port: must be between 1 and 65535"},
+		},
+		{
+			name:    "same port twice",
+			modify:  func(cfg *Config) { cfg.Server.MetricsPort = 8080 },
+			wantErr: []string{"server.metrics_port: must differ from server.port"},
+		},
+		{
+			name:    "non-positive timeout",
+			modify:  func(cfg *Config) { cfg.ClickHouse.DialTimeout = 0 },
+			wantErr: []string{"clickhouse.dial_timeout: must be positive"},
+		},
+		{
+			name:    "sample rate above 1",
+			modify:  func(cfg *Config) { cfg.Telemetry.SampleRate = 1.5 },
+			wantErr: []string{"telemetry.sample_rate: must be between 0 and 1"},
+		},
+		{
+			name:    "enabled telemetry without endpoint",
+			modify:  func(cfg *Config) { cfg.Telemetry.Enabled = true },
+			wantErr: []string{"telemetry.endpoint", "telemetry.export_timeout", "telemetry.export_batch_size"},
+		},
+		{
+			name:    "unknown load balancing strategy",
+			modify:  func(cfg *Config) { cfg.ClickHouse.LoadBalancing = "fastest" },
+			wantErr: []string{"clickhouse.load_balancing"},
+		},
+		{
+			name: "invalid header policy regex",
+			modify: func(cfg *Config) {
+				cfg.Headers.Policies = []HeaderPolicy{{Name: "ok", PathPattern: ".*"}, {Name: "bad", PathPattern: "[invalid"}}
+			},
+			wantErr: []string{"headers.policies[1].path_pattern: invalid regex"},
+		},
+		{
+			name:    "unsupported glob",
+			modify:  func(cfg *Config) { cfg.API.Exclude = []string{"*_local", "fct_[ab]"} },
+			wantErr: []string{`api.exclude[1]: only "*" wildcards are supported`},
+		},
+		{
+			name: "unknown encodings",
+			modify: func(cfg *Config) {
+				cfg.API.Encoding = EncodingConfig{DateTime: "iso", Decimal: "float", Enum: "label"}
+			},
+			wantErr: []string{
+				`api.encoding.datetime: must be epoch or rfc3339, got "iso"`,
+				`api.encoding.decimal: must be number or string, got "float"`,
+				`api.encoding.enum: must be name or value, got "label"`,
+			},
+		},
+		{
+			name: "bigint field with two dots",
+			modify: func(cfg *Config) {
+				cfg.API.BigintToStringFields = []string{"block_number", "fct_*.execution_payload_value", "a.b.c"}
+			},
+			wantErr: []string{`api.bigint_to_string_fields[2]: must be "field" or "table.field", got "a.b.c"`},
+		},
+		{
+			name: "cors credentials with any origin",
+			modify: func(cfg *Config) {
+				cfg.CORS.Policies = []CORSPolicy{{
+					Name:        "private",
+					PathPattern: "^/private/",
+					CORSOptions: CORSOptions{AllowedOrigins: []string{"*"}, AllowCredentials: &allow},
+				}}
+			},
+			wantErr: []string{"cors.policies[0].allow_credentials"},
+		},
+		{
+			name: "invalid table overrides",
+			modify: func(cfg *Config) {
+				cfg.Networks = map[string]NetworkConfig{"mainnet": {}}
+				cfg.Tables = map[string]TableConfig{
+					"fct_block":       {Alias: "blocks", MaxPageSize: -1, HiddenColumns: []string{""}},
+					"fct_attestation": {Alias: "blocks", DeprecatedSince: "2025-01-01", Sunset: "tomorrow"},
+					"fct_head":        {Alias: "mainnet"},
+				}
+			},
+			wantErr: []string{
+				`tables.fct_block.alias: "blocks" is already the path of fct_attestation`,
+				"tables.fct_block.max_page_size: must be at least 0",
+				"tables.fct_block.hidden_columns[0]: must not be empty",
+				"tables.fct_attestation.deprecated_since: must be an RFC 3339 time",
+				"tables.fct_attestation.deprecated_since: requires deprecated: true",
+				"tables.fct_attestation.sunset: must be an RFC 3339 time",
+				`tables.fct_head.alias: "mainnet" is a network name`,
+			},
+		},
+		{
+			name: "several networks without a default",
+			modify: func(cfg *Config) {
+				cfg.Networks = map[string]NetworkConfig{"mainnet": {}, "holesky": {}}
+			},
+			wantErr: []string{"default_network: required"},
+		},
+		{
+			name: "unknown default network and invalid name",
+			modify: func(cfg *Config) {
+				cfg.Networks = map[string]NetworkConfig{"mainnet": {}, "Holesky": {}}
+				cfg.DefaultNetwork = "sepolia"
+			},
+			wantErr: []string{"networks.Holesky: name must match", `default_network: "sepolia" is not a configured network`},
+		},
+		{
+			name: "default network on another cluster",
+			modify: func(cfg *Config) {
+				cfg.Networks = map[string]NetworkConfig{"mainnet": {DSN: "clickhouse://other:9000"}}
+			},
+			wantErr: []string{"networks.mainnet.dsn: the default network must use clickhouse.dsn"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+
+			for _, want := range tt.wantErr {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestLoad_RejectsUnknownKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("api:\n  enable: true\n"), 0o600))
+
+	_, err := Load(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "enable")
+}
+
+func TestLoad_ExampleConfig(t *testing.T) {
+	_, err := Load(filepath.Join("..", "..", "config.example.yaml"))
+	require.NoError(t, err)
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"time"
+)
+
+// WatchFile calls onChange whenever the content of path changes, checking every interval, until
+// ctx is done. Polling the content rather than relying on file events also catches the symlink
+// swaps used by Kubernetes ConfigMap volumes.
+func WatchFile(ctx context.Context, path string, interval time.Duration, onChange func()) {
+	if interval <= 0 {
+		return
+	}
+
+	last, _ := fileHash(path)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current, err := fileHash(path)
+			if err != nil || current == last {
+				continue
+			}
+
+			last = current
+
+			onChange()
+		}
+	}
+}
+
+// fileHash returns the SHA-256 of a file's content.
+func fileHash(path string) ([sha256.Size]byte, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from the command line
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	return sha256.Sum256(data), nil
+}
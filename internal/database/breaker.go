@@ -0,0 +1,405 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
+)
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState int
+
+// Circuit breaker states. The numeric values are exported by the state gauge.
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+// String returns the state name.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half_open"
+	case BreakerOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// ClickHouse exception codes that indicate the server is overloaded rather than the query being wrong.
+var overloadExceptionCodes = map[int32]bool{
+	159: true, // TIMEOUT_EXCEEDED
+	202: true, // TOO_MANY_SIMULTANEOUS_QUERIES
+	209: true, // SOCKET_TIMEOUT
+	241: true, // MEMORY_LIMIT_EXCEEDED
+}
+
+// ErrCircuitOpen is matched by errors returned while the circuit breaker is rejecting queries.
+var ErrCircuitOpen = errors.New("clickhouse circuit breaker is open")
+
+// CircuitOpenError is returned instead of running a query while the breaker is open.
+type CircuitOpenError struct {
+	// RetryAfter is how long until the breaker lets probe queries through.
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrCircuitOpen, e.RetryAfter.Round(time.Second))
+}
+
+// Is makes errors.Is(err, ErrCircuitOpen) match.
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+var circuitBreakerState = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "cbt_api",
+		Subsystem: "clickhouse",
+		Name:      "circuit_breaker_state",
+		Help:      "ClickHouse circuit breaker state (0 = closed, 1 = half-open, 2 = open)",
+	},
+	[]string{"network"},
+)
+
+func init() {
+	prometheus.MustRegister(circuitBreakerState)
+}
+
+// CircuitBreaker fast-fails queries while ClickHouse is unhealthy instead of letting every
+// request wait for the read timeout. It trips when, within a window, the share of failed
+// (or slower than the latency threshold) queries reaches the error rate. After the open
+// duration it half-opens and lets a few probe queries through; if they all succeed it closes,
+// otherwise it opens again.
+type CircuitBreaker struct {
+	client DatabaseClient
+	cfg    config.CircuitBreakerConfig
+	log    logrus.FieldLogger
+	now    func() time.Time
+	gauge  prometheus.Gauge
+
+	mu          sync.Mutex
+	state       BreakerState
+	openedAt    time.Time
+	windowStart time.Time
+	requests    int
+	failures    int
+	probes      int
+	successes   int
+}
+
+// Ensure CircuitBreaker implements DatabaseClient interface.
+var _ DatabaseClient = (*CircuitBreaker)(nil)
+
+// NewCircuitBreaker wraps client with a circuit breaker. Its state is exported under the network
+// label, empty for the cluster of clickhouse.dsn.
+func NewCircuitBreaker(
+	client DatabaseClient,
+	cfg config.CircuitBreakerConfig,
+	network string,
+	logger logrus.FieldLogger,
+) *CircuitBreaker {
+	cfg.HalfOpenProbes = max(cfg.HalfOpenProbes, 1)
+
+	cb := &CircuitBreaker{
+		client: client,
+		cfg:    cfg,
+		log:    logger.WithField("module", "clickhouse_breaker"),
+		now:    time.Now,
+		gauge:  circuitBreakerState.WithLabelValues(network),
+	}
+
+	cb.windowStart = cb.now()
+	cb.gauge.Set(float64(BreakerClosed))
+
+	return cb
+}
+
+// Query executes a SQL query unless the breaker is open. The outcome is recorded when the rows
+// are closed, as exceptions raised mid-stream and the query's full duration are only known then.
+func (cb *CircuitBreaker) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
+	done, err := cb.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	start := cb.now()
+
+	rows, err := cb.client.Query(ctx, query, args...)
+	if err != nil {
+		done(ctx, err, cb.now().Sub(start))
+
+		return nil, err
+	}
+
+	return &breakerRows{Rows: rows, done: func() { done(ctx, rows.Err(), cb.now().Sub(start)) }}, nil
+}
+
+// QueryRow executes a query that is expected to return at most one row unless the breaker is open.
+func (cb *CircuitBreaker) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
+	done, err := cb.allow()
+	if err != nil {
+		return errRow{err: err}
+	}
+
+	start := cb.now()
+	row := cb.client.QueryRow(ctx, query, args...)
+	done(ctx, row.Err(), cb.now().Sub(start))
+
+	return row
+}
+
+// Select executes a query and scans results into dest unless the breaker is open.
+func (cb *CircuitBreaker) Select(ctx context.Context, dest any, query string, args ...any) error {
+	done, err := cb.allow()
+	if err != nil {
+		return err
+	}
+
+	start := cb.now()
+	err = cb.client.Select(ctx, dest, query, args...)
+	done(ctx, err, cb.now().Sub(start))
+
+	return err
+}
+
+// Exec executes a query without returning any rows unless the breaker is open.
+func (cb *CircuitBreaker) Exec(ctx context.Context, query string, args ...any) error {
+	done, err := cb.allow()
+	if err != nil {
+		return err
+	}
+
+	start := cb.now()
+	err = cb.client.Exec(ctx, query, args...)
+	done(ctx, err, cb.now().Sub(start))
+
+	return err
+}
+
+// Close closes the wrapped client.
+func (cb *CircuitBreaker) Close() error {
+	return cb.client.Close()
+}
+
+// Name identifies the breaker in /health.
+func (cb *CircuitBreaker) Name() string {
+	return "clickhouse_circuit_breaker"
+}
+
+// State returns the current breaker state.
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.currentState()
+}
+
+// Status returns the current state name, for /health.
+func (cb *CircuitBreaker) Status() string {
+	return cb.State().String()
+}
+
+// Healthy reports whether the breaker is closed, for /health.
+func (cb *CircuitBreaker) Healthy() bool {
+	return cb.State() == BreakerClosed
+}
+
+// allow decides whether a query may run. It returns a function recording the outcome, or a
+// *CircuitOpenError when the query must be rejected.
+func (cb *CircuitBreaker) allow() (func(ctx context.Context, err error, latency time.Duration), error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.currentState() {
+	case BreakerOpen:
+		return nil, &CircuitOpenError{RetryAfter: cb.cfg.OpenDuration - cb.now().Sub(cb.openedAt)}
+	case BreakerHalfOpen:
+		if cb.probes >= cb.cfg.HalfOpenProbes {
+			return nil, &CircuitOpenError{RetryAfter: time.Second}
+		}
+
+		cb.probes++
+
+		return cb.recordProbe, nil
+	default:
+		return cb.record, nil
+	}
+}
+
+// record counts the outcome of a query while closed, tripping the breaker if the error rate is reached.
+func (cb *CircuitBreaker) record(ctx context.Context, err error, latency time.Duration) {
+	failed, counted := cb.isFailure(ctx, err, latency)
+	if !counted {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state != BreakerClosed {
+		return
+	}
+
+	now := cb.now()
+	if now.Sub(cb.windowStart) > cb.cfg.Window {
+		cb.windowStart = now
+		cb.requests = 0
+		cb.failures = 0
+	}
+
+	cb.requests++
+
+	if failed {
+		cb.failures++
+	}
+
+	if cb.requests >= cb.cfg.MinRequests && float64(cb.failures)/float64(cb.requests) >= cb.cfg.ErrorRate {
+		cb.log.WithFields(logrus.Fields{
+			"requests": cb.requests,
+			"failures": cb.failures,
+		}).Warn("ClickHouse circuit breaker opened")
+
+		cb.trip(now)
+	}
+}
+
+// recordProbe handles the outcome of a half-open probe query.
+func (cb *CircuitBreaker) recordProbe(ctx context.Context, err error, latency time.Duration) {
+	failed, counted := cb.isFailure(ctx, err, latency)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state != BreakerHalfOpen {
+		return
+	}
+
+	if !counted {
+		// The caller went away; free the slot for another probe.
+		cb.probes--
+
+		return
+	}
+
+	if failed {
+		cb.log.WithError(err).Warn("ClickHouse circuit breaker probe failed, reopening")
+		cb.trip(cb.now())
+
+		return
+	}
+
+	cb.successes++
+
+	if cb.successes >= cb.cfg.HalfOpenProbes {
+		cb.log.Info("ClickHouse circuit breaker closed")
+		cb.setState(BreakerClosed)
+		cb.windowStart = cb.now()
+		cb.requests = 0
+		cb.failures = 0
+	}
+}
+
+// currentState returns the state, moving from open to half-open once the open duration has passed.
+// cb.mu must be held.
+func (cb *CircuitBreaker) currentState() BreakerState {
+	if cb.state == BreakerOpen && cb.now().Sub(cb.openedAt) >= cb.cfg.OpenDuration {
+		cb.setState(BreakerHalfOpen)
+	}
+
+	return cb.state
+}
+
+// trip opens the breaker. cb.mu must be held.
+func (cb *CircuitBreaker) trip(now time.Time) {
+	cb.openedAt = now
+	cb.setState(BreakerOpen)
+}
+
+// setState changes the state, resetting probe counters when entering half-open. cb.mu must be held.
+func (cb *CircuitBreaker) setState(state BreakerState) {
+	if cb.state == state {
+		return
+	}
+
+	if state == BreakerHalfOpen {
+		cb.probes = 0
+		cb.successes = 0
+	}
+
+	cb.state = state
+	cb.gauge.Set(float64(state))
+}
+
+// isFailure classifies a query outcome. Errors caused by the caller (cancellation) or by the query
+// itself (syntax, unknown column) are not counted; connection errors, timeouts, overload exceptions
+// and queries slower than the latency threshold are failures.
+func (cb *CircuitBreaker) isFailure(ctx context.Context, err error, latency time.Duration) (failed, counted bool) {
+	if errors.Is(err, context.Canceled) || ctx.Err() == context.Canceled {
+		return false, false
+	}
+
+	if cb.cfg.LatencyThreshold > 0 && latency >= cb.cfg.LatencyThreshold {
+		return true, true
+	}
+
+	if err == nil {
+		return false, true
+	}
+
+	var exception *clickhouse.Exception
+	if errors.As(err, &exception) {
+		return overloadExceptionCodes[exception.Code], true
+	}
+
+	return IsConnectionError(err) || errors.Is(err, context.DeadlineExceeded), true
+}
+
+// breakerRows records the query's outcome once the caller closes the rows.
+type breakerRows struct {
+	driver.Rows
+	done func()
+	once sync.Once
+}
+
+// Close closes the underlying rows and records the outcome.
+func (r *breakerRows) Close() error {
+	err := r.Rows.Close()
+	r.once.Do(r.done)
+
+	return err
+}
+
+// errRow is a driver.Row that only returns an error.
+type errRow struct {
+	err error
+}
+
+// Err returns the error.
+func (r errRow) Err() error {
+	return r.err
+}
+
+// Scan returns the error.
+func (r errRow) Scan(_ ...any) error {
+	return r.err
+}
+
+// ScanStruct returns the error.
+func (r errRow) ScanStruct(_ any) error {
+	return r.err
+}
@@ -0,0 +1,211 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
+)
+
+func newTestBreaker(client DatabaseClient, now *time.Time) *CircuitBreaker {
+	cb := NewCircuitBreaker(client, config.CircuitBreakerConfig{
+		Window:           10 * time.Second,
+		MinRequests:      4,
+		ErrorRate:        0.5,
+		LatencyThreshold: time.Second,
+		OpenDuration:     30 * time.Second,
+		HalfOpenProbes:   2,
+	}, "", logrus.New())
+	cb.now = func() time.Time { return *now }
+
+	return cb
+}
+
+func TestCircuitBreaker_TripsAndRecovers(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	client := &fakeReplica{name: "ch:9000", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED)}
+	cb := newTestBreaker(client, &now)
+	ctx := context.Background()
+
+	// Below min_requests the breaker stays closed.
+	for range 3 {
+		require.Error(t, cb.Select(ctx, nil, "SELECT 1"))
+	}
+
+	assert.Equal(t, BreakerClosed, cb.State())
+
+	require.Error(t, cb.Select(ctx, nil, "SELECT 1"))
+	assert.Equal(t, BreakerOpen, cb.State())
+	assert.False(t, cb.Healthy())
+
+	// While open, queries fail fast without reaching the client.
+	now = now.Add(10 * time.Second)
+	err := cb.Select(ctx, nil, "SELECT 1")
+
+	var openErr *CircuitOpenError
+	require.ErrorAs(t, err, &openErr)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 20*time.Second, openErr.RetryAfter)
+	assert.Equal(t, 4, client.calls)
+
+	// After the open duration probes are let through; enough successes close the breaker.
+	now = now.Add(20 * time.Second)
+	assert.Equal(t, "half_open", cb.Status())
+
+	client.err = nil
+	require.NoError(t, cb.Select(ctx, nil, "SELECT 1"))
+	assert.Equal(t, BreakerHalfOpen, cb.State())
+	require.NoError(t, cb.Select(ctx, nil, "SELECT 1"))
+	assert.Equal(t, BreakerClosed, cb.State())
+}
+
+func TestCircuitBreaker_FailedProbeReopens(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	client := &fakeReplica{name: "ch:9000", err: &clickhouse.Exception{Code: 202, Message: "too many simultaneous queries"}}
+	cb := newTestBreaker(client, &now)
+
+	for range 4 {
+		require.Error(t, cb.Select(context.Background(), nil, "SELECT 1"))
+	}
+
+	require.Equal(t, BreakerOpen, cb.State())
+
+	now = now.Add(30 * time.Second)
+	require.Error(t, cb.Select(context.Background(), nil, "SELECT 1"))
+	assert.Equal(t, BreakerOpen, cb.State())
+}
+
+func TestCircuitBreaker_IgnoresCallerErrors(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	client := &fakeReplica{name: "ch:9000", err: &clickhouse.Exception{Code: 47, Message: "unknown identifier"}}
+	cb := newTestBreaker(client, &now)
+
+	for range 10 {
+		require.Error(t, cb.Select(context.Background(), nil, "SELECT nope"))
+	}
+
+	client.err = context.Canceled
+	for range 10 {
+		require.Error(t, cb.Select(context.Background(), nil, "SELECT 1"))
+	}
+
+	assert.Equal(t, BreakerClosed, cb.State())
+}
+
+func TestCircuitBreaker_QueryRowWhenOpen(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cb := newTestBreaker(&fakeReplica{name: "ch:9000"}, &now)
+
+	cb.mu.Lock()
+	cb.trip(now)
+	cb.mu.Unlock()
+
+	row := cb.QueryRow(context.Background(), "SELECT 1")
+	require.True(t, errors.Is(row.Err(), ErrCircuitOpen))
+	require.ErrorIs(t, row.Scan(), ErrCircuitOpen)
+}
+
+// streamingClient returns rows whose error, e.g. an exception raised mid-stream, is only known
+// once they have been read.
+type streamingClient struct {
+	*fakeReplica
+	rowsErr error
+	now     *time.Time
+	elapsed time.Duration
+}
+
+func (c *streamingClient) Query(_ context.Context, _ string, _ ...any) (driver.Rows, error) {
+	return &fakeRows{err: c.rowsErr, onClose: func() { *c.now = c.now.Add(c.elapsed) }}, nil
+}
+
+// fakeRows is an empty driver.Rows returning err once closed.
+type fakeRows struct {
+	driver.Rows
+	err     error
+	onClose func()
+}
+
+func (r *fakeRows) Next() bool { return false }
+
+func (r *fakeRows) Err() error { return r.err }
+
+func (r *fakeRows) Close() error {
+	r.onClose()
+
+	return nil
+}
+
+func TestCircuitBreaker_QueryRecordedOnClose(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("mid-stream exceptions", func(t *testing.T) {
+		client := &streamingClient{
+			fakeReplica: &fakeReplica{name: "ch:9000"},
+			rowsErr:     &clickhouse.Exception{Code: 241, Message: "memory limit exceeded"},
+			now:         &now,
+		}
+		cb := newTestBreaker(client, &now)
+
+		for range 4 {
+			rows, err := cb.Query(context.Background(), "SELECT 1")
+			require.NoError(t, err)
+			assert.Equal(t, BreakerClosed, cb.State(), "nothing is recorded before the rows are closed")
+
+			require.NoError(t, rows.Close())
+			require.NoError(t, rows.Close())
+		}
+
+		assert.Equal(t, BreakerOpen, cb.State())
+	})
+
+	t.Run("latency includes streaming", func(t *testing.T) {
+		client := &streamingClient{fakeReplica: &fakeReplica{name: "ch:9000"}, now: &now, elapsed: 2 * time.Second}
+		cb := newTestBreaker(client, &now)
+
+		for range 4 {
+			rows, err := cb.Query(context.Background(), "SELECT 1")
+			require.NoError(t, err)
+			require.NoError(t, rows.Close())
+		}
+
+		assert.Equal(t, BreakerOpen, cb.State())
+	})
+}
+
+func TestCircuitBreaker_StatePerNetwork(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	mainnet := NewCircuitBreaker(&fakeReplica{name: "ch:9000"}, config.CircuitBreakerConfig{}, "mainnet", logrus.New())
+	holesky := NewCircuitBreaker(&fakeReplica{name: "ch:9000"}, config.CircuitBreakerConfig{}, "holesky", logrus.New())
+
+	mainnet.mu.Lock()
+	mainnet.trip(now)
+	mainnet.mu.Unlock()
+
+	assert.Equal(t, BreakerClosed, holesky.State())
+
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(circuitBreakerState)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+
+	states := make(map[string]float64)
+	for _, m := range families[0].GetMetric() {
+		states[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+	}
+
+	assert.InDelta(t, float64(BreakerOpen), states["mainnet"], 0)
+	assert.InDelta(t, float64(BreakerClosed), states["holesky"], 0)
+}
@@ -0,0 +1,241 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
+)
+
+// killQueryTimeout bounds how long a KILL QUERY may take once the request is gone.
+const killQueryTimeout = 5 * time.Second
+
+var cancelledQueriesTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "cbt_api",
+		Subsystem: "clickhouse",
+		Name:      "cancelled_queries_total",
+		Help:      "Total number of ClickHouse queries killed because the request context was cancelled",
+	},
+	[]string{"result"},
+)
+
+func init() {
+	prometheus.MustRegister(cancelledQueriesTotal)
+}
+
+// Executor runs statements that return no rows, such as KILL QUERY.
+type Executor interface {
+	Exec(ctx context.Context, query string, args ...any) error
+	Close() error
+}
+
+// CancellingClient kills server-side queries when their context is cancelled.
+// The native protocol can leave a query running until max_execution_time after the
+// client goes away, so every query is pinned to a query_id and, on cancellation,
+// KILL QUERY is issued for it on a separate connection.
+type CancellingClient struct {
+	client DatabaseClient
+	killer Executor
+	log    logrus.FieldLogger
+}
+
+// Ensure CancellingClient implements DatabaseClient interface.
+var _ DatabaseClient = (*CancellingClient)(nil)
+
+// NewCancellingClient wraps client so cancelled queries are killed through killer.
+// The killer should use its own connection so a busy pool can't block the kill.
+func NewCancellingClient(client DatabaseClient, killer Executor, logger logrus.FieldLogger) *CancellingClient {
+	return &CancellingClient{
+		client: client,
+		killer: killer,
+		log:    logger.WithField("module", "clickhouse_cancel"),
+	}
+}
+
+// NewKillClient opens single-connection clients used only to issue KILL QUERY statements.
+// With several replicas the kill is sent to all of them, as only the one running the query matches.
+func NewKillClient(cfg *config.ClickHouseConfig, logger logrus.FieldLogger) (Executor, error) {
+	hosts, err := replicaHosts(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make(killBroadcast, 0, len(hosts))
+
+	for _, host := range hosts {
+		killCfg := replicaConfig(cfg, host)
+		killCfg.MaxOpenConns = 1
+		killCfg.MaxIdleConns = 1
+
+		client, err := openClient(killCfg, logger.WithField("pool", "kill"))
+		if err != nil {
+			_ = clients.Close()
+
+			return nil, err
+		}
+
+		clients = append(clients, client)
+	}
+
+	if len(clients) == 1 {
+		return clients[0], nil
+	}
+
+	return clients, nil
+}
+
+// killBroadcast executes statements on every replica.
+type killBroadcast []*Client
+
+// Exec runs the statement on every replica, returning the joined errors.
+func (b killBroadcast) Exec(ctx context.Context, query string, args ...any) error {
+	var errs []error
+
+	for _, client := range b {
+		if err := client.Exec(ctx, query, args...); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", client.host(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close closes every replica connection.
+func (b killBroadcast) Close() error {
+	var errs []error
+
+	for _, client := range b {
+		errs = append(errs, client.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// Query executes a SQL query, killing it if ctx is cancelled before the rows are closed.
+func (c *CancellingClient) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
+	ctx, stop := c.watch(ctx)
+
+	rows, err := c.client.Query(ctx, query, args...)
+	if err != nil {
+		stop()
+
+		return nil, err
+	}
+
+	return &cancellingRows{Rows: rows, stop: stop}, nil
+}
+
+// QueryRow executes a query that is expected to return at most one row, killing it if ctx is
+// cancelled before the row is scanned.
+func (c *CancellingClient) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
+	ctx, stop := c.watch(ctx)
+
+	return &cancellingRow{Row: c.client.QueryRow(ctx, query, args...), stop: stop}
+}
+
+// Select executes a query and scans results directly into a slice of structs.
+func (c *CancellingClient) Select(ctx context.Context, dest any, query string, args ...any) error {
+	ctx, stop := c.watch(ctx)
+	defer stop()
+
+	return c.client.Select(ctx, dest, query, args...)
+}
+
+// Exec executes a query without returning any rows.
+func (c *CancellingClient) Exec(ctx context.Context, query string, args ...any) error {
+	ctx, stop := c.watch(ctx)
+	defer stop()
+
+	return c.client.Exec(ctx, query, args...)
+}
+
+// Close closes both the query and kill connections.
+func (c *CancellingClient) Close() error {
+	err := c.client.Close()
+
+	if killErr := c.killer.Close(); err == nil {
+		err = killErr
+	}
+
+	return err
+}
+
+// watch pins a query_id on ctx and arranges for the query to be killed if ctx is cancelled
+// before the returned stop function is called.
+func (c *CancellingClient) watch(ctx context.Context) (context.Context, func()) {
+	queryID := resolveQueryID(ctx, optionsFromContext(ctx))
+	ctx = WithQueryID(ctx, queryID)
+
+	stop := context.AfterFunc(ctx, func() {
+		c.kill(queryID)
+	})
+
+	return ctx, func() { stop() }
+}
+
+// kill issues KILL QUERY for queryID. It runs on a fresh context since the request one is done.
+func (c *CancellingClient) kill(queryID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), killQueryTimeout)
+	defer cancel()
+
+	if err := c.killer.Exec(ctx, "KILL QUERY WHERE query_id = ? ASYNC", queryID); err != nil {
+		cancelledQueriesTotal.WithLabelValues("error").Inc()
+		c.log.WithError(err).WithField("query_id", queryID).Warn("Failed to kill cancelled query")
+
+		return
+	}
+
+	cancelledQueriesTotal.WithLabelValues("killed").Inc()
+	c.log.WithField("query_id", queryID).Debug("Killed cancelled query")
+}
+
+// cancellingRows stops watching for cancellation once the caller closes the rows.
+type cancellingRows struct {
+	driver.Rows
+	stop func()
+}
+
+// Close closes the underlying rows and stops the cancellation watch.
+func (r *cancellingRows) Close() error {
+	defer r.stop()
+
+	return r.Rows.Close()
+}
+
+// cancellingRow stops watching for cancellation once the caller has scanned the row, which reads
+// the streamed result, or the query has failed.
+type cancellingRow struct {
+	driver.Row
+	stop func()
+}
+
+// Err returns the query error, stopping the cancellation watch if there is one.
+func (r *cancellingRow) Err() error {
+	err := r.Row.Err()
+	if err != nil {
+		r.stop()
+	}
+
+	return err
+}
+
+// Scan scans the row and stops the cancellation watch.
+func (r *cancellingRow) Scan(dest ...any) error {
+	defer r.stop()
+
+	return r.Row.Scan(dest...)
+}
+
+// ScanStruct scans the row into a struct and stops the cancellation watch.
+func (r *cancellingRow) ScanStruct(dest any) error {
+	defer r.stop()
+
+	return r.Row.ScanStruct(dest)
+}
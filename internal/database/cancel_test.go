@@ -0,0 +1,176 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClient is a DatabaseClient that records calls. Select blocks until its context is done
+// when block is set, simulating a long-running query.
+type fakeClient struct {
+	mu      sync.Mutex
+	block   bool
+	queryID string
+	execs   []fakeExec
+	killed  chan struct{}
+}
+
+type fakeExec struct {
+	query string
+	args  []any
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{killed: make(chan struct{}, 1)}
+}
+
+func (f *fakeClient) Query(_ context.Context, _ string, _ ...any) (driver.Rows, error) {
+	return nil, context.Canceled
+}
+
+func (f *fakeClient) QueryRow(ctx context.Context, _ string, _ ...any) driver.Row {
+	return &blockingRow{ctx: ctx, block: f.block}
+}
+
+// blockingRow is a driver.Row whose Scan, like reading a streamed result, blocks until its
+// context is done when block is set.
+type blockingRow struct {
+	driver.Row
+	ctx   context.Context
+	block bool
+}
+
+func (r *blockingRow) Err() error {
+	return nil
+}
+
+func (r *blockingRow) Scan(_ ...any) error {
+	if r.block {
+		<-r.ctx.Done()
+
+		return r.ctx.Err()
+	}
+
+	return nil
+}
+
+func (f *fakeClient) Select(ctx context.Context, _ any, _ string, _ ...any) error {
+	f.mu.Lock()
+	f.queryID = QueryIDFromContext(ctx)
+	f.mu.Unlock()
+
+	if f.block {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}
+
+	return nil
+}
+
+func (f *fakeClient) Exec(_ context.Context, query string, args ...any) error {
+	f.mu.Lock()
+	f.execs = append(f.execs, fakeExec{query: query, args: args})
+	f.mu.Unlock()
+
+	f.killed <- struct{}{}
+
+	return nil
+}
+
+func (f *fakeClient) Close() error {
+	return nil
+}
+
+func TestCancellingClient_KillsCancelledQuery(t *testing.T) {
+	client := newFakeClient()
+	client.block = true
+	killer := newFakeClient()
+
+	db := NewCancellingClient(client, killer, logrus.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	go func() {
+		done <- db.Select(ctx, nil, "SELECT * FROM fct_block")
+	}()
+
+	// Let the query start, then simulate the client disconnecting.
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	select {
+	case <-killer.killed:
+	case <-time.After(time.Second):
+		t.Fatal("expected KILL QUERY to be issued")
+	}
+
+	killer.mu.Lock()
+	defer killer.mu.Unlock()
+
+	require.Len(t, killer.execs, 1)
+	assert.Equal(t, "KILL QUERY WHERE query_id = ? ASYNC", killer.execs[0].query)
+	assert.NotEmpty(t, client.queryID)
+	assert.Equal(t, []any{client.queryID}, killer.execs[0].args)
+}
+
+func TestCancellingClient_KillsQueryRowCancelledDuringScan(t *testing.T) {
+	client := newFakeClient()
+	client.block = true
+	killer := newFakeClient()
+
+	db := NewCancellingClient(client, killer, logrus.New())
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	row := db.QueryRow(ctx, "SELECT count() FROM fct_block")
+	require.NoError(t, row.Err())
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- row.Scan()
+	}()
+
+	// Disconnect while the result is being read.
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	select {
+	case <-killer.killed:
+	case <-time.After(time.Second):
+		t.Fatal("expected KILL QUERY to be issued")
+	}
+}
+
+func TestCancellingClient_NoKillAfterCompletion(t *testing.T) {
+	client := newFakeClient()
+	killer := newFakeClient()
+
+	db := NewCancellingClient(client, killer, logrus.New())
+
+	ctx, cancel := context.WithCancel(WithQueryID(context.Background(), "q-1"))
+
+	require.NoError(t, db.Select(ctx, nil, "SELECT 1"))
+	cancel()
+
+	select {
+	case <-killer.killed:
+		t.Fatal("completed query should not be killed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal(t, "q-1", client.queryID)
+}
@@ -43,8 +43,43 @@ type Client struct {
 // Ensure Client implements DatabaseClient interface.
 var _ DatabaseClient = (*Client)(nil)
 
+// Open connects to ClickHouse. A single host yields a *Client; multiple hosts, given in the DSN
+// (host1:9000,host2:9000) or the hosts list, yield a load-balanced *ReplicaSet.
+func Open(cfg *config.ClickHouseConfig, logger logrus.FieldLogger) (ReplicaClient, error) {
+	hosts, err := replicaHosts(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(hosts) == 1 {
+		return NewClient(replicaConfig(cfg, hosts[0]), logger)
+	}
+
+	return NewReplicaSet(cfg, logger)
+}
+
 // NewClient creates a new ClickHouse client using the official Go driver.
 func NewClient(cfg *config.ClickHouseConfig, logger logrus.FieldLogger) (*Client, error) {
+	client, err := openClient(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Test connection
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx); err != nil {
+		_ = client.conn.Close()
+
+		return nil, fmt.Errorf("failed to ping ClickHouse: %w", err)
+	}
+
+	return client, nil
+}
+
+// openClient creates a ClickHouse client without checking that the server is reachable.
+func openClient(cfg *config.ClickHouseConfig, logger logrus.FieldLogger) (*Client, error) {
 	log := logger.WithFields(logrus.Fields{
 		"module": "clickhouse",
 	})
@@ -66,16 +101,6 @@ func NewClient(cfg *config.ClickHouseConfig, logger logrus.FieldLogger) (*Client
 		return nil, fmt.Errorf("failed to create ClickHouse connection: %w", err)
 	}
 
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	if err := conn.Ping(ctx); err != nil {
-		_ = conn.Close()
-
-		return nil, fmt.Errorf("failed to ping ClickHouse: %w", err)
-	}
-
 	log.WithFields(logrus.Fields{
 		"database":          cfg.Database,
 		"host":              parsedDSN.Host,
@@ -91,24 +116,39 @@ func NewClient(cfg *config.ClickHouseConfig, logger logrus.FieldLogger) (*Client
 	}, nil
 }
 
+// host returns the host this client connects to.
+func (c *Client) host() string {
+	parsedDSN, err := parseDSN(c.config.DSN)
+	if err != nil {
+		return ""
+	}
+
+	return parsedDSN.Host
+}
+
+// Ping checks that the server is reachable.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.conn.Ping(ctx)
+}
+
 // Query executes a SQL query and returns rows (native driver interface).
 func (c *Client) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
-	return c.conn.Query(ctx, query, args...)
+	return c.conn.Query(queryContext(ctx), query, args...)
 }
 
 // QueryRow executes a query that is expected to return at most one row.
 func (c *Client) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
-	return c.conn.QueryRow(ctx, query, args...)
+	return c.conn.QueryRow(queryContext(ctx), query, args...)
 }
 
 // Select executes a query and scans results directly into a slice of structs.
 func (c *Client) Select(ctx context.Context, dest any, query string, args ...any) error {
-	return c.conn.Select(ctx, dest, query, args...)
+	return c.conn.Select(queryContext(ctx), dest, query, args...)
 }
 
 // Exec executes a query without returning any rows.
 func (c *Client) Exec(ctx context.Context, query string, args ...any) error {
-	return c.conn.Exec(ctx, query, args...)
+	return c.conn.Exec(queryContext(ctx), query, args...)
 }
 
 // Close closes the database connection.
@@ -147,6 +187,50 @@ func parseDSN(dsn string) (*url.URL, error) {
 	return parsedURL, nil
 }
 
+// replicaHosts returns the hosts to connect to: the hosts list if set, otherwise the
+// comma-separated hosts of the DSN.
+func replicaHosts(cfg *config.ClickHouseConfig) ([]string, error) {
+	if len(cfg.Hosts) > 0 {
+		return cfg.Hosts, nil
+	}
+
+	parsedDSN, err := parseDSN(cfg.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DSN: %w", err)
+	}
+
+	hosts := make([]string, 0, 1)
+
+	for host := range strings.SplitSeq(parsedDSN.Host, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no ClickHouse host in DSN")
+	}
+
+	return hosts, nil
+}
+
+// replicaConfig returns a copy of cfg whose DSN points at a single host.
+func replicaConfig(cfg *config.ClickHouseConfig, host string) *config.ClickHouseConfig {
+	replicaCfg := *cfg
+	replicaCfg.Hosts = nil
+
+	parsedDSN, err := parseDSN(cfg.DSN)
+	if err != nil {
+		// Left unchanged so the client reports the parse error.
+		return &replicaCfg
+	}
+
+	parsedDSN.Host = host
+	replicaCfg.DSN = parsedDSN.String()
+
+	return &replicaCfg
+}
+
 // createClickHouseOptions builds connection options for the driver.
 func createClickHouseOptions(cfg *config.ClickHouseConfig, parsedURL *url.URL) *clickhouse.Options {
 	// Extract auth
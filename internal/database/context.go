@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"maps"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ethpandaops/cbt-api/internal/requestid"
+)
+
+// SettingPreferredProjection is the ClickHouse setting that steers the optimizer towards a named projection.
+const SettingPreferredProjection = "preferred_optimize_projection_name"
+
+// SettingLogComment is the ClickHouse setting recorded in system.query_log.log_comment.
+const SettingLogComment = "log_comment"
+
+// queryOptionsKey is the context key for per-query options.
+type queryOptionsKey struct{}
+
+// queryOptions holds per-query options accumulated on a context before execution.
+type queryOptions struct {
+	settings   clickhouse.Settings
+	queryID    string
+	collectors []*StatsCollector
+}
+
+// WithSettings returns a context carrying the given ClickHouse settings for queries executed with it.
+// Settings are merged with any already present on the context, with the new values taking precedence.
+func WithSettings(ctx context.Context, settings clickhouse.Settings) context.Context {
+	opts := optionsFromContext(ctx)
+
+	merged := make(clickhouse.Settings, len(opts.settings)+len(settings))
+	maps.Copy(merged, opts.settings)
+	maps.Copy(merged, settings)
+
+	opts.settings = merged
+
+	return context.WithValue(ctx, queryOptionsKey{}, opts)
+}
+
+// WithProjection returns a context that asks ClickHouse to prefer the named projection.
+func WithProjection(ctx context.Context, projection string) context.Context {
+	if projection == "" {
+		return ctx
+	}
+
+	return WithSettings(ctx, clickhouse.Settings{SettingPreferredProjection: projection})
+}
+
+// WithQueryID returns a context that runs its queries with the given ClickHouse query_id.
+func WithQueryID(ctx context.Context, queryID string) context.Context {
+	opts := optionsFromContext(ctx)
+	opts.queryID = queryID
+
+	return context.WithValue(ctx, queryOptionsKey{}, opts)
+}
+
+// QueryIDFromContext returns the query_id set on the context, or "" if none.
+func QueryIDFromContext(ctx context.Context) string {
+	return optionsFromContext(ctx).queryID
+}
+
+// SettingsFromContext returns a copy of the ClickHouse settings attached to the context.
+func SettingsFromContext(ctx context.Context) clickhouse.Settings {
+	opts := optionsFromContext(ctx)
+	if len(opts.settings) == 0 {
+		return nil
+	}
+
+	return maps.Clone(opts.settings)
+}
+
+// optionsFromContext returns the query options attached to the context, or the zero value.
+func optionsFromContext(ctx context.Context) queryOptions {
+	if opts, ok := ctx.Value(queryOptionsKey{}).(queryOptions); ok {
+		return opts
+	}
+
+	return queryOptions{}
+}
+
+// queryContext applies the per-query options on ctx to the driver context.
+func queryContext(ctx context.Context) context.Context {
+	opts := optionsFromContext(ctx)
+	queryID := resolveQueryID(ctx, opts)
+
+	chOpts := []clickhouse.QueryOption{clickhouse.WithQueryID(queryID)}
+
+	if settings := resolveSettings(ctx, opts); len(settings) > 0 {
+		chOpts = append(chOpts, clickhouse.WithSettings(settings))
+	}
+
+	chOpts = append(chOpts, statsOptions(queryID, opts.collectors)...)
+
+	return clickhouse.Context(ctx, chOpts...)
+}
+
+// resolveQueryID picks the query_id so every query can be correlated with system.query_log:
+// an explicit WithQueryID, else one derived from the HTTP request ID, else a random one.
+func resolveQueryID(ctx context.Context, opts queryOptions) string {
+	if opts.queryID != "" {
+		return opts.queryID
+	}
+
+	if queryID := requestid.NextQueryID(ctx); queryID != "" {
+		return queryID
+	}
+
+	return uuid.NewString()
+}
+
+// resolveSettings returns the query settings, adding the trace ID as log_comment unless one is set.
+func resolveSettings(ctx context.Context, opts queryOptions) clickhouse.Settings {
+	settings := maps.Clone(opts.settings)
+
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.HasTraceID() {
+		return settings
+	}
+
+	if _, ok := settings[SettingLogComment]; ok {
+		return settings
+	}
+
+	if settings == nil {
+		settings = make(clickhouse.Settings, 1)
+	}
+
+	settings[SettingLogComment] = spanCtx.TraceID().String()
+
+	return settings
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ethpandaops/cbt-api/internal/requestid"
+)
+
+func TestWithSettings(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, SettingsFromContext(ctx))
+
+	ctx = WithSettings(ctx, clickhouse.Settings{"max_threads": 4, "log_comment": "a"})
+	ctx = WithSettings(ctx, clickhouse.Settings{"log_comment": "b"})
+
+	assert.Equal(t, clickhouse.Settings{"max_threads": 4, "log_comment": "b"}, SettingsFromContext(ctx))
+}
+
+func TestWithSettings_DoesNotMutateParent(t *testing.T) {
+	parent := WithSettings(context.Background(), clickhouse.Settings{"max_threads": 4})
+	_ = WithSettings(parent, clickhouse.Settings{"max_threads": 8})
+
+	assert.Equal(t, clickhouse.Settings{"max_threads": 4}, SettingsFromContext(parent))
+}
+
+func TestWithProjection(t *testing.T) {
+	ctx := WithProjection(context.Background(), "p_by_slot")
+	assert.Equal(t, clickhouse.Settings{SettingPreferredProjection: "p_by_slot"}, SettingsFromContext(ctx))
+
+	// Empty projection is a no-op.
+	assert.Nil(t, SettingsFromContext(WithProjection(context.Background(), "")))
+}
+
+func TestResolveQueryID(t *testing.T) {
+	// Explicit query ID wins.
+	ctx := requestid.WithContext(context.Background(), "req-1")
+	assert.Equal(t, "explicit", resolveQueryID(ctx, optionsFromContext(WithQueryID(ctx, "explicit"))))
+
+	// The request ID is embedded after a server-generated prefix, with a suffix for subsequent queries.
+	first := resolveQueryID(ctx, optionsFromContext(ctx))
+	assert.True(t, strings.HasSuffix(first, "-req-1"), first)
+	assert.Equal(t, first+"-2", resolveQueryID(ctx, optionsFromContext(ctx)))
+
+	// Otherwise a random ID is generated.
+	assert.NotEmpty(t, resolveQueryID(context.Background(), queryOptions{}))
+}
+
+func TestResolveSettings_LogComment(t *testing.T) {
+	traceID := trace.TraceID{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  trace.SpanID{0x01},
+	}))
+
+	assert.Nil(t, resolveSettings(context.Background(), queryOptions{}))
+
+	settings := resolveSettings(ctx, optionsFromContext(WithProjection(ctx, "p_by_slot")))
+	assert.Equal(t, clickhouse.Settings{
+		SettingPreferredProjection: "p_by_slot",
+		SettingLogComment:          traceID.String(),
+	}, settings)
+
+	// An explicit log_comment is preserved.
+	explicit := WithSettings(ctx, clickhouse.Settings{SettingLogComment: "manual"})
+	assert.Equal(t, "manual", resolveSettings(ctx, optionsFromContext(explicit))[SettingLogComment])
+}
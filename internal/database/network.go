@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+
+	"github.com/ethpandaops/cbt-api/internal/network"
+)
+
+// NetworkClient sends each query to the client of the request's network, for networks whose
+// databases are on a different ClickHouse cluster. Other networks use the default client.
+type NetworkClient struct {
+	defaultClient DatabaseClient
+	clients       map[string]DatabaseClient // by network name
+}
+
+// Ensure NetworkClient implements DatabaseClient interface.
+var _ DatabaseClient = (*NetworkClient)(nil)
+
+// NewNetworkClient creates a NetworkClient. clients maps network names to their clients; a client
+// may serve several networks.
+func NewNetworkClient(defaultClient DatabaseClient, clients map[string]DatabaseClient) *NetworkClient {
+	return &NetworkClient{
+		defaultClient: defaultClient,
+		clients:       clients,
+	}
+}
+
+// Query executes a query on the network's client.
+func (c *NetworkClient) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
+	return c.client(ctx).Query(ctx, query, args...)
+}
+
+// QueryRow executes a query returning a single row on the network's client.
+func (c *NetworkClient) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
+	return c.client(ctx).QueryRow(ctx, query, args...)
+}
+
+// Select executes a query and scans the results into dest on the network's client.
+func (c *NetworkClient) Select(ctx context.Context, dest any, query string, args ...any) error {
+	return c.client(ctx).Select(ctx, dest, query, args...)
+}
+
+// Exec executes a statement on the network's client.
+func (c *NetworkClient) Exec(ctx context.Context, query string, args ...any) error {
+	return c.client(ctx).Exec(ctx, query, args...)
+}
+
+// Close closes every client once.
+func (c *NetworkClient) Close() error {
+	closed := map[DatabaseClient]bool{c.defaultClient: true}
+	errs := []error{c.defaultClient.Close()}
+
+	for _, client := range c.clients {
+		if closed[client] {
+			continue
+		}
+
+		closed[client] = true
+
+		errs = append(errs, client.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+// client returns the client for the network on ctx.
+func (c *NetworkClient) client(ctx context.Context) DatabaseClient {
+	if client, ok := c.clients[network.Name(ctx)]; ok {
+		return client
+	}
+
+	return c.defaultClient
+}
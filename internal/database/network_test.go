@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/cbt-api/internal/network"
+)
+
+// countingClient is a fakeClient that counts Select calls.
+type countingClient struct {
+	fakeClient
+	selects int
+}
+
+func (c *countingClient) Select(_ context.Context, _ any, _ string, _ ...any) error {
+	c.selects++
+
+	return nil
+}
+
+func TestNetworkClient(t *testing.T) {
+	defaultClient, holesky := &countingClient{}, &countingClient{}
+	client := NewNetworkClient(defaultClient, map[string]DatabaseClient{"holesky": holesky})
+
+	ctx := network.WithContext(context.Background(), network.Network{Name: "holesky", Database: "holesky"})
+	require.NoError(t, client.Select(ctx, nil, "SELECT 1"))
+	assert.Equal(t, 1, holesky.selects)
+	assert.Equal(t, 0, defaultClient.selects)
+
+	// Networks without their own client, and queries outside any network, use the default
+	ctx = network.WithContext(context.Background(), network.Network{Name: "mainnet", Database: "mainnet"})
+	require.NoError(t, client.Select(ctx, nil, "SELECT 2"))
+	require.NoError(t, client.Select(context.Background(), nil, "SELECT 3"))
+	assert.Equal(t, 2, defaultClient.selects)
+	assert.Equal(t, 1, holesky.selects)
+}
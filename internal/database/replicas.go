@@ -0,0 +1,461 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
+)
+
+// Load balancing strategies for ReplicaSet.
+const (
+	LoadBalancingRoundRobin = "round_robin"
+	LoadBalancingRandom     = "random"
+	LoadBalancingInOrder    = "in_order"
+)
+
+const (
+	// healthCheckTimeout bounds a single replica ping.
+	healthCheckTimeout = 5 * time.Second
+
+	// readmitBackoff is how long an ejected replica is skipped before queries try it again when
+	// health checks are disabled.
+	readmitBackoff = 30 * time.Second
+)
+
+var (
+	replicaHealthy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "cbt_api",
+			Subsystem: "clickhouse",
+			Name:      "replica_healthy",
+			Help:      "Whether a ClickHouse replica passed its last health check (1) or is ejected (0)",
+		},
+		[]string{"host"},
+	)
+
+	replicaQueriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "cbt_api",
+			Subsystem: "clickhouse",
+			Name:      "replica_queries_total",
+			Help:      "Total number of queries sent to a ClickHouse replica",
+		},
+		[]string{"host"},
+	)
+
+	replicaErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "cbt_api",
+			Subsystem: "clickhouse",
+			Name:      "replica_connection_errors_total",
+			Help:      "Total number of connection-level errors from a ClickHouse replica",
+		},
+		[]string{"host"},
+	)
+
+	replicaRetriesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "cbt_api",
+			Subsystem: "clickhouse",
+			Name:      "replica_retries_total",
+			Help:      "Total number of SELECTs retried on another replica after a connection error",
+		},
+		[]string{"host"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(replicaHealthy)
+	prometheus.MustRegister(replicaQueriesTotal)
+	prometheus.MustRegister(replicaErrorsTotal)
+	prometheus.MustRegister(replicaRetriesTotal)
+}
+
+// ReplicaClient is a DatabaseClient for a single replica that can be health checked.
+type ReplicaClient interface {
+	DatabaseClient
+	Ping(ctx context.Context) error
+}
+
+// replica is a single host in a ReplicaSet.
+type replica struct {
+	host      string
+	client    ReplicaClient
+	healthy   atomic.Bool
+	ejectedAt atomic.Int64 // unix nanoseconds of the last connection error
+}
+
+// ReplicaSet load balances queries over several ClickHouse replicas. Replicas failing their
+// background health check, or returning connection errors, are ejected until they recover.
+// Without health checks, an ejected replica is tried again by queries after readmitBackoff and
+// readmitted once one succeeds. Idempotent SELECTs failing with a connection error are retried
+// on another replica.
+type ReplicaSet struct {
+	replicas     []*replica
+	strategy     string
+	maxRetries   int
+	readmitAfter time.Duration // zero while the health loop readmits replicas
+	next         atomic.Uint64
+	log          logrus.FieldLogger
+	now          func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// Ensure ReplicaSet implements DatabaseClient interface.
+var _ DatabaseClient = (*ReplicaSet)(nil)
+
+// NewReplicaSet connects to every configured host. Unreachable replicas start ejected; an error
+// is returned only if none of them can be reached.
+func NewReplicaSet(cfg *config.ClickHouseConfig, logger logrus.FieldLogger) (*ReplicaSet, error) {
+	hosts, err := replicaHosts(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make([]ReplicaClient, 0, len(hosts))
+
+	for _, host := range hosts {
+		client, err := openClient(replicaConfig(cfg, host), logger.WithField("replica", host))
+		if err != nil {
+			for _, c := range clients {
+				_ = c.Close()
+			}
+
+			return nil, fmt.Errorf("failed to create ClickHouse client for %s: %w", host, err)
+		}
+
+		clients = append(clients, client)
+	}
+
+	rs, err := newReplicaSet(hosts, clients, cfg.LoadBalancing, cfg.MaxRetries, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if rs.checkHealth() == 0 {
+		_ = rs.Close()
+
+		return nil, fmt.Errorf("failed to ping any ClickHouse replica (%s)", strings.Join(hosts, ", "))
+	}
+
+	if cfg.HealthCheckInterval <= 0 {
+		rs.readmitAfter = readmitBackoff
+	}
+
+	go rs.healthLoop(cfg.HealthCheckInterval)
+
+	return rs, nil
+}
+
+// newReplicaSet builds a ReplicaSet over the given clients without starting health checks.
+func newReplicaSet(
+	hosts []string,
+	clients []ReplicaClient,
+	strategy string,
+	maxRetries int,
+	logger logrus.FieldLogger,
+) (*ReplicaSet, error) {
+	switch strategy {
+	case "":
+		strategy = LoadBalancingRoundRobin
+	case LoadBalancingRoundRobin, LoadBalancingRandom, LoadBalancingInOrder:
+	default:
+		return nil, fmt.Errorf("unknown load balancing strategy %q", strategy)
+	}
+
+	rs := &ReplicaSet{
+		replicas:   make([]*replica, len(hosts)),
+		strategy:   strategy,
+		maxRetries: max(maxRetries, 0),
+		log:        logger.WithField("module", "clickhouse_replicas"),
+		now:        time.Now,
+		stop:       make(chan struct{}),
+	}
+
+	for i, host := range hosts {
+		rs.replicas[i] = &replica{host: host, client: clients[i]}
+		rs.replicas[i].healthy.Store(true)
+		replicaHealthy.WithLabelValues(host).Set(1)
+	}
+
+	return rs, nil
+}
+
+// Query executes a SQL query on a replica, retrying SELECTs on another replica after a connection error.
+func (rs *ReplicaSet) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
+	var rows driver.Rows
+
+	err := rs.do(ctx, query, func(r *replica) error {
+		var err error
+
+		rows, err = r.client.Query(ctx, query, args...)
+
+		return err
+	})
+
+	return rows, err
+}
+
+// QueryRow executes a query that is expected to return at most one row.
+func (rs *ReplicaSet) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
+	var row driver.Row
+
+	_ = rs.do(ctx, query, func(r *replica) error {
+		row = r.client.QueryRow(ctx, query, args...)
+
+		return row.Err()
+	})
+
+	return row
+}
+
+// Select executes a query and scans results directly into a slice of structs.
+func (rs *ReplicaSet) Select(ctx context.Context, dest any, query string, args ...any) error {
+	return rs.do(ctx, query, func(r *replica) error {
+		return r.client.Select(ctx, dest, query, args...)
+	})
+}
+
+// Exec executes a query without returning any rows. It is never retried.
+func (rs *ReplicaSet) Exec(ctx context.Context, query string, args ...any) error {
+	return rs.do(ctx, query, func(r *replica) error {
+		return r.client.Exec(ctx, query, args...)
+	})
+}
+
+// Ping succeeds if any replica is reachable.
+func (rs *ReplicaSet) Ping(ctx context.Context) error {
+	var errs []error
+
+	for _, r := range rs.candidates() {
+		err := r.client.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", r.host, err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close stops health checks and closes every replica connection.
+func (rs *ReplicaSet) Close() error {
+	rs.stopOnce.Do(func() { close(rs.stop) })
+
+	var errs []error
+
+	for _, r := range rs.replicas {
+		if err := r.client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.host, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// do runs fn on replicas in strategy order. Connection errors eject the replica and, for
+// idempotent reads, the query is retried on the next candidate up to maxRetries times.
+func (rs *ReplicaSet) do(ctx context.Context, query string, fn func(r *replica) error) error {
+	candidates := rs.candidates()
+
+	attempts := 1
+	if isIdempotentQuery(query) {
+		attempts = min(1+rs.maxRetries, len(candidates))
+	}
+
+	var err error
+
+	for i := range attempts {
+		r := candidates[i]
+
+		if i > 0 {
+			replicaRetriesTotal.WithLabelValues(r.host).Inc()
+		}
+
+		replicaQueriesTotal.WithLabelValues(r.host).Inc()
+
+		err = fn(r)
+		if err == nil || ctx.Err() != nil || !IsConnectionError(err) {
+			// A replica retried after its backoff is back
+			if !r.healthy.Load() && rs.readmitAfter > 0 {
+				rs.setHealthy(r, true, nil)
+			}
+
+			return err
+		}
+
+		replicaErrorsTotal.WithLabelValues(r.host).Inc()
+		rs.setHealthy(r, false, err)
+	}
+
+	return err
+}
+
+// candidates returns the healthy replicas, and those due to be tried again, in the order they
+// should be tried. If every replica is ejected all of them are returned, as failing fast helps
+// nobody.
+func (rs *ReplicaSet) candidates() []*replica {
+	healthy := make([]*replica, 0, len(rs.replicas))
+
+	for _, r := range rs.replicas {
+		if r.healthy.Load() || rs.readmittable(r) {
+			healthy = append(healthy, r)
+		}
+	}
+
+	if len(healthy) == 0 {
+		healthy = append(healthy, rs.replicas...)
+	}
+
+	switch rs.strategy {
+	case LoadBalancingRandom:
+		rand.Shuffle(len(healthy), func(i, j int) { healthy[i], healthy[j] = healthy[j], healthy[i] })
+	case LoadBalancingRoundRobin:
+		start := int((rs.next.Add(1) - 1) % uint64(len(healthy)))
+		healthy = append(healthy[start:], healthy[:start]...)
+	}
+
+	return healthy
+}
+
+// readmittable reports whether an ejected replica's backoff has elapsed without health checks.
+func (rs *ReplicaSet) readmittable(r *replica) bool {
+	if rs.readmitAfter <= 0 {
+		return false
+	}
+
+	return rs.now().Sub(time.Unix(0, r.ejectedAt.Load())) >= rs.readmitAfter
+}
+
+// healthLoop pings every replica on the given interval until Close is called.
+func (rs *ReplicaSet) healthLoop(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rs.stop:
+			return
+		case <-ticker.C:
+			rs.checkHealth()
+		}
+	}
+}
+
+// checkHealth pings every replica, updating its health, and returns the number of healthy replicas.
+func (rs *ReplicaSet) checkHealth() int {
+	var (
+		wg      sync.WaitGroup
+		healthy atomic.Int64
+	)
+
+	for _, r := range rs.replicas {
+		wg.Go(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+			defer cancel()
+
+			err := r.client.Ping(ctx)
+			rs.setHealthy(r, err == nil, err)
+
+			if err == nil {
+				healthy.Add(1)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	return int(healthy.Load())
+}
+
+// setHealthy updates a replica's health, logging transitions. Every failure restarts the
+// readmission backoff.
+func (rs *ReplicaSet) setHealthy(r *replica, healthy bool, err error) {
+	if !healthy {
+		r.ejectedAt.Store(rs.now().UnixNano())
+	}
+
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+
+	if healthy {
+		replicaHealthy.WithLabelValues(r.host).Set(1)
+		rs.log.WithField("host", r.host).Info("ClickHouse replica is healthy again")
+
+		return
+	}
+
+	replicaHealthy.WithLabelValues(r.host).Set(0)
+	rs.log.WithError(err).WithField("host", r.host).Warn("Ejecting unhealthy ClickHouse replica")
+}
+
+// isIdempotentQuery reports whether a query only reads data and is therefore safe to retry.
+func isIdempotentQuery(query string) bool {
+	op := extractStatement(query)
+
+	return op == "SELECT" || op == "WITH"
+}
+
+// extractStatement returns the upper-cased first keyword of a query.
+func extractStatement(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	return strings.ToUpper(fields[0])
+}
+
+// IsConnectionError reports whether err means the replica could not be reached or the
+// connection broke, as opposed to the server rejecting the query.
+func IsConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var exception *clickhouse.Exception
+	if errors.As(err, &exception) {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, clickhouse.ErrAcquireConnTimeout) ||
+		errors.Is(err, clickhouse.ErrConnectionClosed) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
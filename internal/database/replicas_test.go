@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
+)
+
+// fakeReplica is a ReplicaClient returning canned errors and counting calls.
+type fakeReplica struct {
+	name    string
+	err     error
+	pingErr error
+	calls   int
+}
+
+func (f *fakeReplica) Query(_ context.Context, _ string, _ ...any) (driver.Rows, error) {
+	f.calls++
+
+	return nil, f.err
+}
+
+func (f *fakeReplica) QueryRow(_ context.Context, _ string, _ ...any) driver.Row {
+	f.calls++
+
+	return nil
+}
+
+func (f *fakeReplica) Select(_ context.Context, dest any, _ string, _ ...any) error {
+	f.calls++
+
+	if f.err == nil {
+		if names, ok := dest.(*[]string); ok {
+			*names = append(*names, f.name)
+		}
+	}
+
+	return f.err
+}
+
+func (f *fakeReplica) Exec(_ context.Context, _ string, _ ...any) error {
+	f.calls++
+
+	return f.err
+}
+
+func (f *fakeReplica) Ping(_ context.Context) error {
+	return f.pingErr
+}
+
+func (f *fakeReplica) Close() error {
+	return nil
+}
+
+func newTestReplicaSet(t *testing.T, strategy string, maxRetries int, replicas ...*fakeReplica) *ReplicaSet {
+	t.Helper()
+
+	hosts := make([]string, len(replicas))
+	clients := make([]ReplicaClient, len(replicas))
+
+	for i, r := range replicas {
+		hosts[i] = r.name
+		clients[i] = r
+	}
+
+	rs, err := newReplicaSet(hosts, clients, strategy, maxRetries, logrus.New())
+	require.NoError(t, err)
+
+	return rs
+}
+
+// selectFrom runs a SELECT and returns the name of the replica that answered it.
+func selectFrom(t *testing.T, rs *ReplicaSet) string {
+	t.Helper()
+
+	var names []string
+	require.NoError(t, rs.Select(context.Background(), &names, "SELECT 1"))
+	require.Len(t, names, 1)
+
+	return names[0]
+}
+
+func TestReplicaSet_Strategies(t *testing.T) {
+	a, b, c := &fakeReplica{name: "a:9000"}, &fakeReplica{name: "b:9000"}, &fakeReplica{name: "c:9000"}
+
+	rs := newTestReplicaSet(t, LoadBalancingRoundRobin, 0, a, b, c)
+	assert.Equal(t, []string{"a:9000", "b:9000", "c:9000", "a:9000"}, []string{
+		selectFrom(t, rs), selectFrom(t, rs), selectFrom(t, rs), selectFrom(t, rs),
+	})
+
+	rs = newTestReplicaSet(t, LoadBalancingInOrder, 0, a, b, c)
+	assert.Equal(t, "a:9000", selectFrom(t, rs))
+	assert.Equal(t, "a:9000", selectFrom(t, rs))
+
+	rs = newTestReplicaSet(t, LoadBalancingRandom, 0, a, b, c)
+	assert.Contains(t, []string{"a:9000", "b:9000", "c:9000"}, selectFrom(t, rs))
+
+	_, err := newReplicaSet([]string{"a"}, []ReplicaClient{a}, "fastest", 0, logrus.New())
+	require.Error(t, err)
+}
+
+func TestReplicaSet_RetriesSelectOnConnectionError(t *testing.T) {
+	down := &fakeReplica{name: "down:9000", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED)}
+	up := &fakeReplica{name: "up:9000"}
+
+	rs := newTestReplicaSet(t, LoadBalancingInOrder, 1, down, up)
+
+	assert.Equal(t, "up:9000", selectFrom(t, rs))
+	assert.False(t, rs.replicas[0].healthy.Load(), "failing replica should be ejected")
+
+	// Ejected replicas are skipped until a health check succeeds again.
+	assert.Equal(t, "up:9000", selectFrom(t, rs))
+	assert.Equal(t, 1, down.calls)
+
+	down.err = nil
+	assert.Equal(t, 2, rs.checkHealth())
+	assert.Equal(t, "down:9000", selectFrom(t, rs))
+}
+
+func TestReplicaSet_ReadmitsWithoutHealthChecks(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	down := &fakeReplica{name: "down:9000", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED)}
+	up := &fakeReplica{name: "up:9000"}
+
+	rs := newTestReplicaSet(t, LoadBalancingInOrder, 1, down, up)
+	rs.readmitAfter = readmitBackoff
+	rs.now = func() time.Time { return now }
+
+	assert.Equal(t, "up:9000", selectFrom(t, rs))
+	assert.False(t, rs.replicas[0].healthy.Load())
+
+	// Within the backoff the ejected replica is skipped.
+	now = now.Add(readmitBackoff / 2)
+	assert.Equal(t, "up:9000", selectFrom(t, rs))
+	assert.Equal(t, 1, down.calls)
+
+	// After it a query tries the replica again; a failure restarts the backoff.
+	now = now.Add(readmitBackoff)
+	assert.Equal(t, "up:9000", selectFrom(t, rs))
+	assert.Equal(t, 2, down.calls)
+	assert.Equal(t, "up:9000", selectFrom(t, rs))
+	assert.Equal(t, 2, down.calls)
+
+	// A success readmits it.
+	down.err = nil
+	now = now.Add(readmitBackoff)
+	assert.Equal(t, "down:9000", selectFrom(t, rs))
+	assert.True(t, rs.replicas[0].healthy.Load())
+}
+
+func TestReplicaSet_NoRetry(t *testing.T) {
+	t.Run("writes are not retried", func(t *testing.T) {
+		down := &fakeReplica{name: "down:9000", err: fmt.Errorf("write: %w", syscall.ECONNRESET)}
+		up := &fakeReplica{name: "up:9000"}
+
+		rs := newTestReplicaSet(t, LoadBalancingInOrder, 1, down, up)
+
+		require.Error(t, rs.Exec(context.Background(), "INSERT INTO t VALUES (1)"))
+		assert.Equal(t, 0, up.calls)
+	})
+
+	t.Run("server errors are not retried", func(t *testing.T) {
+		bad := &fakeReplica{name: "a:9000", err: &clickhouse.Exception{Code: 60, Message: "table does not exist"}}
+		up := &fakeReplica{name: "b:9000"}
+
+		rs := newTestReplicaSet(t, LoadBalancingInOrder, 1, bad, up)
+
+		require.Error(t, rs.Select(context.Background(), &[]string{}, "SELECT * FROM missing"))
+		assert.Equal(t, 0, up.calls)
+		assert.True(t, rs.replicas[0].healthy.Load())
+	})
+}
+
+func TestReplicaSet_AllEjected(t *testing.T) {
+	a := &fakeReplica{name: "a:9000", pingErr: errors.New("down")}
+	b := &fakeReplica{name: "b:9000", pingErr: errors.New("down")}
+
+	rs := newTestReplicaSet(t, LoadBalancingInOrder, 0, a, b)
+	assert.Equal(t, 0, rs.checkHealth())
+
+	// With every replica ejected queries are still attempted rather than failing fast.
+	assert.Equal(t, "a:9000", selectFrom(t, rs))
+	require.Error(t, rs.Ping(context.Background()))
+}
+
+func TestIsConnectionError(t *testing.T) {
+	assert.True(t, IsConnectionError(fmt.Errorf("read: %w", syscall.ECONNRESET)))
+	assert.True(t, IsConnectionError(clickhouse.ErrAcquireConnTimeout))
+	assert.False(t, IsConnectionError(nil))
+	assert.False(t, IsConnectionError(context.Canceled))
+	assert.False(t, IsConnectionError(&clickhouse.Exception{Code: 62}))
+	assert.False(t, IsConnectionError(errors.New("scan error")))
+}
+
+func TestReplicaHosts(t *testing.T) {
+	hosts, err := replicaHosts(&config.ClickHouseConfig{DSN: "clickhouse://u:p@ch-1:9000,ch-2:9000"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ch-1:9000", "ch-2:9000"}, hosts)
+
+	hosts, err = replicaHosts(&config.ClickHouseConfig{DSN: "clickhouse://u:p@ch-1:9000", Hosts: []string{"a:9000", "b:9000"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a:9000", "b:9000"}, hosts)
+
+	replicaCfg := replicaConfig(&config.ClickHouseConfig{DSN: "clickhouse://u:p@ch-1:9000,ch-2:9000/db"}, "ch-2:9000")
+	assert.Equal(t, "clickhouse://u:p@ch-2:9000/db", replicaCfg.DSN)
+}
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// Profile event names reported by ClickHouse for query memory usage.
+const (
+	profileEventMemoryPeak  = "MemoryTrackerPeakUsage"
+	profileEventMemoryUsage = "MemoryTrackerUsage"
+)
+
+// QueryStats is a snapshot of ClickHouse progress and profile information.
+// Progress and profile packets are only sent over the native protocol.
+type QueryStats struct {
+	QueryID         string
+	ReadRows        uint64
+	ReadBytes       uint64
+	TotalRowsToRead uint64
+	ResultRows      uint64
+	ResultBytes     uint64
+	PeakMemoryUsage int64
+	Elapsed         time.Duration
+	Queries         int
+}
+
+// StatsCollector accumulates QueryStats from driver callbacks.
+// It is safe for concurrent use, as the driver reports progress from its reader goroutine.
+type StatsCollector struct {
+	mu    sync.Mutex
+	stats QueryStats
+}
+
+// NewStatsCollector creates an empty StatsCollector.
+func NewStatsCollector() *StatsCollector {
+	return &StatsCollector{}
+}
+
+// WithStatsCollector returns a context whose queries report their statistics to the collector.
+// Several collectors may be attached, e.g. one per query for tracing and one per request for headers.
+func WithStatsCollector(ctx context.Context, collector *StatsCollector) context.Context {
+	opts := optionsFromContext(ctx)
+
+	collectors := make([]*StatsCollector, 0, len(opts.collectors)+1)
+	collectors = append(collectors, opts.collectors...)
+	opts.collectors = append(collectors, collector)
+
+	return context.WithValue(ctx, queryOptionsKey{}, opts)
+}
+
+// Stats returns a snapshot of the statistics collected so far.
+func (c *StatsCollector) Stats() QueryStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.stats
+}
+
+// start records the beginning of a query with the given ID.
+func (c *StatsCollector) start(queryID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.QueryID = queryID
+	c.stats.Queries++
+}
+
+// progress adds a progress packet; ClickHouse sends these as deltas.
+func (c *StatsCollector) progress(p *clickhouse.Progress) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.ReadRows += p.Rows
+	c.stats.ReadBytes += p.Bytes
+	c.stats.TotalRowsToRead += p.TotalRows
+	c.stats.Elapsed += p.Elapsed
+}
+
+// profileInfo adds the result size reported at the end of a query.
+func (c *StatsCollector) profileInfo(p *clickhouse.ProfileInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.ResultRows += p.Rows
+	c.stats.ResultBytes += p.Bytes
+}
+
+// profileEvents tracks the peak memory usage reported by the server.
+func (c *StatsCollector) profileEvents(events []clickhouse.ProfileEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, event := range events {
+		if event.Name != profileEventMemoryPeak && event.Name != profileEventMemoryUsage {
+			continue
+		}
+
+		if event.Value > c.stats.PeakMemoryUsage {
+			c.stats.PeakMemoryUsage = event.Value
+		}
+	}
+}
+
+// statsOptions returns driver options that fan progress and profile callbacks out to the collectors.
+func statsOptions(queryID string, collectors []*StatsCollector) []clickhouse.QueryOption {
+	if len(collectors) == 0 {
+		return nil
+	}
+
+	for _, c := range collectors {
+		c.start(queryID)
+	}
+
+	return []clickhouse.QueryOption{
+		clickhouse.WithProgress(func(p *clickhouse.Progress) {
+			for _, c := range collectors {
+				c.progress(p)
+			}
+		}),
+		clickhouse.WithProfileInfo(func(p *clickhouse.ProfileInfo) {
+			for _, c := range collectors {
+				c.profileInfo(p)
+			}
+		}),
+		clickhouse.WithProfileEvents(func(events []clickhouse.ProfileEvent) {
+			for _, c := range collectors {
+				c.profileEvents(events)
+			}
+		}),
+	}
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsCollector(t *testing.T) {
+	collector := NewStatsCollector()
+
+	collector.start("query-1")
+	collector.progress(&clickhouse.Progress{Rows: 100, Bytes: 1000, TotalRows: 500, Elapsed: 2 * time.Millisecond})
+	collector.progress(&clickhouse.Progress{Rows: 50, Bytes: 500, Elapsed: time.Millisecond})
+	collector.profileInfo(&clickhouse.ProfileInfo{Rows: 10, Bytes: 80})
+	collector.profileEvents([]clickhouse.ProfileEvent{
+		{Name: "SelectedRows", Value: 150},
+		{Name: profileEventMemoryUsage, Value: 2048},
+		{Name: profileEventMemoryPeak, Value: 4096},
+	})
+
+	assert.Equal(t, QueryStats{
+		QueryID:         "query-1",
+		ReadRows:        150,
+		ReadBytes:       1500,
+		TotalRowsToRead: 500,
+		ResultRows:      10,
+		ResultBytes:     80,
+		PeakMemoryUsage: 4096,
+		Elapsed:         3 * time.Millisecond,
+		Queries:         1,
+	}, collector.Stats())
+}
+
+func TestWithStatsCollector(t *testing.T) {
+	requestCollector := NewStatsCollector()
+	queryCollector := NewStatsCollector()
+
+	parent := WithStatsCollector(context.Background(), requestCollector)
+	child := WithStatsCollector(parent, queryCollector)
+
+	require.Len(t, optionsFromContext(parent).collectors, 1)
+	require.Len(t, optionsFromContext(child).collectors, 2)
+
+	// Starting a query reports to every collector on the context.
+	opts := statsOptions("query-1", optionsFromContext(child).collectors)
+	assert.Len(t, opts, 3)
+	assert.Equal(t, "query-1", requestCollector.Stats().QueryID)
+	assert.Equal(t, "query-1", queryCollector.Stats().QueryID)
+
+	assert.Nil(t, statsOptions("query-2", nil))
+}
+
+func TestWithQueryID(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, QueryIDFromContext(ctx))
+
+	ctx = WithQueryID(ctx, "abc")
+	assert.Equal(t, "abc", QueryIDFromContext(ctx))
+}
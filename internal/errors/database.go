@@ -0,0 +1,101 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"google.golang.org/grpc/codes"
+
+	"github.com/ethpandaops/cbt-api/internal/database"
+)
+
+// exceptionMapping is the API code and client-facing message for a ClickHouse exception code.
+type exceptionMapping struct {
+	name    string
+	code    Code
+	message string
+}
+
+// clickhouseExceptions maps ClickHouse exception codes to API codes. Messages are deliberately
+// generic: the raw exception text can contain SQL, hostnames and internal table names.
+// See https://github.com/ClickHouse/ClickHouse/blob/master/src/Common/ErrorCodes.cpp.
+var clickhouseExceptions = map[int32]exceptionMapping{
+	6:   {"CANNOT_PARSE_TEXT", codes.InvalidArgument, "Invalid filter value"},
+	16:  {"NO_SUCH_COLUMN_IN_TABLE", codes.InvalidArgument, "Unknown column"},
+	27:  {"CANNOT_PARSE_INPUT_ASSERTION_FAILED", codes.InvalidArgument, "Invalid filter value"},
+	41:  {"CANNOT_PARSE_DATETIME", codes.InvalidArgument, "Invalid date/time filter value"},
+	47:  {"UNKNOWN_IDENTIFIER", codes.InvalidArgument, "Unknown column"},
+	53:  {"TYPE_MISMATCH", codes.InvalidArgument, "Filter value does not match the column type"},
+	60:  {"UNKNOWN_TABLE", codes.NotFound, "Table does not exist"},
+	70:  {"CANNOT_CONVERT_TYPE", codes.InvalidArgument, "Filter value does not match the column type"},
+	72:  {"CANNOT_PARSE_NUMBER", codes.InvalidArgument, "Invalid numeric filter value"},
+	81:  {"UNKNOWN_DATABASE", codes.NotFound, "Database does not exist"},
+	158: {"TOO_MANY_ROWS", codes.ResourceExhausted, "Query reads too many rows, narrow the filters"},
+	159: {"TIMEOUT_EXCEEDED", codes.DeadlineExceeded, "Query exceeded the maximum execution time"},
+	160: {"TOO_SLOW", codes.DeadlineExceeded, "Query is estimated to exceed the maximum execution time"},
+	201: {"QUOTA_EXCEEDED", codes.ResourceExhausted, "Database quota exceeded"},
+	202: {"TOO_MANY_SIMULTANEOUS_QUERIES", codes.Unavailable, "Database is busy, retry later"},
+	209: {"SOCKET_TIMEOUT", codes.Unavailable, "Database connection timed out"},
+	210: {"NETWORK_ERROR", codes.Unavailable, "Database is unreachable"},
+	241: {"MEMORY_LIMIT_EXCEEDED", codes.ResourceExhausted, "Query exceeded the memory limit, narrow the filters"},
+	252: {"TOO_MANY_PARTS", codes.Unavailable, "Database is busy, retry later"},
+	307: {"TOO_MANY_BYTES", codes.ResourceExhausted, "Query reads too much data, narrow the filters"},
+	394: {"QUERY_WAS_CANCELLED", codes.Canceled, "Query was cancelled"},
+	396: {"TOO_MANY_ROWS_OR_BYTES", codes.ResourceExhausted, "Query reads too much data, narrow the filters"},
+}
+
+// FromError converts errors from the database layer into a Status with a sanitized message:
+// ClickHouse exceptions (with the original code in ErrorInfo metadata), circuit breaker
+// rejections, connection failures and context errors. A *Status is returned as is.
+// It returns nil for errors it does not recognize.
+func FromError(err error) *Status {
+	if err == nil {
+		return nil
+	}
+
+	var status *Status
+	if errors.As(err, &status) {
+		return status
+	}
+
+	var circuitErr *database.CircuitOpenError
+	if errors.As(err, &circuitErr) {
+		return Unavailable("Database temporarily unavailable").WithRetryInfo(circuitErr.RetryAfter)
+	}
+
+	var exception *clickhouse.Exception
+	if errors.As(err, &exception) {
+		return fromException(exception)
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return New(codes.DeadlineExceeded, "Query timed out")
+	case errors.Is(err, context.Canceled):
+		return New(codes.Canceled, "Request cancelled")
+	case database.IsConnectionError(err):
+		return Unavailable("Database is unreachable")
+	}
+
+	return nil
+}
+
+// fromException maps a ClickHouse exception to a Status.
+func fromException(exception *clickhouse.Exception) *Status {
+	mapping, ok := clickhouseExceptions[exception.Code]
+	if !ok {
+		mapping = exceptionMapping{code: codes.Internal, message: "Database query failed"}
+	}
+
+	metadata := map[string]string{
+		"clickhouse_code": strconv.Itoa(int(exception.Code)),
+	}
+
+	if mapping.name != "" {
+		metadata["clickhouse_error"] = mapping.name
+	}
+
+	return New(mapping.code, mapping.message).WithMetadata(metadata)
+}
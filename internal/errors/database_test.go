@@ -0,0 +1,128 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/ethpandaops/cbt-api/internal/database"
+)
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name             string
+		err              error
+		expectedCode     Code
+		expectedMessage  string
+		expectedMetadata map[string]string
+	}{
+		{
+			name:             "timeout exceeded",
+			err:              &clickhouse.Exception{Code: 159, Message: "Timeout exceeded: elapsed 60.1 seconds, maximum: 60"},
+			expectedCode:     codes.DeadlineExceeded,
+			expectedMessage:  "Query exceeded the maximum execution time",
+			expectedMetadata: map[string]string{"clickhouse_code": "159", "clickhouse_error": "TIMEOUT_EXCEEDED"},
+		},
+		{
+			name:             "memory limit exceeded",
+			err:              fmt.Errorf("query: %w", &clickhouse.Exception{Code: 241, Message: "Memory limit (total) exceeded: would use 10.00 GiB"}),
+			expectedCode:     codes.ResourceExhausted,
+			expectedMessage:  "Query exceeded the memory limit, narrow the filters",
+			expectedMetadata: map[string]string{"clickhouse_code": "241", "clickhouse_error": "MEMORY_LIMIT_EXCEEDED"},
+		},
+		{
+			name:             "too many simultaneous queries",
+			err:              &clickhouse.Exception{Code: 202, Message: "Too many simultaneous queries. Maximum: 100"},
+			expectedCode:     codes.Unavailable,
+			expectedMessage:  "Database is busy, retry later",
+			expectedMetadata: map[string]string{"clickhouse_code": "202", "clickhouse_error": "TOO_MANY_SIMULTANEOUS_QUERIES"},
+		},
+		{
+			name:             "unknown table",
+			err:              &clickhouse.Exception{Code: 60, Message: "Table mainnet.fct_block_local does not exist"},
+			expectedCode:     codes.NotFound,
+			expectedMessage:  "Table does not exist",
+			expectedMetadata: map[string]string{"clickhouse_code": "60", "clickhouse_error": "UNKNOWN_TABLE"},
+		},
+		{
+			name:             "unmapped exception",
+			err:              &clickhouse.Exception{Code: 999, Message: "Keeper exception at host ch-1.internal"},
+			expectedCode:     codes.Internal,
+			expectedMessage:  "Database query failed",
+			expectedMetadata: map[string]string{"clickhouse_code": "999"},
+		},
+		{
+			name:            "deadline exceeded",
+			err:             fmt.Errorf("read: %w", context.DeadlineExceeded),
+			expectedCode:    codes.DeadlineExceeded,
+			expectedMessage: "Query timed out",
+		},
+		{
+			name:            "connection refused",
+			err:             fmt.Errorf("dial tcp 10.0.0.1:9000: %w", syscall.ECONNREFUSED),
+			expectedCode:    codes.Unavailable,
+			expectedMessage: "Database is unreachable",
+		},
+		{
+			name:            "status passthrough",
+			err:             BadRequest("invalid filter"),
+			expectedCode:    codes.InvalidArgument,
+			expectedMessage: "invalid filter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := FromError(tt.err)
+			require.NotNil(t, status)
+
+			assert.Equal(t, tt.expectedCode, status.Code)
+			assert.Equal(t, tt.expectedMessage, status.Message)
+
+			if tt.expectedMetadata == nil {
+				return
+			}
+
+			require.Len(t, status.Details, 1)
+			assert.Equal(t, "type.googleapis.com/ErrorInfo", status.Details[0]["@type"])
+			assert.Equal(t, tt.expectedMetadata, status.Details[0]["metadata"])
+		})
+	}
+
+	assert.Nil(t, FromError(nil))
+	assert.Nil(t, FromError(errors.New("scan error")))
+}
+
+func TestFromError_CircuitOpen(t *testing.T) {
+	status := FromError(&database.CircuitOpenError{RetryAfter: 12 * time.Second})
+	require.NotNil(t, status)
+
+	rec := httptest.NewRecorder()
+	status.WriteJSON(rec)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "12", rec.Header().Get("Retry-After"))
+}
+
+func TestDefaultErrorHandler_SanitizesDatabaseErrors(t *testing.T) {
+	handler := DefaultErrorHandler(logrus.New())
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/fct_block", nil),
+		&clickhouse.Exception{Code: 241, Message: "Memory limit exceeded at host ch-1.internal"})
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "ch-1.internal")
+	assert.Contains(t, rec.Body.String(), "MEMORY_LIMIT_EXCEEDED")
+}
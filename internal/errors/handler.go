@@ -29,11 +29,12 @@ func DefaultErrorHandler(logger logrus.FieldLogger) func(w http.ResponseWriter,
 			return
 		}
 
-		// If the error is already a Status, write it directly
-		if status, ok := err.(*Status); ok {
+		// Statuses are written directly; database errors map to sanitized statuses
+		if status := FromError(err); status != nil {
 			logger.WithFields(logrus.Fields{
 				"code":    status.Code,
 				"message": status.Message,
+				"error":   err,
 				"path":    r.URL.Path,
 				"method":  r.Method,
 			}).Warn("request error")
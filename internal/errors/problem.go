@@ -0,0 +1,179 @@
+package errors
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details.
+const ProblemContentType = "application/problem+json"
+
+// problemTypeBlank is the problem type used when the HTTP status code is all the client needs.
+const problemTypeBlank = "about:blank"
+
+// Problem is an RFC 9457 problem details object. Extensions are written as top-level members.
+type Problem struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]any
+}
+
+// MarshalJSON writes the standard members followed by the extension members.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any, len(p.Extensions)+5)
+
+	for k, v := range p.Extensions {
+		out[k] = v
+	}
+
+	out["type"] = p.Type
+	out["title"] = p.Title
+	out["status"] = p.Status
+
+	if p.Detail != "" {
+		out["detail"] = p.Detail
+	}
+
+	if p.Instance != "" {
+		out["instance"] = p.Instance
+	}
+
+	return json.Marshal(out)
+}
+
+// Problem converts the status to problem details. The gRPC code is kept as the "code" extension
+// and each detail becomes an extension named after its @type (ErrorInfo as "errorInfo", RequestInfo
+// as "requestInfo", ...). If several details share a type, the first one wins.
+func (s *Status) Problem(instance string) *Problem {
+	httpStatus := HTTPStatus(s.Code)
+
+	p := &Problem{
+		Type:     problemTypeBlank,
+		Title:    http.StatusText(httpStatus),
+		Status:   httpStatus,
+		Detail:   s.Message,
+		Instance: instance,
+		Extensions: map[string]any{
+			"code": s.Code,
+		},
+	}
+
+	for _, detail := range s.Details {
+		detailType, _ := detail["@type"].(string)
+
+		name := problemExtensionName(detailType)
+		if name == "" {
+			continue
+		}
+
+		if _, exists := p.Extensions[name]; exists {
+			continue
+		}
+
+		member := make(map[string]any, len(detail))
+
+		for k, v := range detail {
+			if k != "@type" {
+				member[k] = v
+			}
+		}
+
+		p.Extensions[name] = member
+	}
+
+	return p
+}
+
+// problemExtensionName derives an extension member name from a detail @type, e.g.
+// "type.googleapis.com/ErrorInfo" becomes "errorInfo".
+func problemExtensionName(detailType string) string {
+	name := detailType[strings.LastIndex(detailType, "/")+1:]
+	name = name[strings.LastIndex(name, ".")+1:]
+
+	if name == "" {
+		return ""
+	}
+
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// AcceptsProblem reports whether an Accept header prefers problem details over application/json.
+// The Status format stays the default: problem details are only chosen when listed explicitly
+// with a quality at least as high as application/json.
+func AcceptsProblem(accept string) bool {
+	if accept == "" {
+		return false
+	}
+
+	problemQ, jsonQ := -1.0, -1.0
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+
+		if raw, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+				q = parsed
+			}
+		}
+
+		switch mediaType {
+		case ProblemContentType:
+			problemQ = max(problemQ, q)
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+
+	return problemQ > 0 && problemQ >= jsonQ
+}
+
+// problemWriter marks a response whose errors are written as problem details.
+type problemWriter struct {
+	http.ResponseWriter
+	instance string
+}
+
+// Unwrap returns the wrapped ResponseWriter.
+func (w *problemWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// NegotiateFormat returns a ResponseWriter on which WriteJSON writes problem details if the
+// request's Accept header asks for them, and w unchanged otherwise. Middleware wrapping the
+// returned writer must implement Unwrap() http.ResponseWriter for the choice to be found.
+func NegotiateFormat(w http.ResponseWriter, r *http.Request) http.ResponseWriter {
+	if !AcceptsProblem(r.Header.Get("Accept")) {
+		return w
+	}
+
+	return &problemWriter{ResponseWriter: w, instance: r.URL.Path}
+}
+
+// problemInstance returns the problem instance if the response was negotiated to problem details.
+func problemInstance(w http.ResponseWriter) (string, bool) {
+	for w != nil {
+		if pw, ok := w.(*problemWriter); ok {
+			return pw.instance, true
+		}
+
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return "", false
+		}
+
+		w = unwrapper.Unwrap()
+	}
+
+	return "", false
+}
@@ -0,0 +1,102 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/ethpandaops/cbt-api/internal/requestid"
+)
+
+func TestAcceptsProblem(t *testing.T) {
+	tests := []struct {
+		accept   string
+		expected bool
+	}{
+		{"", false},
+		{"*/*", false},
+		{"application/json", false},
+		{"application/problem+json", true},
+		{"application/problem+json, application/json", true},
+		{"application/json, application/problem+json;q=0.9", false},
+		{"application/json;q=0.5, application/problem+json", true},
+		{"application/problem+json;q=0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			assert.Equal(t, tt.expected, AcceptsProblem(tt.accept))
+		})
+	}
+}
+
+func TestStatus_WriteJSON_Problem(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/fct_block/42", nil)
+	req.Header.Set("Accept", ProblemContentType)
+
+	rec := httptest.NewRecorder()
+	rec.Header().Set(requestid.Header, "req-123")
+
+	NotFound("resource not found: fct_block with slot=42").
+		WithMetadata(map[string]string{"table": "fct_block"}).
+		WriteJSON(NegotiateFormat(rec, req))
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+
+	var problem map[string]any
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+	assert.Equal(t, "about:blank", problem["type"])
+	assert.Equal(t, "Not Found", problem["title"])
+	assert.InDelta(t, http.StatusNotFound, problem["status"], 0)
+	assert.Equal(t, "resource not found: fct_block with slot=42", problem["detail"])
+	assert.Equal(t, "/api/v1/fct_block/42", problem["instance"])
+	assert.InDelta(t, float64(codes.NotFound), problem["code"], 0)
+	assert.Equal(t, map[string]any{"metadata": map[string]any{"table": "fct_block"}}, problem["errorInfo"])
+	assert.Equal(t, map[string]any{"requestId": "req-123"}, problem["requestInfo"])
+}
+
+func TestStatus_WriteJSON_DefaultFormat(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/fct_block/42", nil)
+	req.Header.Set("Accept", "application/json")
+
+	rec := httptest.NewRecorder()
+
+	NotFound("missing").WriteJSON(NegotiateFormat(rec, req))
+
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var status Status
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, codes.NotFound, status.Code)
+	assert.Equal(t, "missing", status.Message)
+}
+
+// unwrappingWriter stands in for middleware wrapping the negotiated writer.
+type unwrappingWriter struct {
+	http.ResponseWriter
+}
+
+func (w *unwrappingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func TestStatus_WriteJSON_ProblemThroughWrappers(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	req.Header.Set("Accept", ProblemContentType)
+
+	rec := httptest.NewRecorder()
+
+	Internal("boom").WriteJSON(&unwrappingWriter{ResponseWriter: NegotiateFormat(rec, req)})
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+}
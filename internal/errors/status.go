@@ -4,8 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"google.golang.org/grpc/codes"
+
+	"github.com/ethpandaops/cbt-api/internal/requestid"
 )
 
 // Code is an alias for gRPC status codes.
@@ -69,6 +73,9 @@ type Status struct {
 	Message string `json:"message"`
 	// Details is a list of messages that carry error details.
 	Details []Detail `json:"details,omitempty"`
+
+	// retryAfter is sent as the Retry-After header when set.
+	retryAfter time.Duration
 }
 
 // Error implements the error interface.
@@ -97,17 +104,109 @@ func (s *Status) WithMetadata(metadata map[string]string) *Status {
 	return s.WithDetail(detail)
 }
 
+// FieldViolation describes a single invalid request field, as in google.rpc.BadRequest.
+type FieldViolation struct {
+	// Field is the name of the invalid field, e.g. a query parameter.
+	Field string `json:"field"`
+	// Description explains why the field is invalid.
+	Description string `json:"description"`
+	// Reason is a machine-readable cause, e.g. "UNKNOWN_PARAMETER" or "INVALID_TYPE".
+	Reason string `json:"reason,omitempty"`
+	// Value is the rejected value.
+	Value string `json:"value,omitempty"`
+	// Expected is the expected type, range or pattern.
+	Expected string `json:"expected,omitempty"`
+}
+
+// WithFieldViolations adds a detail with BadRequest type listing every invalid field.
+func (s *Status) WithFieldViolations(violations []FieldViolation) *Status {
+	detail := Detail{
+		"@type":           "type.googleapis.com/BadRequest",
+		"fieldViolations": violations,
+	}
+
+	return s.WithDetail(detail)
+}
+
+// WithRequestInfo adds a detail with RequestInfo type carrying the request ID.
+func (s *Status) WithRequestInfo(requestID string) *Status {
+	detail := Detail{
+		"@type":     requestInfoType,
+		"requestId": requestID,
+	}
+
+	return s.WithDetail(detail)
+}
+
+// WithRetryInfo adds a detail with RetryInfo type and sets the Retry-After header
+// written with the status.
+func (s *Status) WithRetryInfo(delay time.Duration) *Status {
+	delay = max(delay, time.Second)
+	s.retryAfter = delay
+
+	detail := Detail{
+		"@type":      "type.googleapis.com/RetryInfo",
+		"retryDelay": fmt.Sprintf("%ds", retryAfterSeconds(delay)),
+	}
+
+	return s.WithDetail(detail)
+}
+
+// retryAfterSeconds rounds a delay up to whole seconds.
+func retryAfterSeconds(delay time.Duration) int64 {
+	return int64((delay + time.Second - 1) / time.Second)
+}
+
+// requestInfoType is the @type of RequestInfo details.
+const requestInfoType = "type.googleapis.com/RequestInfo"
+
 // WriteJSON writes the status as JSON to the http.ResponseWriter.
+// If the response carries an X-Request-ID header and the status has no RequestInfo detail yet,
+// one is added so clients can quote the ID when reporting errors. If the writer was returned by
+// NegotiateFormat for a client accepting application/problem+json, RFC 9457 problem details are
+// written instead.
 func (s *Status) WriteJSON(w http.ResponseWriter) {
-	w.Header().Set("Content-Type", "application/json")
+	out := s
+
+	if id := w.Header().Get(requestid.Header); id != "" && !s.hasDetail(requestInfoType) {
+		out = &Status{Code: s.Code, Message: s.Message, Details: append([]Detail(nil), s.Details...)}
+		out.WithRequestInfo(id)
+	}
+
+	if s.retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.FormatInt(retryAfterSeconds(s.retryAfter), 10))
+	}
+
+	var body any = out
+
+	contentType := "application/json"
+
+	if instance, ok := problemInstance(w); ok {
+		body = out.Problem(instance)
+		contentType = ProblemContentType
+	}
+
+	w.Header().Add("Vary", "Accept")
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(HTTPStatus(s.Code))
 
-	if err := json.NewEncoder(w).Encode(s); err != nil {
+	if err := json.NewEncoder(w).Encode(body); err != nil {
 		// Fallback to plain text if JSON encoding fails
 		http.Error(w, s.Message, HTTPStatus(s.Code))
 	}
 }
 
+// hasDetail reports whether the status already carries a detail of the given @type.
+func (s *Status) hasDetail(detailType string) bool {
+	for _, d := range s.Details {
+		if d["@type"] == detailType {
+			return true
+		}
+	}
+
+	return false
+}
+
 // New creates a new Status with the given code and message.
 func New(code Code, message string) *Status {
 	return &Status{
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -66,6 +67,43 @@ func TestStatus_WriteJSON(t *testing.T) {
 	}
 }
 
+func TestStatus_WriteJSON_RequestInfo(t *testing.T) {
+	status := NotFound("resource not found")
+
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-Request-ID", "req-123")
+	status.WriteJSON(rec)
+
+	var result Status
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	require.Len(t, result.Details, 1)
+	assert.Equal(t, "type.googleapis.com/RequestInfo", result.Details[0]["@type"])
+	assert.Equal(t, "req-123", result.Details[0]["requestId"])
+
+	// The shared status is not mutated.
+	assert.Empty(t, status.Details)
+
+	// Without a request ID header no detail is added.
+	rec = httptest.NewRecorder()
+	status.WriteJSON(rec)
+	assert.NotContains(t, rec.Body.String(), "RequestInfo")
+}
+
+func TestStatus_WithRetryInfo(t *testing.T) {
+	status := Unavailable("database temporarily unavailable").WithRetryInfo(2500 * time.Millisecond)
+
+	require.Len(t, status.Details, 1)
+	assert.Equal(t, "type.googleapis.com/RetryInfo", status.Details[0]["@type"])
+	assert.Equal(t, "3s", status.Details[0]["retryDelay"])
+
+	rec := httptest.NewRecorder()
+	status.WriteJSON(rec)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "3", rec.Header().Get("Retry-After"))
+}
+
 func TestStatus_WithDetail(t *testing.T) {
 	status := BadRequest("test error").WithDetail(Detail{
 		"@type":  "type.googleapis.com/ErrorInfo",
@@ -90,6 +128,27 @@ func TestStatus_WithMetadata(t *testing.T) {
 	assert.Equal(t, metadata, status.Details[0]["metadata"])
 }
 
+func TestStatus_WithFieldViolations(t *testing.T) {
+	violations := []FieldViolation{
+		{Field: "slot_gtw", Description: "unknown query parameter", Reason: "UNKNOWN_PARAMETER"},
+		{Field: "slot_eq", Description: "must be a number", Reason: "INVALID_TYPE", Value: "abc", Expected: "uint32"},
+	}
+
+	status := BadRequest("invalid parameters").WithFieldViolations(violations)
+
+	require.Len(t, status.Details, 1)
+	assert.Equal(t, "type.googleapis.com/BadRequest", status.Details[0]["@type"])
+
+	body, err := json.Marshal(status)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"code":3,"message":"invalid parameters","details":[{
+		"@type":"type.googleapis.com/BadRequest",
+		"fieldViolations":[
+			{"field":"slot_gtw","description":"unknown query parameter","reason":"UNKNOWN_PARAMETER"},
+			{"field":"slot_eq","description":"must be a number","reason":"INVALID_TYPE","value":"abc","expected":"uint32"}
+		]}]}`, string(body))
+}
+
 func TestHTTPStatus(t *testing.T) {
 	tests := []struct {
 		code     Code
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// BigIntString is a 64-bit integer column encoded as a JSON string, as JavaScript numbers lose
+// precision above 2^53. openapi-preprocess maps columns listed in api.bigint_to_string_fields
+// to it with x-go-type. A NULL or unset value encodes as null.
+type BigIntString struct {
+	value string
+	valid bool
+}
+
+// NewBigIntString returns a BigIntString holding the decimal value s.
+func NewBigIntString(s string) BigIntString {
+	return BigIntString{value: s, valid: true}
+}
+
+// String returns the decimal value, or "" when null.
+func (b BigIntString) String() string {
+	return b.value
+}
+
+// Valid reports whether the value is not null.
+func (b BigIntString) Valid() bool {
+	return b.valid
+}
+
+// Scan implements sql.Scanner for ClickHouse integer columns, and for columns the query builder
+// already converted with toString.
+func (b *BigIntString) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*b = BigIntString{}
+	case uint64:
+		*b = NewBigIntString(strconv.FormatUint(v, 10))
+	case int64:
+		*b = NewBigIntString(strconv.FormatInt(v, 10))
+	case uint32:
+		*b = NewBigIntString(strconv.FormatUint(uint64(v), 10))
+	case int32:
+		*b = NewBigIntString(strconv.FormatInt(int64(v), 10))
+	case string:
+		*b = NewBigIntString(v)
+	case []byte:
+		*b = NewBigIntString(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into BigIntString", src)
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the value as a JSON string, or null.
+func (b BigIntString) MarshalJSON() ([]byte, error) {
+	if !b.valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(b.value)
+}
+
+// UnmarshalJSON accepts a JSON string, number or null.
+func (b *BigIntString) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*b = BigIntString{}
+
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = NewBigIntString(s)
+
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("BigIntString must be a string, number or null: %w", err)
+	}
+
+	*b = NewBigIntString(n.String())
+
+	return nil
+}
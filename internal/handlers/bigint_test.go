@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBigIntString_Scan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  any
+		want string
+	}{
+		{name: "uint64 above 2^53", src: uint64(math.MaxUint64), want: `"18446744073709551615"`},
+		{name: "negative int64", src: int64(math.MinInt64), want: `"-9223372036854775808"`},
+		{name: "string from toString", src: "1000000000000000000", want: `"1000000000000000000"`},
+		{name: "null", src: nil, want: `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b BigIntString
+			require.NoError(t, b.Scan(tt.src))
+
+			got, err := json.Marshal(b)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	var b BigIntString
+	assert.Error(t, b.Scan(1.5))
+}
+
+func TestBigIntString_JSON(t *testing.T) {
+	var item struct {
+		Value   BigIntString `json:"value,omitempty"`
+		Missing BigIntString `json:"missing,omitempty"`
+		Number  BigIntString `json:"number"`
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"value":"0","number":12345678901234567890}`), &item))
+	assert.Equal(t, "0", item.Value.String())
+	assert.True(t, item.Value.Valid())
+	assert.False(t, item.Missing.Valid())
+	assert.Equal(t, "12345678901234567890", item.Number.String())
+
+	// A zero value is still encoded, as the field is a struct
+	got, err := json.Marshal(item)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value":"0","missing":null,"number":"12345678901234567890"}`, string(got))
+}
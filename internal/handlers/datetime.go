@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DateTimeFormats describes the values accepted by DateTime filter parameters, for error messages.
+const DateTimeFormats = "Unix time, RFC 3339 (2024-01-01T00:00:00Z) or now[+-N<unit>][/<unit>] (now-1h, now-7d/d)"
+
+// relativeTimePattern matches relative expressions such as "now", "now-1h", "now-1d+12h" or "now-7d/d".
+var relativeTimePattern = regexp.MustCompile(`^now((?:[+-]\d+[smhdwMy])*)(?:/([smhdwMy]))?$`)
+
+// relativeTimeOffset matches a single offset within a relative expression.
+var relativeTimeOffset = regexp.MustCompile(`([+-])(\d+)([smhdwMy])`)
+
+// ErrInvalidDateTime is returned by ParseDateTime for values that are not Unix time, RFC 3339
+// or a relative expression.
+var ErrInvalidDateTime = errors.New("must be " + DateTimeFormats)
+
+// ParseDateTime parses a DateTime filter value into the integer the column is compared against.
+// Integers are returned unchanged, as Unix time in the column's unit; RFC 3339 timestamps and
+// relative expressions (resolved against now, in UTC) are converted to Unix time with the given
+// number of fractional digits, as for DateTime64(precision). format is the integer format of the
+// filter ("uint32", "uint64" or "int64") and bounds the result.
+func ParseDateTime(value, format string, precision int, now time.Time) (int64, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		t, timeErr := parseTimeValue(value, now)
+		if timeErr != nil {
+			return 0, timeErr
+		}
+
+		n = unixWithPrecision(t, precision)
+	}
+
+	switch format {
+	case "uint32":
+		if n < 0 || n > math.MaxUint32 {
+			return 0, fmt.Errorf("must be between 1970-01-01T00:00:00Z and %s",
+				time.Unix(math.MaxUint32, 0).UTC().Format(time.RFC3339))
+		}
+	case "uint64":
+		if n < 0 {
+			return 0, errors.New("must not be before 1970-01-01T00:00:00Z")
+		}
+	}
+
+	return n, nil
+}
+
+// DateTimeParam converts a DateTime filter parameter to the integer type of its filter, for the
+// generated handlers. QueryParameterValidation has already rewritten the value to Unix time, so
+// relative expressions are not resolved again; a value that still fails to parse is an error
+// rather than a dropped filter.
+func DateTimeParam[T uint32 | uint64 | int64](name string, value *string, format string, precision int) (*T, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	n, err := ParseDateTime(*value, format, precision, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("parameter '%s' %w", name, err)
+	}
+
+	v := T(n)
+
+	return &v, nil
+}
+
+// parseTimeValue parses an RFC 3339 timestamp or a relative expression.
+func parseTimeValue(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+
+	matches := relativeTimePattern.FindStringSubmatch(value)
+	if matches == nil {
+		return time.Time{}, ErrInvalidDateTime
+	}
+
+	t := now.UTC()
+
+	for _, offset := range relativeTimeOffset.FindAllStringSubmatch(matches[1], -1) {
+		amount, err := strconv.Atoi(offset[2])
+		if err != nil {
+			return time.Time{}, ErrInvalidDateTime
+		}
+
+		if offset[1] == "-" {
+			amount = -amount
+		}
+
+		t = addTimeUnit(t, amount, offset[3])
+	}
+
+	if matches[2] != "" {
+		t = truncateTimeUnit(t, matches[2])
+	}
+
+	return t, nil
+}
+
+// addTimeUnit adds amount of the given unit to t. Months and years use calendar arithmetic.
+func addTimeUnit(t time.Time, amount int, unit string) time.Time {
+	switch unit {
+	case "s":
+		return t.Add(time.Duration(amount) * time.Second)
+	case "m":
+		return t.Add(time.Duration(amount) * time.Minute)
+	case "h":
+		return t.Add(time.Duration(amount) * time.Hour)
+	case "d":
+		return t.AddDate(0, 0, amount)
+	case "w":
+		return t.AddDate(0, 0, 7*amount)
+	case "M":
+		return t.AddDate(0, amount, 0)
+	default: // "y"
+		return t.AddDate(amount, 0, 0)
+	}
+}
+
+// truncateTimeUnit rounds t down to the start of the given unit. Weeks start on Monday.
+func truncateTimeUnit(t time.Time, unit string) time.Time {
+	switch unit {
+	case "s":
+		return t.Truncate(time.Second)
+	case "m":
+		return t.Truncate(time.Minute)
+	case "h":
+		return t.Truncate(time.Hour)
+	}
+
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch unit {
+	case "d":
+		return day
+	case "w":
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case "M":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default: // "y"
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// unixWithPrecision returns t as Unix time with the given number of fractional digits (0-9).
+func unixWithPrecision(t time.Time, precision int) int64 {
+	precision = min(max(precision, 0), 9)
+
+	n := t.Unix()
+	fraction := int64(t.Nanosecond())
+
+	for range precision {
+		n *= 10
+	}
+
+	for range 9 - precision {
+		fraction /= 10
+	}
+
+	return n + fraction
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDateTime(t *testing.T) {
+	// Wednesday
+	now := time.Date(2024, 3, 13, 15, 4, 5, 123456789, time.UTC)
+
+	tests := []struct {
+		name      string
+		value     string
+		format    string
+		precision int
+		want      int64
+		wantErr   bool
+	}{
+		{name: "unix time", value: "1704067200", format: "uint32", want: 1704067200},
+		{name: "rfc 3339", value: "2024-01-01T00:00:00Z", format: "uint32", want: 1704067200},
+		{name: "rfc 3339 with offset", value: "2024-01-01T02:00:00+02:00", format: "uint32", want: 1704067200},
+		{name: "now", value: "now", format: "uint32", want: now.Unix()},
+		{name: "now minus one hour", value: "now-1h", format: "uint32", want: now.Add(-time.Hour).Unix()},
+		{name: "several offsets", value: "now-1d+12h", format: "uint32", want: now.Add(-12 * time.Hour).Unix()},
+		{name: "rounded to day", value: "now-7d/d", format: "uint32", want: time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC).Unix()},
+		{name: "rounded to week", value: "now/w", format: "uint32", want: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC).Unix()},
+		{name: "rounded to month", value: "now-1M/M", format: "uint32", want: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC).Unix()},
+		{name: "datetime64 milliseconds", value: "2024-01-01T00:00:00.5Z", format: "int64", precision: 3, want: 1704067200500},
+		{name: "datetime64 now", value: "now", format: "int64", precision: 3, want: now.UnixMilli()},
+		{name: "before epoch", value: "1969-12-31T23:59:59Z", format: "uint32", wantErr: true},
+		{name: "after uint32", value: "2106-02-07T06:28:16Z", format: "uint32", wantErr: true},
+		{name: "negative int64", value: "1969-12-31T23:59:59Z", format: "int64", want: -1},
+		{name: "date only", value: "2024-01-01", format: "uint32", wantErr: true},
+		{name: "unknown unit", value: "now-1q", format: "uint32", wantErr: true},
+		{name: "garbage", value: "yesterday", format: "uint32", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDateTime(tt.value, tt.format, tt.precision, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDateTimeParam(t *testing.T) {
+	got, err := DateTimeParam[uint32]("slot_start_date_time_gte", nil, "uint32", 0)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	value := "2024-01-01T00:00:00Z"
+	got, err = DateTimeParam[uint32]("slot_start_date_time_gte", &value, "uint32", 0)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, uint32(1704067200), *got)
+
+	// An unencoded + arrives as a space
+	invalid := "now 1h"
+	got, err = DateTimeParam[uint32]("slot_start_date_time_gte", &invalid, "uint32", 0)
+	require.ErrorIs(t, err, ErrInvalidDateTime)
+	assert.Nil(t, got)
+	assert.Contains(t, err.Error(), "parameter 'slot_start_date_time_gte' must be")
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrUnsupportedListQuery is returned when a List query's LIMIT can't be removed, so a
+// distinct-values query over it would only see a single page.
+var ErrUnsupportedListQuery = errors.New("list query has a LIMIT that can't be removed")
+
+var (
+	// listQueryTailPattern matches the ORDER BY, LIMIT and OFFSET clauses the List query builders
+	// end their queries with.
+	listQueryTailPattern = regexp.MustCompile(`(?is)(\s+ORDER\s+BY\s+[^()?]+?)?(\s+LIMIT\s+\d+(\s*,\s*\d+|\s+OFFSET\s+\d+)?)?\s*$`)
+
+	// limitPattern matches any LIMIT clause left after removing the tail.
+	limitPattern = regexp.MustCompile(`(?i)\bLIMIT\b`)
+
+	// columnNamePattern restricts the columns distinct values can be listed for.
+	columnNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// DistinctQuery wraps the SQL of a List query builder in a query returning the most frequent values
+// of column among the matching rows, most frequent first, with the query's arguments unchanged.
+// Each row holds the value, its number of rows and the number of distinct values.
+//
+// The List query's ordering and paging are removed, so values are counted over every matching row.
+// Without counts, values come from topK and the distinct count from uniq, which are approximate but
+// need a fixed amount of memory; the count column is NULL. With counts, values are grouped and
+// counted exactly.
+func DistinctQuery(listQuery, column string, limit int, counts bool) (string, error) {
+	if !columnNamePattern.MatchString(column) {
+		return "", fmt.Errorf("invalid column name %q", column)
+	}
+
+	if limit < 1 {
+		return "", fmt.Errorf("limit must be positive, got %d", limit)
+	}
+
+	inner := listQueryTailPattern.ReplaceAllString(listQuery, "")
+	if limitPattern.MatchString(inner) {
+		return "", ErrUnsupportedListQuery
+	}
+
+	if !counts {
+		return fmt.Sprintf("SELECT arrayJoin(topK(%d)(`%s`)) AS value, CAST(NULL AS Nullable(UInt64)) AS frequency, "+
+			"uniq(`%s`) AS distinct_count FROM (%s)", limit, column, column, inner), nil
+	}
+
+	return fmt.Sprintf("SELECT value, frequency, count() OVER () AS distinct_count FROM ("+
+		"SELECT `%s` AS value, count() AS frequency FROM (%s) GROUP BY value"+
+		") ORDER BY frequency DESC, value LIMIT %d", column, inner, limit), nil
+}
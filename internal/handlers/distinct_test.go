@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistinctQuery(t *testing.T) {
+	const inner = "SELECT * FROM `mainnet`.`fct_block` FINAL WHERE slot >= ? AND status = ?"
+
+	tests := []struct {
+		name      string
+		listQuery string
+		counts    bool
+		want      string
+	}{
+		{
+			name:      "topK without counts",
+			listQuery: inner + " ORDER BY slot_start_date_time, slot DESC LIMIT 100",
+			want: "SELECT arrayJoin(topK(10)(`status`)) AS value, CAST(NULL AS Nullable(UInt64)) AS frequency, " +
+				"uniq(`status`) AS distinct_count FROM (" + inner + ")",
+		},
+		{
+			name:      "grouped with counts",
+			listQuery: inner + " ORDER BY slot LIMIT 100 OFFSET 200",
+			counts:    true,
+			want: "SELECT value, frequency, count() OVER () AS distinct_count FROM (" +
+				"SELECT `status` AS value, count() AS frequency FROM (" + inner + ") GROUP BY value" +
+				") ORDER BY frequency DESC, value LIMIT 10",
+		},
+		{
+			name:      "without ordering or paging",
+			listQuery: inner,
+			counts:    true,
+			want: "SELECT value, frequency, count() OVER () AS distinct_count FROM (" +
+				"SELECT `status` AS value, count() AS frequency FROM (" + inner + ") GROUP BY value" +
+				") ORDER BY frequency DESC, value LIMIT 10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DistinctQuery(tt.listQuery, "status", 10, tt.counts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("placeholder limit is rejected", func(t *testing.T) {
+		_, err := DistinctQuery(inner+" LIMIT ? OFFSET ?", "status", 10, false)
+		assert.ErrorIs(t, err, ErrUnsupportedListQuery)
+	})
+
+	t.Run("invalid column", func(t *testing.T) {
+		_, err := DistinctQuery(inner, "status`) FROM x --", 10, false)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		_, err := DistinctQuery(inner, "status", 0, false)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The types below implement the api.encoding policy for DateTime, Decimal and Enum columns.
+// openapi-preprocess maps columns to them with x-go-type; each implements sql.Scanner, so
+// rows.ScanStruct and the generated proto converters fill them the same way, and encodes a
+// NULL or unset value as null.
+
+// decimalPattern matches the decimal text of a ClickHouse Decimal value.
+var decimalPattern = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// nullTime is a nullable instant shared by the DateTime encodings.
+type nullTime struct {
+	t     time.Time
+	valid bool
+}
+
+// Time returns the instant, or the zero time when null.
+func (n nullTime) Time() time.Time {
+	return n.t
+}
+
+// Valid reports whether the value is not null.
+func (n nullTime) Valid() bool {
+	return n.valid
+}
+
+// Scan implements sql.Scanner for DateTime and DateTime64 columns. Integers, as held by the proto
+// types, are Unix seconds; strings are RFC 3339.
+func (n *nullTime) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*n = nullTime{}
+	case time.Time:
+		*n = nullTime{t: v.UTC(), valid: true}
+	case uint32:
+		*n = nullTime{t: time.Unix(int64(v), 0).UTC(), valid: true}
+	case int64:
+		*n = nullTime{t: time.Unix(v, 0).UTC(), valid: true}
+	case uint64:
+		*n = nullTime{t: time.Unix(int64(v), 0).UTC(), valid: true} //nolint:gosec // Unix seconds fit in int64.
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return fmt.Errorf("cannot scan %q into a DateTime: %w", v, err)
+		}
+
+		*n = nullTime{t: t.UTC(), valid: true}
+	default:
+		return fmt.Errorf("cannot scan %T into a DateTime", src)
+	}
+
+	return nil
+}
+
+// RFC3339Time is a DateTime or DateTime64 column encoded as an RFC 3339 string in UTC, keeping
+// the column's fractional seconds (api.encoding.datetime: rfc3339).
+type RFC3339Time struct {
+	nullTime
+}
+
+// NewRFC3339Time returns an RFC3339Time holding t.
+func NewRFC3339Time(t time.Time) RFC3339Time {
+	return RFC3339Time{nullTime{t: t.UTC(), valid: true}}
+}
+
+// MarshalJSON encodes the value as an RFC 3339 string, or null.
+func (r RFC3339Time) MarshalJSON() ([]byte, error) {
+	if !r.valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(r.t.Format(time.RFC3339Nano))
+}
+
+// UnmarshalJSON accepts an RFC 3339 string or null.
+func (r *RFC3339Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*r = RFC3339Time{}
+
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("RFC3339Time must be a string or null: %w", err)
+	}
+
+	return r.Scan(s)
+}
+
+// EpochTime is a DateTime64 column encoded as a JSON number of Unix seconds with the column's
+// fractional digits, e.g. 1704067200.123456789 (api.encoding.datetime: epoch).
+type EpochTime struct {
+	nullTime
+}
+
+// NewEpochTime returns an EpochTime holding t.
+func NewEpochTime(t time.Time) EpochTime {
+	return EpochTime{nullTime{t: t.UTC(), valid: true}}
+}
+
+// MarshalJSON encodes the value as a number of Unix seconds, or null. The digits are written
+// directly rather than through float64, so nanoseconds are not rounded away.
+func (e EpochTime) MarshalJSON() ([]byte, error) {
+	if !e.valid {
+		return []byte("null"), nil
+	}
+
+	seconds, nanos := e.t.Unix(), e.t.Nanosecond()
+	if nanos == 0 {
+		return strconv.AppendInt(nil, seconds, 10), nil
+	}
+
+	// Instants before 1970 with a fraction are -(|seconds|-1).(1e9-nanos)
+	sign := ""
+	if seconds < 0 {
+		sign, seconds, nanos = "-", -seconds-1, 1e9-nanos
+	}
+
+	fraction := strings.TrimRight(fmt.Sprintf("%09d", nanos), "0")
+
+	return fmt.Appendf(nil, "%s%d.%s", sign, seconds, fraction), nil
+}
+
+// UnmarshalJSON accepts a number of Unix seconds or null.
+func (e *EpochTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*e = EpochTime{}
+
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("EpochTime must be a number or null: %w", err)
+	}
+
+	whole, fraction, _ := strings.Cut(n.String(), ".")
+
+	seconds, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return fmt.Errorf("EpochTime must be a number of seconds: %w", err)
+	}
+
+	var nanos int64
+
+	if fraction != "" {
+		nanos, err = strconv.ParseInt((fraction + "000000000")[:9], 10, 64)
+		if err != nil {
+			return fmt.Errorf("EpochTime must be a number of seconds: %w", err)
+		}
+
+		if strings.HasPrefix(whole, "-") {
+			nanos = -nanos
+		}
+	}
+
+	*e = NewEpochTime(time.Unix(seconds, nanos))
+
+	return nil
+}
+
+// nullDecimal is a nullable decimal held as its exact text, shared by the Decimal encodings.
+type nullDecimal struct {
+	value string
+	valid bool
+}
+
+// String returns the decimal text, or "" when null.
+func (n nullDecimal) String() string {
+	return n.value
+}
+
+// Valid reports whether the value is not null.
+func (n nullDecimal) Valid() bool {
+	return n.valid
+}
+
+// Scan implements sql.Scanner for Decimal columns, which clickhouse-go returns as
+// decimal.Decimal, and for the float64 or string proto types.
+func (n *nullDecimal) Scan(src any) error {
+	var value string
+
+	switch v := src.(type) {
+	case nil:
+		*n = nullDecimal{}
+
+		return nil
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	case float64:
+		value = strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		value = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case int64:
+		value = strconv.FormatInt(v, 10)
+	case uint64:
+		value = strconv.FormatUint(v, 10)
+	case fmt.Stringer: // decimal.Decimal
+		value = v.String()
+	default:
+		return fmt.Errorf("cannot scan %T into a Decimal", src)
+	}
+
+	if !decimalPattern.MatchString(value) {
+		return fmt.Errorf("cannot scan %q into a Decimal", value)
+	}
+
+	*n = nullDecimal{value: value, valid: true}
+
+	return nil
+}
+
+// DecimalString is a Decimal column encoded as a JSON string with its exact digits
+// (api.encoding.decimal: string).
+type DecimalString struct {
+	nullDecimal
+}
+
+// MarshalJSON encodes the value as a JSON string, or null.
+func (d DecimalString) MarshalJSON() ([]byte, error) {
+	if !d.valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(d.value)
+}
+
+// UnmarshalJSON accepts a JSON string, number or null.
+func (d *DecimalString) UnmarshalJSON(data []byte) error {
+	return d.unmarshalJSON(data)
+}
+
+// DecimalNumber is a Decimal column encoded as a JSON number with its exact digits, rather than
+// the nearest float64 (api.encoding.decimal: number).
+type DecimalNumber struct {
+	nullDecimal
+}
+
+// MarshalJSON encodes the value as a JSON number, or null.
+func (d DecimalNumber) MarshalJSON() ([]byte, error) {
+	if !d.valid {
+		return []byte("null"), nil
+	}
+
+	return []byte(d.value), nil
+}
+
+// UnmarshalJSON accepts a JSON string, number or null.
+func (d *DecimalNumber) UnmarshalJSON(data []byte) error {
+	return d.unmarshalJSON(data)
+}
+
+// unmarshalJSON decodes a JSON string, number or null.
+func (n *nullDecimal) unmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*n = nullDecimal{}
+
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return n.Scan(s)
+	}
+
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err != nil {
+		return fmt.Errorf("decimal must be a string, number or null: %w", err)
+	}
+
+	return n.Scan(num.String())
+}
+
+// EnumValue is an Enum8 or Enum16 column encoded as its numeric value rather than its name
+// (api.encoding.enum: value). The generated handlers cast the column to Int16 in the query,
+// as clickhouse-go scans Enum columns as names.
+type EnumValue struct {
+	value int16
+	valid bool
+}
+
+// NewEnumValue returns an EnumValue holding v.
+func NewEnumValue(v int16) EnumValue {
+	return EnumValue{value: v, valid: true}
+}
+
+// Value returns the numeric value, or 0 when null.
+func (e EnumValue) Value() int16 {
+	return e.value
+}
+
+// Valid reports whether the value is not null.
+func (e EnumValue) Valid() bool {
+	return e.valid
+}
+
+// Scan implements sql.Scanner for Enum columns cast to an integer.
+func (e *EnumValue) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*e = EnumValue{}
+	case int8:
+		*e = NewEnumValue(int16(v))
+	case int16:
+		*e = NewEnumValue(v)
+	case string:
+		n, err := strconv.ParseInt(v, 10, 16)
+		if err != nil {
+			return fmt.Errorf("cannot scan enum name %q into EnumValue", v)
+		}
+
+		*e = NewEnumValue(int16(n))
+	default:
+		return fmt.Errorf("cannot scan %T into EnumValue", src)
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the value as a JSON number, or null.
+func (e EnumValue) MarshalJSON() ([]byte, error) {
+	if !e.valid {
+		return []byte("null"), nil
+	}
+
+	return strconv.AppendInt(nil, int64(e.value), 10), nil
+}
+
+// UnmarshalJSON accepts a JSON number or null.
+func (e *EnumValue) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*e = EnumValue{}
+
+		return nil
+	}
+
+	var n int16
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("EnumValue must be a number or null: %w", err)
+	}
+
+	*e = NewEnumValue(n)
+
+	return nil
+}
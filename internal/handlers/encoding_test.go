@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeEncodings(t *testing.T) {
+	nanos := time.Date(2024, 1, 1, 0, 0, 0, 123456789, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name      string
+		src       any
+		rfc3339   string
+		epoch     string
+		wantError bool
+	}{
+		{name: "datetime64(9)", src: nanos, rfc3339: `"2023-12-31T23:00:00.123456789Z"`, epoch: `1704063600.123456789`},
+		{name: "whole seconds", src: time.Unix(1704067200, 0), rfc3339: `"2024-01-01T00:00:00Z"`, epoch: `1704067200`},
+		{name: "before 1970", src: time.Unix(-1, 750000000), rfc3339: `"1969-12-31T23:59:59.75Z"`, epoch: `-0.25`},
+		{name: "proto uint32", src: uint32(1704067200), rfc3339: `"2024-01-01T00:00:00Z"`, epoch: `1704067200`},
+		{name: "null", src: nil, rfc3339: `null`, epoch: `null`},
+		{name: "unsupported", src: 1.5, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r RFC3339Time
+				e EpochTime
+			)
+
+			if tt.wantError {
+				assert.Error(t, r.Scan(tt.src))
+				assert.Error(t, e.Scan(tt.src))
+
+				return
+			}
+
+			require.NoError(t, r.Scan(tt.src))
+			require.NoError(t, e.Scan(tt.src))
+
+			got, err := json.Marshal(r)
+			require.NoError(t, err)
+			assert.Equal(t, tt.rfc3339, string(got))
+
+			got, err = json.Marshal(e)
+			require.NoError(t, err)
+			assert.Equal(t, tt.epoch, string(got))
+
+			// Both encodings round-trip
+			var (
+				r2 RFC3339Time
+				e2 EpochTime
+			)
+
+			require.NoError(t, json.Unmarshal([]byte(tt.rfc3339), &r2))
+			require.NoError(t, json.Unmarshal([]byte(tt.epoch), &e2))
+			assert.True(t, r.Time().Equal(r2.Time()))
+			assert.True(t, e.Time().Equal(e2.Time()))
+			assert.Equal(t, r.Valid(), e2.Valid())
+		})
+	}
+}
+
+// decimalText stands in for decimal.Decimal, which clickhouse-go passes to Scan.
+type decimalText string
+
+func (d decimalText) String() string {
+	return string(d)
+}
+
+func TestDecimalEncodings(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    any
+		str    string
+		number string
+	}{
+		{name: "decimal128(18)", src: decimalText("12345678901234567.123456789012345678"),
+			str: `"12345678901234567.123456789012345678"`, number: `12345678901234567.123456789012345678`},
+		{name: "negative", src: decimalText("-0.0001"), str: `"-0.0001"`, number: `-0.0001`},
+		{name: "proto double", src: 1.25, str: `"1.25"`, number: `1.25`},
+		{name: "null", src: nil, str: `null`, number: `null`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				s DecimalString
+				n DecimalNumber
+			)
+
+			require.NoError(t, s.Scan(tt.src))
+			require.NoError(t, n.Scan(tt.src))
+
+			got, err := json.Marshal(s)
+			require.NoError(t, err)
+			assert.Equal(t, tt.str, string(got))
+
+			got, err = json.Marshal(n)
+			require.NoError(t, err)
+			assert.Equal(t, tt.number, string(got))
+
+			// Either encoding is accepted when decoding
+			var s2 DecimalString
+
+			require.NoError(t, json.Unmarshal([]byte(tt.number), &s2))
+			assert.Equal(t, s.String(), s2.String())
+		})
+	}
+
+	var d DecimalNumber
+
+	assert.Error(t, d.Scan("NaN"))
+}
+
+func TestEnumValue(t *testing.T) {
+	var e EnumValue
+
+	require.NoError(t, e.Scan(int16(300)))
+
+	got, err := json.Marshal(e)
+	require.NoError(t, err)
+	assert.Equal(t, `300`, string(got))
+
+	require.NoError(t, e.Scan(int8(-2)))
+	assert.Equal(t, int16(-2), e.Value())
+
+	require.NoError(t, e.Scan(nil))
+	assert.False(t, e.Valid())
+
+	got, err = json.Marshal(e)
+	require.NoError(t, err)
+	assert.Equal(t, `null`, string(got))
+
+	// Names are only available when the column was not cast
+	assert.Error(t, e.Scan("option1"))
+}
@@ -9,15 +9,46 @@ import (
 
 // HealthResponse represents the health check response.
 type HealthResponse struct {
-	Status  string `json:"status"`
-	Version string `json:"version"`
+	Status     string            `json:"status"`
+	Version    string            `json:"version"`
+	Components map[string]string `json:"components,omitempty"`
+}
+
+// HealthComponent is a dependency whose state is reported by /health.
+type HealthComponent interface {
+	Name() string
+	Status() string
+	Healthy() bool
 }
 
 // Health handles health check requests.
 func Health(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(HealthResponse{
-		Status:  "ok",
-		Version: version.Short(),
-	})
+	NewHealth()(w, r)
+}
+
+// NewHealth returns a health check handler reporting the state of the given components.
+// The status is "degraded" while any component is unhealthy; the response code stays 200 so
+// liveness probes don't restart the API over a dependency problem.
+func NewHealth(components ...HealthComponent) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		response := HealthResponse{
+			Status:  "ok",
+			Version: version.Short(),
+		}
+
+		if len(components) > 0 {
+			response.Components = make(map[string]string, len(components))
+		}
+
+		for _, component := range components {
+			response.Components[component.Name()] = component.Status()
+
+			if !component.Healthy() {
+				response.Status = "degraded"
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}
 }
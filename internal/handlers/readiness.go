@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethpandaops/cbt-api/internal/version"
+)
+
+// readinessCheckTimeout bounds a full readiness check.
+const readinessCheckTimeout = 5 * time.Second
+
+// ReadinessDatabase is the database access needed by readiness checks.
+type ReadinessDatabase interface {
+	Ping(ctx context.Context) error
+	Select(ctx context.Context, dest any, query string, args ...any) error
+}
+
+// ReadinessConfig configures the readiness checks.
+type ReadinessConfig struct {
+	Database     string        // ClickHouse database holding the exposed tables
+	Tables       []string      // Tables exposed by the API
+	SampleTables int           // Number of tables checked per run (0 checks none)
+	CacheTTL     time.Duration // How long a result is reused
+}
+
+// CheckResult is the outcome of a single readiness check.
+type CheckResult struct {
+	Status    string   `json:"status"`
+	LatencyMs float64  `json:"latency_ms"`
+	Error     string   `json:"error,omitempty"`
+	Tables    []string `json:"tables,omitempty"`
+}
+
+// ReadinessResponse represents the readiness check response.
+type ReadinessResponse struct {
+	Status    string                 `json:"status"`
+	Version   string                 `json:"version"`
+	CheckedAt time.Time              `json:"checked_at"`
+	Checks    map[string]CheckResult `json:"checks"`
+}
+
+// Readiness reports whether the API can serve traffic: ClickHouse answers a ping and a random
+// sample of exposed tables exists. Results are cached for CacheTTL so frequent probes from
+// several sources result in at most one check per TTL.
+type Readiness struct {
+	db  ReadinessDatabase
+	cfg ReadinessConfig
+	now func() time.Time
+
+	mu     sync.Mutex
+	cached *ReadinessResponse
+}
+
+// NewReadiness creates a readiness handler.
+func NewReadiness(db ReadinessDatabase, cfg ReadinessConfig) *Readiness {
+	return &Readiness{
+		db:  db,
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// ServeHTTP handles readiness requests, returning 503 if any check fails.
+func (rd *Readiness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	response := rd.check(r.Context())
+
+	code := http.StatusOK
+	if response.Status != "ok" {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// check returns the cached result, or runs the checks if it has expired. The lock is held while
+// checking so concurrent probes wait for one result rather than each querying ClickHouse.
+func (rd *Readiness) check(ctx context.Context) ReadinessResponse {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+
+	if rd.cached != nil && rd.now().Sub(rd.cached.CheckedAt) < rd.cfg.CacheTTL {
+		return *rd.cached
+	}
+
+	// Detach from the probe's cancellation, as the result is shared with other callers.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), readinessCheckTimeout)
+	defer cancel()
+
+	response := ReadinessResponse{
+		Status:    "ok",
+		Version:   version.Short(),
+		CheckedAt: rd.now(),
+		Checks:    make(map[string]CheckResult, 2),
+	}
+
+	response.Checks["clickhouse"] = rd.timed(func(_ *CheckResult) error {
+		return rd.db.Ping(ctx)
+	})
+
+	if tables := rd.sampleTables(); len(tables) > 0 {
+		response.Checks["tables"] = rd.timed(func(result *CheckResult) error {
+			result.Tables = tables
+
+			return rd.checkTables(ctx, tables)
+		})
+	}
+
+	for _, result := range response.Checks {
+		if result.Status != "ok" {
+			response.Status = "unavailable"
+		}
+	}
+
+	rd.cached = &response
+
+	return response
+}
+
+// timed runs a check and records its latency and outcome.
+func (rd *Readiness) timed(fn func(result *CheckResult) error) CheckResult {
+	var result CheckResult
+
+	start := rd.now()
+	err := fn(&result)
+	result.LatencyMs = float64(rd.now().Sub(start).Microseconds()) / 1000
+
+	result.Status = "ok"
+	if err != nil {
+		result.Status = "error"
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// checkTables verifies that every table exists in the configured database.
+func (rd *Readiness) checkTables(ctx context.Context, tables []string) error {
+	var found []tableName
+
+	if err := rd.db.Select(ctx, &found,
+		"SELECT name FROM system.tables WHERE database = ? AND has(?, name)", rd.cfg.Database, tables,
+	); err != nil {
+		return err
+	}
+
+	missing := make([]string, 0)
+
+	for _, table := range tables {
+		if !slices.Contains(found, tableName{Name: table}) {
+			missing = append(missing, table)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing tables: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// tableName is a row of system.tables.
+type tableName struct {
+	Name string `ch:"name"`
+}
+
+// sampleTables picks up to SampleTables random exposed tables.
+func (rd *Readiness) sampleTables() []string {
+	if rd.cfg.SampleTables <= 0 || len(rd.cfg.Tables) == 0 {
+		return nil
+	}
+
+	tables := slices.Clone(rd.cfg.Tables)
+	rand.Shuffle(len(tables), func(i, j int) { tables[i], tables[j] = tables[j], tables[i] })
+
+	tables = tables[:min(rd.cfg.SampleTables, len(tables))]
+	slices.Sort(tables)
+
+	return tables
+}
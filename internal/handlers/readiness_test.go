@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReadinessDB answers pings and system.tables lookups from canned values.
+type fakeReadinessDB struct {
+	pingErr  error
+	existing []string
+	pings    int
+}
+
+func (f *fakeReadinessDB) Ping(_ context.Context) error {
+	f.pings++
+
+	return f.pingErr
+}
+
+func (f *fakeReadinessDB) Select(_ context.Context, dest any, _ string, args ...any) error {
+	requested, _ := args[1].([]string)
+	rows := reflect.ValueOf(dest).Elem()
+
+	for _, name := range requested {
+		for _, existing := range f.existing {
+			if name == existing {
+				rows.Set(reflect.Append(rows, reflect.ValueOf(tableName{Name: name})))
+			}
+		}
+	}
+
+	return nil
+}
+
+func serveReadiness(t *testing.T, rd *Readiness) (int, ReadinessResponse) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	rd.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var response ReadinessResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+	return rec.Code, response
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name         string
+		db           *fakeReadinessDB
+		expectedCode int
+		failedCheck  string
+	}{
+		{
+			name:         "ready",
+			db:           &fakeReadinessDB{existing: []string{"fct_block", "fct_attestation"}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "ping fails",
+			db:           &fakeReadinessDB{pingErr: errors.New("connection refused"), existing: []string{"fct_block", "fct_attestation"}},
+			expectedCode: http.StatusServiceUnavailable,
+			failedCheck:  "clickhouse",
+		},
+		{
+			name:         "table missing",
+			db:           &fakeReadinessDB{existing: []string{"fct_block"}},
+			expectedCode: http.StatusServiceUnavailable,
+			failedCheck:  "tables",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rd := NewReadiness(tt.db, ReadinessConfig{
+				Database:     "mainnet",
+				Tables:       []string{"fct_attestation", "fct_block"},
+				SampleTables: 5,
+			})
+
+			code, response := serveReadiness(t, rd)
+
+			assert.Equal(t, tt.expectedCode, code)
+			assert.Contains(t, response.Checks, "clickhouse")
+			assert.Equal(t, []string{"fct_attestation", "fct_block"}, response.Checks["tables"].Tables)
+
+			if tt.failedCheck == "" {
+				assert.Equal(t, "ok", response.Status)
+
+				return
+			}
+
+			assert.Equal(t, "unavailable", response.Status)
+			assert.Equal(t, "error", response.Checks[tt.failedCheck].Status)
+			assert.NotEmpty(t, response.Checks[tt.failedCheck].Error)
+		})
+	}
+}
+
+func TestReadiness_Cache(t *testing.T) {
+	db := &fakeReadinessDB{}
+	now := time.Unix(1700000000, 0)
+
+	rd := NewReadiness(db, ReadinessConfig{CacheTTL: 2 * time.Second})
+	rd.now = func() time.Time { return now }
+
+	serveReadiness(t, rd)
+	serveReadiness(t, rd)
+	assert.Equal(t, 1, db.pings)
+
+	now = now.Add(3 * time.Second)
+
+	serveReadiness(t, rd)
+	assert.Equal(t, 2, db.pings)
+}
+
+func TestReadiness_SampleTables(t *testing.T) {
+	rd := NewReadiness(&fakeReadinessDB{}, ReadinessConfig{
+		Tables:       []string{"a", "b", "c", "d"},
+		SampleTables: 2,
+	})
+
+	assert.Len(t, rd.sampleTables(), 2)
+
+	rd.cfg.SampleTables = 0
+	assert.Nil(t, rd.sampleTables())
+}
@@ -1,19 +1,210 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/rs/cors"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
 )
 
-// CORS returns a middleware that handles CORS headers.
-func CORS() func(http.Handler) http.Handler {
-	c := cors.New(cors.Options{
-		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "HEAD", "OPTIONS"},
-		AllowedHeaders: []string{"*"},
-		MaxAge:         86400, // 24 hours
-	})
+// CORS applies the configured CORS policy, choosing a per-path override when one matches.
+// The configuration can be replaced at runtime with Update.
+type CORS struct {
+	mu       sync.RWMutex
+	defaults *cors.Cors
+	policies []corsPolicy
+}
+
+// corsPolicy is a compiled per-path CORS override.
+type corsPolicy struct {
+	name    string
+	pattern *regexp.Regexp
+	cors    *cors.Cors
+}
+
+// NewCORS compiles the CORS configuration. Returns an error if a pattern is invalid or a policy
+// allows credentials for any origin.
+func NewCORS(cfg config.CORSConfig) (*CORS, error) {
+	c := &CORS{}
+
+	if err := c.Update(cfg); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Update replaces the CORS configuration. On error the current configuration is kept.
+func (c *CORS) Update(cfg config.CORSConfig) error {
+	defaults, err := newCORS(cfg.CORSOptions)
+	if err != nil {
+		return fmt.Errorf("invalid cors configuration: %w", err)
+	}
+
+	policies := make([]corsPolicy, 0, len(cfg.Policies))
+
+	for _, p := range cfg.Policies {
+		pattern, err := regexp.Compile(p.PathPattern)
+		if err != nil {
+			return fmt.Errorf("invalid path_pattern in cors policy %q: %w", p.Name, err)
+		}
+
+		policyCORS, err := newCORS(mergeCORSOptions(cfg.CORSOptions, p.CORSOptions))
+		if err != nil {
+			return fmt.Errorf("invalid cors policy %q: %w", p.Name, err)
+		}
+
+		policies = append(policies, corsPolicy{
+			name:    p.Name,
+			pattern: pattern,
+			cors:    policyCORS,
+		})
+	}
+
+	c.mu.Lock()
+	c.defaults = defaults
+	c.policies = policies
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Middleware returns a middleware that handles CORS headers and preflight requests.
+func (c *CORS) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c.match(r.URL.Path).ServeHTTP(w, r, next.ServeHTTP)
+		})
+	}
+}
+
+// match returns the first policy matching path, or the defaults.
+func (c *CORS) match(path string) *cors.Cors {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, p := range c.policies {
+		if p.pattern.MatchString(path) {
+			return p.cors
+		}
+	}
+
+	return c.defaults
+}
+
+// mergeCORSOptions fills the options a policy leaves unset from the top-level options.
+func mergeCORSOptions(base, override config.CORSOptions) config.CORSOptions {
+	merged := override
+
+	if merged.AllowedOrigins == nil && merged.AllowedOriginPatterns == nil {
+		merged.AllowedOrigins = base.AllowedOrigins
+		merged.AllowedOriginPatterns = base.AllowedOriginPatterns
+	}
+
+	if merged.AllowedMethods == nil {
+		merged.AllowedMethods = base.AllowedMethods
+	}
+
+	if merged.AllowedHeaders == nil {
+		merged.AllowedHeaders = base.AllowedHeaders
+	}
+
+	if merged.ExposedHeaders == nil {
+		merged.ExposedHeaders = base.ExposedHeaders
+	}
+
+	if merged.AllowCredentials == nil {
+		merged.AllowCredentials = base.AllowCredentials
+	}
+
+	if merged.MaxAge == 0 {
+		merged.MaxAge = base.MaxAge
+	}
+
+	return merged
+}
+
+// newCORS builds an rs/cors handler from options.
+func newCORS(opts config.CORSOptions) (*cors.Cors, error) {
+	allowCredentials := opts.AllowCredentials != nil && *opts.AllowCredentials
+
+	if allowCredentials && slices.Contains(opts.AllowedOrigins, "*") {
+		return nil, fmt.Errorf("allow_credentials cannot be used with allowed_origins \"*\"")
+	}
+
+	options := cors.Options{
+		AllowedOrigins:   opts.AllowedOrigins,
+		AllowedMethods:   opts.AllowedMethods,
+		AllowedHeaders:   opts.AllowedHeaders,
+		ExposedHeaders:   opts.ExposedHeaders,
+		AllowCredentials: allowCredentials,
+		MaxAge:           int(opts.MaxAge.Seconds()),
+	}
+
+	// rs/cors only understands exact origins with at most one wildcard, so regexes need a custom matcher.
+	if len(opts.AllowedOriginPatterns) > 0 {
+		allowed, err := newOriginMatcher(opts.AllowedOrigins, opts.AllowedOriginPatterns)
+		if err != nil {
+			return nil, err
+		}
+
+		options.AllowOriginFunc = allowed
+	}
+
+	return cors.New(options), nil
+}
+
+// newOriginMatcher returns a function reporting whether an origin is in the list (which may use "*"
+// or a single wildcard per entry, as in rs/cors) or matches any of the regexes.
+func newOriginMatcher(origins, patterns []string) (func(origin string) bool, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed_origin_patterns entry %q: %w", pattern, err)
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	return func(origin string) bool {
+		origin = strings.ToLower(origin)
+
+		for _, allowed := range origins {
+			if matchOrigin(strings.ToLower(allowed), origin) {
+				return true
+			}
+		}
+
+		for _, re := range compiled {
+			if re.MatchString(origin) {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
+
+// matchOrigin matches an origin against an allowed entry, which may be "*" or contain one wildcard.
+func matchOrigin(allowed, origin string) bool {
+	if allowed == "*" {
+		return true
+	}
+
+	prefix, suffix, wildcard := strings.Cut(allowed, "*")
+	if !wildcard {
+		return allowed == origin
+	}
 
-	return c.Handler
+	return len(origin) >= len(prefix)+len(suffix) &&
+		strings.HasPrefix(origin, prefix) &&
+		strings.HasSuffix(origin, suffix)
 }
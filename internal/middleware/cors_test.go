@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestCORS(t *testing.T) {
+	cfg := config.CORSConfig{
+		CORSOptions: config.CORSOptions{
+			AllowedOrigins: []string{"*"},
+			AllowedMethods: []string{http.MethodGet, http.MethodHead, http.MethodOptions},
+			AllowedHeaders: []string{"*"},
+			ExposedHeaders: []string{"X-Request-ID"},
+			MaxAge:         24 * time.Hour,
+		},
+		Policies: []config.CORSPolicy{
+			{
+				Name:        "private",
+				PathPattern: "^/api/v1/private/",
+				CORSOptions: config.CORSOptions{
+					AllowedOrigins:        []string{"https://app.example.com"},
+					AllowedOriginPatterns: []string{`^https://[a-z0-9-]+\.preview\.example\.com$`},
+					AllowedHeaders:        []string{"Authorization"},
+					AllowCredentials:      boolPtr(true),
+				},
+			},
+		},
+	}
+
+	c, err := NewCORS(cfg)
+	require.NoError(t, err)
+
+	handler := c.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name              string
+		path              string
+		origin            string
+		expectOrigin      string
+		expectCredentials string
+	}{
+		{
+			name:         "default allows any origin",
+			path:         "/api/v1/fct_block",
+			origin:       "https://anywhere.example.org",
+			expectOrigin: "*",
+		},
+		{
+			name:              "override allows listed origin with credentials",
+			path:              "/api/v1/private/fct_block",
+			origin:            "https://app.example.com",
+			expectOrigin:      "https://app.example.com",
+			expectCredentials: "true",
+		},
+		{
+			name:              "override allows origin matching a regex",
+			path:              "/api/v1/private/fct_block",
+			origin:            "https://pr-42.preview.example.com",
+			expectOrigin:      "https://pr-42.preview.example.com",
+			expectCredentials: "true",
+		},
+		{
+			name:   "override rejects other origins",
+			path:   "/api/v1/private/fct_block",
+			origin: "https://evil.example.org",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Origin", tt.origin)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectOrigin, rec.Header().Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, tt.expectCredentials, rec.Header().Get("Access-Control-Allow-Credentials"))
+
+			if tt.expectOrigin != "" {
+				assert.Equal(t, "X-Request-Id", rec.Header().Get("Access-Control-Expose-Headers"), "inherited from defaults")
+			}
+		})
+	}
+
+	t.Run("preflight uses override headers and inherited max age", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/api/v1/private/fct_block", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		req.Header.Set("Access-Control-Request-Headers", "authorization")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "authorization", rec.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "86400", rec.Header().Get("Access-Control-Max-Age"))
+	})
+}
+
+func TestNewCORS_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.CORSConfig
+	}{
+		{
+			name: "credentials with wildcard origin",
+			cfg: config.CORSConfig{CORSOptions: config.CORSOptions{
+				AllowedOrigins:   []string{"*"},
+				AllowCredentials: boolPtr(true),
+			}},
+		},
+		{
+			name: "credentials inherited by a policy with wildcard origin",
+			cfg: config.CORSConfig{
+				CORSOptions: config.CORSOptions{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: boolPtr(true)},
+				Policies: []config.CORSPolicy{{
+					Name:        "public",
+					PathPattern: "^/public",
+					CORSOptions: config.CORSOptions{AllowedOrigins: []string{"*"}},
+				}},
+			},
+		},
+		{
+			name: "invalid origin regex",
+			cfg: config.CORSConfig{CORSOptions: config.CORSOptions{
+				AllowedOriginPatterns: []string{"("},
+			}},
+		},
+		{
+			name: "invalid path pattern",
+			cfg:  config.CORSConfig{Policies: []config.CORSPolicy{{Name: "broken", PathPattern: "("}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCORS(tt.cfg)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	apierrors "github.com/ethpandaops/cbt-api/internal/errors"
+)
+
+// ErrorFormat returns a middleware that negotiates the error response format from the Accept header.
+// Clients asking for application/problem+json get RFC 9457 problem details; everyone else keeps
+// the google.rpc.Status format. Response writers wrapped inside it must implement Unwrap.
+func ErrorFormat() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(apierrors.NegotiateFormat(w, r), r)
+		})
+	}
+}
@@ -63,6 +63,11 @@ func (w *bufferedResponseWriter) WriteHeader(code int) {
 	}
 }
 
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController and error format negotiation.
+func (w *bufferedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // GzipOption is a functional option for configuring the Gzip middleware.
 type GzipOption func(*gzipConfig)
 
@@ -749,6 +749,32 @@ func TestMiddleware(t *testing.T) {
 	}
 }
 
+func TestManagerUpdate(t *testing.T) {
+	manager, err := NewManager([]config.HeaderPolicy{
+		{Name: "health", PathPattern: "^/health$", Headers: map[string]string{"Cache-Control": "no-cache"}},
+	})
+	require.NoError(t, err)
+
+	// An invalid pattern is rejected and the current policies are kept
+	err = manager.Update([]config.HeaderPolicy{{Name: "bad", PathPattern: "[invalid"}})
+	require.Error(t, err)
+
+	policyName, _ := manager.Match("/health")
+	assert.Equal(t, "health", policyName)
+
+	err = manager.Update([]config.HeaderPolicy{
+		{Name: "api", PathPattern: "^/api/", Headers: map[string]string{"Cache-Control": "max-age=60"}},
+	})
+	require.NoError(t, err)
+
+	policyName, headers := manager.Match("/api/v1/foo")
+	assert.Equal(t, "api", policyName)
+	assert.Equal(t, "max-age=60", headers["Cache-Control"])
+
+	policyName, _ = manager.Match("/health")
+	assert.Empty(t, policyName)
+}
+
 func TestMiddlewareWithNilManager(t *testing.T) {
 	// This test ensures that Match is safe to call even with no policies
 	manager := &Manager{
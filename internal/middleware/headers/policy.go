@@ -3,12 +3,15 @@ package headers
 import (
 	"fmt"
 	"regexp"
+	"sync"
 
 	"github.com/ethpandaops/cbt-api/internal/config"
 )
 
 // Manager handles HTTP header policy matching and application.
+// Policies can be replaced at runtime with Update.
 type Manager struct {
+	mu       sync.RWMutex
 	policies []compiledPolicy
 }
 
@@ -21,6 +24,30 @@ type compiledPolicy struct {
 // NewManager creates a new Manager with compiled regex patterns from the provided policies.
 // Returns an error if any policy contains an invalid regex pattern.
 func NewManager(policies []config.HeaderPolicy) (*Manager, error) {
+	compiled, err := compilePolicies(policies)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{policies: compiled}, nil
+}
+
+// Update replaces the policies. On error the current policies are kept.
+func (m *Manager) Update(policies []config.HeaderPolicy) error {
+	compiled, err := compilePolicies(policies)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.policies = compiled
+	m.mu.Unlock()
+
+	return nil
+}
+
+// compilePolicies compiles the path pattern of every policy.
+func compilePolicies(policies []config.HeaderPolicy) ([]compiledPolicy, error) {
 	compiled := make([]compiledPolicy, 0, len(policies))
 
 	for _, p := range policies {
@@ -36,12 +63,15 @@ func NewManager(policies []config.HeaderPolicy) (*Manager, error) {
 		})
 	}
 
-	return &Manager{policies: compiled}, nil
+	return compiled, nil
 }
 
 // Match finds the first policy that matches the given path and returns its name and headers.
 // Returns empty string and nil if no policy matches.
 func (m *Manager) Match(path string) (string, map[string]string) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	for _, p := range m.policies {
 		if p.pattern.MatchString(path) {
 			return p.name, p.headers
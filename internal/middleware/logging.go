@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/ethpandaops/cbt-api/internal/network"
+	"github.com/ethpandaops/cbt-api/internal/requestid"
 	"github.com/sirupsen/logrus"
 )
 
@@ -25,6 +27,8 @@ func Logging(logger logrus.FieldLogger) func(http.Handler) http.Handler {
 				"status":      wrapped.statusCode,
 				"duration_ms": time.Since(start).Milliseconds(),
 				"remote_addr": r.RemoteAddr,
+				"request_id":  requestid.FromContext(r.Context()),
+				"network":     network.Name(r.Context()),
 			}).Info("request")
 		})
 	}
@@ -39,3 +43,8 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController and error format negotiation.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/ethpandaops/cbt-api/internal/network"
 )
 
 var (
@@ -15,7 +17,7 @@ var (
 			Name:      "http_requests_total",
 			Help:      "Total number of HTTP requests",
 		},
-		[]string{"method", "path", "status"},
+		[]string{"method", "route", "table", "operation", "network", "status"},
 	)
 
 	httpRequestDuration = prometheus.NewHistogramVec(
@@ -25,7 +27,7 @@ var (
 			Help:      "HTTP request duration in seconds",
 			Buckets:   prometheus.DefBuckets,
 		},
-		[]string{"method", "path"},
+		[]string{"method", "route", "table", "operation", "network"},
 	)
 
 	httpRequestsInFlight = prometheus.NewGauge(
@@ -43,7 +45,7 @@ var (
 			Help:      "HTTP request size in bytes",
 			Buckets:   []float64{100, 1000, 5000, 10000, 50000, 100000, 500000, 1000000},
 		},
-		[]string{"method", "path"},
+		[]string{"method", "route", "table", "operation", "network"},
 	)
 
 	httpResponseSizeBytes = prometheus.NewHistogramVec(
@@ -53,7 +55,7 @@ var (
 			Help:      "HTTP response size in bytes",
 			Buckets:   []float64{100, 1000, 5000, 10000, 50000, 100000, 500000, 1000000, 5000000},
 		},
-		[]string{"method", "path", "status"},
+		[]string{"method", "route", "table", "operation", "network", "status"},
 	)
 )
 
@@ -66,6 +68,9 @@ func init() {
 }
 
 // Metrics returns a middleware that collects Prometheus metrics.
+// Requests are labelled with the route template resolved by RouteMatcher rather than the raw path,
+// so Get-by-key URLs share a series and unmatched paths collapse into "unmatched", and with the
+// network resolved by Networks.
 func Metrics() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -82,9 +87,13 @@ func Metrics() func(http.Handler) http.Handler {
 				bytesWritten:   0,
 			}
 
+			route, table, operation := RouteLabels(r.Context())
+			networkName := network.Name(r.Context())
+
 			// Track request size
 			if r.ContentLength > 0 {
-				httpRequestSizeBytes.WithLabelValues(r.Method, r.URL.Path).Observe(float64(r.ContentLength))
+				httpRequestSizeBytes.WithLabelValues(r.Method, route, table, operation, networkName).
+					Observe(float64(r.ContentLength))
 			}
 
 			next.ServeHTTP(wrapped, r)
@@ -93,9 +102,10 @@ func Metrics() func(http.Handler) http.Handler {
 			status := strconv.Itoa(wrapped.statusCode)
 
 			// Record all metrics
-			httpRequestsTotal.WithLabelValues(r.Method, r.URL.Path, status).Inc()
-			httpRequestDuration.WithLabelValues(r.Method, r.URL.Path).Observe(duration)
-			httpResponseSizeBytes.WithLabelValues(r.Method, r.URL.Path, status).Observe(float64(wrapped.bytesWritten))
+			httpRequestsTotal.WithLabelValues(r.Method, route, table, operation, networkName, status).Inc()
+			httpRequestDuration.WithLabelValues(r.Method, route, table, operation, networkName).Observe(duration)
+			httpResponseSizeBytes.WithLabelValues(r.Method, route, table, operation, networkName, status).
+				Observe(float64(wrapped.bytesWritten))
 		})
 	}
 }
@@ -112,6 +122,11 @@ func (rw *metricsResponseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController and error format negotiation.
+func (rw *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func (rw *metricsResponseWriter) Write(b []byte) (int, error) {
 	n, err := rw.ResponseWriter.Write(b)
 	rw.bytesWritten += n
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ethpandaops/cbt-api/internal/network"
+)
+
+// Networks returns a middleware that resolves the network of API requests. When the first segment
+// after basePath names a network (/api/v1/holesky/fct_block), that network is used and the segment
+// is removed so routing and validation see /api/v1/fct_block; other API paths use defaultNetwork.
+// It must wrap RouteMatcher.
+func Networks(basePath string, networks []network.Network, defaultNetwork string) func(http.Handler) http.Handler {
+	byName := make(map[string]network.Network, len(networks))
+	for _, n := range networks {
+		byName[n.Name] = n
+	}
+
+	prefix := strings.TrimSuffix(basePath, "/") + "/"
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rest, ok := strings.CutPrefix(r.URL.Path, prefix)
+			if !ok {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			segment, tail, _ := strings.Cut(rest, "/")
+
+			if n, ok := byName[segment]; ok {
+				r = r.WithContext(network.WithContext(r.Context(), n))
+
+				u := *r.URL
+				u.Path = prefix + tail
+				u.RawPath = ""
+				r.URL = &u
+			} else if n, ok := byName[defaultNetwork]; ok {
+				r = r.WithContext(network.WithContext(r.Context(), n))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
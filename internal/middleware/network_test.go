@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethpandaops/cbt-api/internal/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworks(t *testing.T) {
+	networks := []network.Network{
+		{Name: "mainnet", Database: "mainnet"},
+		{Name: "holesky", Database: "holesky_v2"},
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		wantPath     string
+		wantNetwork  string
+		wantDatabase string
+	}{
+		{
+			name:         "network segment is stripped",
+			path:         "/api/v1/holesky/fct_block",
+			wantPath:     "/api/v1/fct_block",
+			wantNetwork:  "holesky",
+			wantDatabase: "holesky_v2",
+		},
+		{
+			name:         "get by key",
+			path:         "/api/v1/mainnet/fct_block/123",
+			wantPath:     "/api/v1/fct_block/123",
+			wantNetwork:  "mainnet",
+			wantDatabase: "mainnet",
+		},
+		{
+			name:         "no network segment uses the default",
+			path:         "/api/v1/fct_block",
+			wantPath:     "/api/v1/fct_block",
+			wantNetwork:  "mainnet",
+			wantDatabase: "mainnet",
+		},
+		{
+			name:         "unknown network is treated as a table",
+			path:         "/api/v1/sepolia/fct_block",
+			wantPath:     "/api/v1/sepolia/fct_block",
+			wantNetwork:  "mainnet",
+			wantDatabase: "mainnet",
+		},
+		{
+			name:     "paths outside the API have no network",
+			path:     "/health",
+			wantPath: "/health",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				gotPath string
+				got     network.Network
+			)
+
+			handler := Networks("/api/v1", networks, "mainnet")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				got, _ = network.FromContext(r.Context())
+			}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantPath, gotPath)
+			assert.Equal(t, tt.wantNetwork, got.Name)
+			assert.Equal(t, tt.wantDatabase, got.Database)
+		})
+	}
+}
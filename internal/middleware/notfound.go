@@ -7,6 +7,8 @@ import (
 )
 
 // NotFoundHandler returns a middleware that converts 404 responses to Status format.
+// 404s that are already a JSON Status, such as Get misses naming the table and key,
+// are passed through unchanged.
 func NotFoundHandler() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -19,7 +21,7 @@ func NotFoundHandler() func(http.Handler) http.Handler {
 			next.ServeHTTP(wrapped, r)
 
 			// If a 404 was written, convert to Status format
-			if wrapped.status == http.StatusNotFound {
+			if wrapped.status == http.StatusNotFound && !wrapped.passthrough {
 				status := apierrors.NotFoundf("path not found: %s", r.URL.Path)
 				status.WriteJSON(w)
 			}
@@ -31,6 +33,7 @@ type notFoundResponseWriter struct {
 	http.ResponseWriter
 	status        int
 	headerWritten bool
+	passthrough   bool
 	path          string
 }
 
@@ -38,13 +41,25 @@ func (w *notFoundResponseWriter) WriteHeader(status int) {
 	w.status = status
 	w.headerWritten = true
 
+	if status == http.StatusNotFound {
+		switch w.Header().Get("Content-Type") {
+		case "application/json", apierrors.ProblemContentType:
+			w.passthrough = true
+		}
+	}
+
 	// Only write the header if it's not a 404
 	// (we'll handle 404s after the handler completes)
-	if status != http.StatusNotFound {
+	if status != http.StatusNotFound || w.passthrough {
 		w.ResponseWriter.WriteHeader(status)
 	}
 }
 
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController and error format negotiation.
+func (w *notFoundResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func (w *notFoundResponseWriter) Write(b []byte) (int, error) {
 	// If no explicit status was set, assume 200
 	if w.status == 0 {
@@ -52,7 +67,7 @@ func (w *notFoundResponseWriter) Write(b []byte) (int, error) {
 	}
 
 	// Don't write 404 body - we'll replace it with Status format
-	if w.status == http.StatusNotFound {
+	if w.status == http.StatusNotFound && !w.passthrough {
 		return len(b), nil // Pretend we wrote it
 	}
 
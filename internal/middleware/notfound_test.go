@@ -81,3 +81,21 @@ func TestNotFoundHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestNotFoundHandler_StatusPassthrough(t *testing.T) {
+	handler := NotFoundHandler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apierrors.NotFoundf("resource not found: fct_block with slot=%d", 42).WriteJSON(w)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/fct_block/42", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	var status apierrors.Status
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, codes.NotFound, status.Code)
+	assert.Equal(t, "resource not found: fct_block with slot=42", status.Message)
+}
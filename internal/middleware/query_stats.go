@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ethpandaops/cbt-api/internal/database"
+)
+
+// Query statistics response headers.
+const (
+	HeaderClickHouseQueryID     = "X-ClickHouse-Query-Id"
+	HeaderClickHouseReadRows    = "X-ClickHouse-Read-Rows"
+	HeaderClickHouseReadBytes   = "X-ClickHouse-Read-Bytes"
+	HeaderClickHouseMemoryUsage = "X-ClickHouse-Memory-Usage"
+	HeaderClickHouseElapsedMs   = "X-ClickHouse-Elapsed-Ms"
+)
+
+// QueryStatsHeaders returns a middleware that reports the cost of the ClickHouse queries run for a
+// request in X-ClickHouse-* response headers. Statistics are summed across all queries of the request.
+// Headers are written just before the response header, once handlers have consumed their rows.
+func QueryStatsHeaders() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			collector := database.NewStatsCollector()
+			r = r.WithContext(database.WithStatsCollector(r.Context(), collector))
+
+			next.ServeHTTP(&queryStatsResponseWriter{ResponseWriter: w, collector: collector}, r)
+		})
+	}
+}
+
+// queryStatsResponseWriter injects query statistics headers when the response header is written.
+type queryStatsResponseWriter struct {
+	http.ResponseWriter
+	collector   *database.StatsCollector
+	wroteHeader bool
+}
+
+func (rw *queryStatsResponseWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		rw.setStatsHeaders()
+	}
+
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController and error format negotiation.
+func (rw *queryStatsResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *queryStatsResponseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	return rw.ResponseWriter.Write(b)
+}
+
+// setStatsHeaders sets the X-ClickHouse-* headers if any query ran.
+func (rw *queryStatsResponseWriter) setStatsHeaders() {
+	stats := rw.collector.Stats()
+	if stats.Queries == 0 {
+		return
+	}
+
+	h := rw.Header()
+	h.Set(HeaderClickHouseQueryID, stats.QueryID)
+	h.Set(HeaderClickHouseReadRows, strconv.FormatUint(stats.ReadRows, 10))
+	h.Set(HeaderClickHouseReadBytes, strconv.FormatUint(stats.ReadBytes, 10))
+	h.Set(HeaderClickHouseMemoryUsage, strconv.FormatInt(stats.PeakMemoryUsage, 10))
+	h.Set(HeaderClickHouseElapsedMs, strconv.FormatInt(stats.Elapsed.Milliseconds(), 10))
+}
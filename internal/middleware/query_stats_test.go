@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryStatsHeaders_NoQueries(t *testing.T) {
+	handler := QueryStatsHeaders()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+
+	for _, header := range []string{
+		HeaderClickHouseQueryID,
+		HeaderClickHouseReadRows,
+		HeaderClickHouseReadBytes,
+		HeaderClickHouseMemoryUsage,
+		HeaderClickHouseElapsedMs,
+	} {
+		assert.Empty(t, rec.Header().Get(header), "header %s should not be set without queries", header)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"runtime/debug"
 
 	"github.com/sirupsen/logrus"
+
+	apierrors "github.com/ethpandaops/cbt-api/internal/errors"
 )
 
 // Recovery returns a middleware that recovers from panics.
@@ -19,15 +21,7 @@ func Recovery(logger logrus.FieldLogger) func(http.Handler) http.Handler {
 						"stack": string(debug.Stack()),
 					}).Error("panic recovered")
 
-					// Return 500 Internal Server Error
-					w.Header().Set("Content-Type", "application/json")
-					w.WriteHeader(http.StatusInternalServerError)
-
-					// Write error response
-					errorMsg := `{"error":"Internal Server Error","message":"An unexpected error occurred","code":500}`
-					if _, err := w.Write([]byte(errorMsg)); err != nil {
-						logger.WithError(err).Error("Failed to write panic recovery response")
-					}
+					apierrors.Internal("An unexpected error occurred").WriteJSON(w)
 				}
 			}()
 
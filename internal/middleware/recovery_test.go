@@ -11,6 +11,9 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	apierrors "github.com/ethpandaops/cbt-api/internal/errors"
 )
 
 func TestRecovery(t *testing.T) {
@@ -29,7 +32,7 @@ func TestRecovery(t *testing.T) {
 			},
 			expectPanic:        true,
 			expectStatusCode:   http.StatusInternalServerError,
-			expectErrorMessage: "Internal Server Error",
+			expectErrorMessage: "An unexpected error occurred",
 			expectLogFields:    []string{"error", "path", "stack"},
 		},
 		{
@@ -39,7 +42,7 @@ func TestRecovery(t *testing.T) {
 			},
 			expectPanic:        true,
 			expectStatusCode:   http.StatusInternalServerError,
-			expectErrorMessage: "Internal Server Error",
+			expectErrorMessage: "An unexpected error occurred",
 			expectLogFields:    []string{"error", "path", "stack"},
 		},
 		{
@@ -49,7 +52,7 @@ func TestRecovery(t *testing.T) {
 			},
 			expectPanic:        true,
 			expectStatusCode:   http.StatusInternalServerError,
-			expectErrorMessage: "Internal Server Error",
+			expectErrorMessage: "An unexpected error occurred",
 			expectLogFields:    []string{"error", "path", "stack"},
 		},
 		{
@@ -131,6 +134,11 @@ func TestRecovery(t *testing.T) {
 					if tt.expectErrorMessage != "" {
 						assert.Contains(t, body, tt.expectErrorMessage)
 					}
+
+					var status apierrors.Status
+
+					require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+					assert.Equal(t, codes.Internal, status.Code)
 					// Verify Content-Type header
 					assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
 				}
@@ -258,14 +266,13 @@ func TestRecovery_ResponseFormat(t *testing.T) {
 	// Verify Content-Type
 	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
 
-	// Parse JSON response
-	var response map[string]interface{}
+	// Parse Status response
+	var status apierrors.Status
 
-	err := json.Unmarshal(rec.Body.Bytes(), &response)
+	err := json.Unmarshal(rec.Body.Bytes(), &status)
 	require.NoError(t, err)
 
 	// Verify response structure
-	assert.Equal(t, "Internal Server Error", response["error"])
-	assert.Equal(t, "An unexpected error occurred", response["message"])
-	assert.Equal(t, float64(500), response["code"])
+	assert.Equal(t, codes.Internal, status.Code)
+	assert.Equal(t, "An unexpected error occurred", status.Message)
 }
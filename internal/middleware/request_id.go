@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/ethpandaops/cbt-api/internal/requestid"
+)
+
+// RequestID returns a middleware that accepts a valid client-supplied X-Request-ID or generates one,
+// stores it in the request context and echoes it as a response header. The ID is used as the
+// ClickHouse query_id, in request logs and in error responses.
+func RequestID() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(requestid.Header)
+			if !requestid.Valid(id) {
+				id = requestid.New()
+			}
+
+			w.Header().Set(requestid.Header, id)
+
+			next.ServeHTTP(w, r.WithContext(requestid.WithContext(r.Context(), id)))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethpandaops/cbt-api/internal/requestid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name       string
+		incoming   string
+		expectSame bool
+	}{
+		{
+			name:       "accepts valid incoming ID",
+			incoming:   "edge-7f3a2b",
+			expectSame: true,
+		},
+		{
+			name: "generates ID when missing",
+		},
+		{
+			name:     "replaces invalid incoming ID",
+			incoming: "bad id with spaces",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctxID string
+
+			handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctxID = requestid.FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/fct_block", nil)
+			if tt.incoming != "" {
+				req.Header.Set(requestid.Header, tt.incoming)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			responseID := rec.Header().Get(requestid.Header)
+			assert.NotEmpty(t, responseID)
+			assert.Equal(t, responseID, ctxID, "context and response header should carry the same ID")
+
+			if tt.expectSame {
+				assert.Equal(t, tt.incoming, responseID)
+			} else {
+				assert.NotEqual(t, tt.incoming, responseID)
+				assert.True(t, requestid.Valid(responseID))
+			}
+		})
+	}
+}
@@ -1,12 +1,15 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	apierrors "github.com/ethpandaops/cbt-api/internal/errors"
 	"github.com/ethpandaops/cbt-api/internal/handlers"
@@ -20,6 +23,14 @@ import (
 // - Invalid parameter types (e.g., non-numeric value for uint32)
 // - Invalid parameter formats (e.g., pattern violations)
 //
+// order_by must list sortable columns of the table, each optionally followed by asc or desc.
+//
+// DateTime filters also accept RFC 3339 timestamps and relative expressions such as now-1h or
+// now-7d/d; they are rewritten to Unix time before the request reaches the handler.
+//
+// All problems are collected in one pass and listed as field violations in a BadRequest detail,
+// so clients can highlight every bad filter at once.
+//
 // Note: This middleware validates only query parameters, not routes/paths.
 // Route validation is handled by the http.ServeMux itself (Go 1.22+).
 func QueryParameterValidation(logger logrus.FieldLogger) func(http.Handler) http.Handler {
@@ -29,140 +40,176 @@ func QueryParameterValidation(logger logrus.FieldLogger) func(http.Handler) http
 		logger.WithError(err).Fatal("failed to load OpenAPI specification")
 	}
 
+	router := NewRouter(swagger)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Try to find the OpenAPI operation by matching path patterns
-			var operation *openapi3.Operation
-
-			for path, pathItem := range swagger.Paths.Map() {
-				if matchesPattern(r.URL.Path, path) {
-					switch r.Method {
-					case http.MethodGet:
-						operation = pathItem.Get
-					case http.MethodPost:
-						operation = pathItem.Post
-					case http.MethodPut:
-						operation = pathItem.Put
-					case http.MethodDelete:
-						operation = pathItem.Delete
-					case http.MethodPatch:
-						operation = pathItem.Patch
-					}
-
-					if operation != nil {
-						break
-					}
-				}
+			// Reuse the route resolved by RouteMatcher when present
+			route := RouteFromContext(r.Context())
+			if route == nil {
+				route = router.Lookup(r.Method, r.URL.Path)
 			}
 
-			// If we can't find the operation, let it through - the handler will return 404
-			if operation == nil {
+			// If we can't find the operation, let it through - the handler will return 404.
+			// Static routes (e.g. /health) have no OpenAPI operation to validate against.
+			if route == nil || route.Operation == nil {
 				next.ServeHTTP(w, r)
 
 				return
 			}
 
-			// Build map of parameter definitions for validation
-			paramDefs := make(map[string]*openapi3.Parameter)
-			validParams := make(map[string]bool)
+			query := r.URL.Query()
 
-			for _, paramRef := range operation.Parameters {
-				if paramRef.Value != nil && paramRef.Value.In == openapi3.ParameterInQuery {
-					paramDefs[paramRef.Value.Name] = paramRef.Value
-					validParams[paramRef.Value.Name] = true
-				}
-			}
+			if status := validateQuery(route, query); status != nil {
+				logger.WithFields(logrus.Fields{
+					"path":    r.URL.Path,
+					"message": status.Message,
+				}).Warn("parameter validation failed")
 
-			// Check for unknown query parameters
-			var unknownParams []string
+				status.WriteJSON(w)
 
-			for paramName := range r.URL.Query() {
-				if !validParams[paramName] {
-					unknownParams = append(unknownParams, paramName)
-				}
+				return
 			}
 
-			if len(unknownParams) > 0 {
-				sort.Strings(unknownParams)
+			// Rewrite DateTime filters to Unix time so the handler binds them as integers
+			if normalizeDateTimes(route, query, time.Now()) {
+				r = r.Clone(r.Context())
+				r.URL.RawQuery = query.Encode()
+			}
 
-				validParamList := make([]string, 0, len(validParams))
-				for param := range validParams {
-					validParamList = append(validParamList, param)
-				}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-				sort.Strings(validParamList)
+// Field violation reasons for query parameters.
+const (
+	reasonUnknownParameter = "UNKNOWN_PARAMETER"
+	reasonInvalidType      = "INVALID_TYPE"
+	reasonOutOfRange       = "OUT_OF_RANGE"
+	reasonInvalidFormat    = "INVALID_FORMAT"
+	reasonInvalidLength    = "INVALID_LENGTH"
+	reasonUnknownColumn    = "UNKNOWN_COLUMN"
+)
 
-				status := apierrors.BadRequestf(
-					"unknown query parameter(s): %s",
-					strings.Join(unknownParams, ", "),
-				).WithMetadata(map[string]string{
-					"unknown_parameters": strings.Join(unknownParams, ", "),
-					"valid_parameters":   strings.Join(validParamList, ", "),
-				})
+// orderBySyntax describes the order_by parameter in validation errors.
+const orderBySyntax = "comma-separated columns, each optionally followed by asc or desc"
 
-				status.WriteJSON(w)
+// validateQuery checks every query parameter against the route in one pass and returns a
+// Status listing all violations, or nil if the query is valid. Unknown parameters keep their
+// ErrorInfo detail first; every problem is listed in a BadRequest detail.
+func validateQuery(route *Route, query url.Values) *apierrors.Status {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
 
-				return
+	sort.Strings(names)
+
+	var (
+		unknownParams []string
+		messages      []string
+		violations    []apierrors.FieldViolation
+	)
+
+	for _, name := range names {
+		validate, ok := route.params[name]
+		if !ok {
+			unknownParams = append(unknownParams, name)
+			violations = append(violations, apierrors.FieldViolation{
+				Field:       name,
+				Description: "unknown query parameter",
+				Reason:      reasonUnknownParameter,
+			})
+
+			continue
+		}
+
+		for _, value := range query[name] {
+			err := validate(value)
+			if err == nil {
+				continue
 			}
 
-			// Validate parameter types and formats
-			for paramName, paramDef := range paramDefs {
-				values := r.URL.Query()[paramName]
-				if len(values) == 0 {
-					continue // Parameter not provided, skip validation
-				}
-
-				// Validate each value
-				for _, value := range values {
-					if err := validateParameterValue(paramName, value, paramDef); err != nil {
-						logger.WithFields(logrus.Fields{
-							"param": paramName,
-							"value": value,
-							"path":  r.URL.Path,
-						}).Warn("parameter validation failed")
-
-						status := apierrors.BadRequest(err.Error())
-						status.WriteJSON(w)
-
-						return
-					}
-				}
+			violation := apierrors.FieldViolation{
+				Field:       name,
+				Description: err.Error(),
+				Value:       value,
 			}
 
-			next.ServeHTTP(w, r)
-		})
+			var invalid *invalidParameterError
+			if errors.As(err, &invalid) {
+				violation.Reason = invalid.reason
+				violation.Expected = invalid.expected
+			}
+
+			messages = append(messages, err.Error())
+			violations = append(violations, violation)
+		}
 	}
-}
 
-// validateParameterValue validates a single parameter value against its OpenAPI schema.
-func validateParameterValue(paramName, value string, param *openapi3.Parameter) error {
-	if param.Schema == nil || param.Schema.Value == nil {
-		return nil // No schema to validate against
+	if len(violations) == 0 {
+		return nil
 	}
 
-	schema := param.Schema.Value
+	var status *apierrors.Status
 
-	// Get the parameter type
-	if schema.Type == nil || len(schema.Type.Slice()) == 0 {
-		return nil // No type specified
+	if len(unknownParams) > 0 {
+		messages = append([]string{"unknown query parameter(s): " + strings.Join(unknownParams, ", ")}, messages...)
+		status = apierrors.BadRequest(strings.Join(messages, "; ")).WithMetadata(map[string]string{
+			"unknown_parameters": strings.Join(unknownParams, ", "),
+			"valid_parameters":   strings.Join(route.validParams, ", "),
+		})
+	} else {
+		status = apierrors.BadRequest(strings.Join(messages, "; "))
 	}
 
-	paramType := schema.Type.Slice()[0]
-	format := schema.Format
-
-	// Validate based on type
-	switch paramType {
-	case "integer":
-		return validateIntegerParameter(paramName, value, format, schema)
-	case "number":
-		return validateNumberParameter(paramName, value, schema)
-	case "string":
-		return validateStringParameter(paramName, value, schema)
-	case "boolean":
-		return validateBooleanParameter(paramName, value)
+	return status.WithFieldViolations(violations)
+}
+
+// normalizeDateTimes rewrites RFC 3339 and relative DateTime filter values in query to Unix time,
+// resolving relative expressions once against now. It reports whether any value changed.
+// The query must already have passed validateQuery.
+func normalizeDateTimes(route *Route, query url.Values, now time.Time) bool {
+	changed := false
+
+	for name, dt := range route.dateTimes {
+		for i, value := range query[name] {
+			n, err := handlers.ParseDateTime(value, dt.format, dt.precision, now)
+			if err != nil {
+				continue
+			}
+
+			if normalized := strconv.FormatInt(n, 10); normalized != value {
+				query[name][i] = normalized
+				changed = true
+			}
+		}
 	}
 
-	return nil
+	return changed
+}
+
+// invalidParameterError is a query parameter value that failed validation, with the
+// machine-readable reason and expected type, range or pattern reported in field violations.
+type invalidParameterError struct {
+	reason   string
+	expected string
+	message  string
+}
+
+// Error returns the client-facing message.
+func (e *invalidParameterError) Error() string {
+	return e.message
+}
+
+// invalidParameter creates an invalidParameterError with a formatted message.
+func invalidParameter(reason, expected, format string, args ...any) error {
+	return &invalidParameterError{
+		reason:   reason,
+		expected: expected,
+		message:  fmt.Sprintf(format, args...),
+	}
 }
 
 // validateIntegerParameter validates integer parameters (uint32, uint64, int32, int64).
@@ -171,66 +218,74 @@ func validateIntegerParameter(paramName, value, format string, schema *openapi3.
 	case "uint32":
 		val, err := strconv.ParseUint(value, 10, 32)
 		if err != nil {
-			return fmt.Errorf("parameter '%s' must be a valid unsigned 32-bit integer", paramName)
+			return invalidParameter(reasonInvalidType, "uint32", "parameter '%s' must be a valid unsigned 32-bit integer", paramName)
 		}
 
 		// Check minimum if specified
 		if schema.Min != nil && float64(val) < *schema.Min {
-			return fmt.Errorf("parameter '%s' must be >= %v", paramName, *schema.Min)
+			return invalidParameter(reasonOutOfRange, fmt.Sprintf(">= %v", *schema.Min),
+				"parameter '%s' must be >= %v", paramName, *schema.Min)
 		}
 
 		// Check maximum if specified
 		if schema.Max != nil && float64(val) > *schema.Max {
-			return fmt.Errorf("parameter '%s' must be <= %v", paramName, *schema.Max)
+			return invalidParameter(reasonOutOfRange, fmt.Sprintf("<= %v", *schema.Max),
+				"parameter '%s' must be <= %v", paramName, *schema.Max)
 		}
 
 	case "uint64":
 		val, err := strconv.ParseUint(value, 10, 64)
 		if err != nil {
-			return fmt.Errorf("parameter '%s' must be a valid unsigned 64-bit integer", paramName)
+			return invalidParameter(reasonInvalidType, "uint64", "parameter '%s' must be a valid unsigned 64-bit integer", paramName)
 		}
 
 		if schema.Min != nil && float64(val) < *schema.Min {
-			return fmt.Errorf("parameter '%s' must be >= %v", paramName, *schema.Min)
+			return invalidParameter(reasonOutOfRange, fmt.Sprintf(">= %v", *schema.Min),
+				"parameter '%s' must be >= %v", paramName, *schema.Min)
 		}
 
 		if schema.Max != nil && float64(val) > *schema.Max {
-			return fmt.Errorf("parameter '%s' must be <= %v", paramName, *schema.Max)
+			return invalidParameter(reasonOutOfRange, fmt.Sprintf("<= %v", *schema.Max),
+				"parameter '%s' must be <= %v", paramName, *schema.Max)
 		}
 
 	case "int32":
 		val, err := strconv.ParseInt(value, 10, 32)
 		if err != nil {
-			return fmt.Errorf("parameter '%s' must be a valid signed 32-bit integer", paramName)
+			return invalidParameter(reasonInvalidType, "int32", "parameter '%s' must be a valid signed 32-bit integer", paramName)
 		}
 
 		if schema.Min != nil && float64(val) < *schema.Min {
-			return fmt.Errorf("parameter '%s' must be >= %v", paramName, *schema.Min)
+			return invalidParameter(reasonOutOfRange, fmt.Sprintf(">= %v", *schema.Min),
+				"parameter '%s' must be >= %v", paramName, *schema.Min)
 		}
 
 		if schema.Max != nil && float64(val) > *schema.Max {
-			return fmt.Errorf("parameter '%s' must be <= %v", paramName, *schema.Max)
+			return invalidParameter(reasonOutOfRange, fmt.Sprintf("<= %v", *schema.Max),
+				"parameter '%s' must be <= %v", paramName, *schema.Max)
 		}
 
 	case "int64":
 		val, err := strconv.ParseInt(value, 10, 64)
 		if err != nil {
-			return fmt.Errorf("parameter '%s' must be a valid signed 64-bit integer", paramName)
+			return invalidParameter(reasonInvalidType, "int64", "parameter '%s' must be a valid signed 64-bit integer", paramName)
 		}
 
 		if schema.Min != nil && float64(val) < *schema.Min {
-			return fmt.Errorf("parameter '%s' must be >= %v", paramName, *schema.Min)
+			return invalidParameter(reasonOutOfRange, fmt.Sprintf(">= %v", *schema.Min),
+				"parameter '%s' must be >= %v", paramName, *schema.Min)
 		}
 
 		if schema.Max != nil && float64(val) > *schema.Max {
-			return fmt.Errorf("parameter '%s' must be <= %v", paramName, *schema.Max)
+			return invalidParameter(reasonOutOfRange, fmt.Sprintf("<= %v", *schema.Max),
+				"parameter '%s' must be <= %v", paramName, *schema.Max)
 		}
 
 	default:
 		// Generic integer validation
 		_, err := strconv.ParseInt(value, 10, 64)
 		if err != nil {
-			return fmt.Errorf("parameter '%s' must be a valid integer", paramName)
+			return invalidParameter(reasonInvalidType, "integer", "parameter '%s' must be a valid integer", paramName)
 		}
 	}
 
@@ -241,42 +296,49 @@ func validateIntegerParameter(paramName, value, format string, schema *openapi3.
 func validateNumberParameter(paramName, value string, schema *openapi3.Schema) error {
 	val, err := strconv.ParseFloat(value, 64)
 	if err != nil {
-		return fmt.Errorf("parameter '%s' must be a valid number", paramName)
+		return invalidParameter(reasonInvalidType, "number", "parameter '%s' must be a valid number", paramName)
 	}
 
 	if schema.Min != nil && val < *schema.Min {
-		return fmt.Errorf("parameter '%s' must be >= %v", paramName, *schema.Min)
+		return invalidParameter(reasonOutOfRange, fmt.Sprintf(">= %v", *schema.Min),
+			"parameter '%s' must be >= %v", paramName, *schema.Min)
 	}
 
 	if schema.Max != nil && val > *schema.Max {
-		return fmt.Errorf("parameter '%s' must be <= %v", paramName, *schema.Max)
+		return invalidParameter(reasonOutOfRange, fmt.Sprintf("<= %v", *schema.Max),
+			"parameter '%s' must be <= %v", paramName, *schema.Max)
 	}
 
 	return nil
 }
 
 // validateStringParameter validates string parameters with pattern, minLength, maxLength.
-func validateStringParameter(paramName, value string, schema *openapi3.Schema) error {
+// The pattern is precompiled; patternErr is set if the schema pattern failed to compile.
+func validateStringParameter(
+	paramName, value string,
+	schema *openapi3.Schema,
+	pattern *regexp.Regexp,
+	patternErr error,
+) error {
 	// Check pattern if specified
-	if schema.Pattern != "" {
-		matched, err := regexp.MatchString(schema.Pattern, value)
-		if err != nil {
-			return fmt.Errorf("parameter '%s' has invalid pattern in schema", paramName)
-		}
+	if patternErr != nil {
+		return invalidParameter(reasonInvalidFormat, "", "parameter '%s' has invalid pattern in schema", paramName)
+	}
 
-		if !matched {
-			return fmt.Errorf("parameter '%s' has invalid format", paramName)
-		}
+	if pattern != nil && !pattern.MatchString(value) {
+		return invalidParameter(reasonInvalidFormat, pattern.String(), "parameter '%s' has invalid format", paramName)
 	}
 
 	// Check minLength if specified
 	if schema.MinLength > 0 && uint64(len(value)) < schema.MinLength {
-		return fmt.Errorf("parameter '%s' must be at least %d characters", paramName, schema.MinLength)
+		return invalidParameter(reasonInvalidLength, fmt.Sprintf(">= %d characters", schema.MinLength),
+			"parameter '%s' must be at least %d characters", paramName, schema.MinLength)
 	}
 
 	// Check maxLength if specified
 	if schema.MaxLength != nil && uint64(len(value)) > *schema.MaxLength {
-		return fmt.Errorf("parameter '%s' must be at most %d characters", paramName, *schema.MaxLength)
+		return invalidParameter(reasonInvalidLength, fmt.Sprintf("<= %d characters", *schema.MaxLength),
+			"parameter '%s' must be at most %d characters", paramName, *schema.MaxLength)
 	}
 
 	return nil
@@ -286,36 +348,8 @@ func validateStringParameter(paramName, value string, schema *openapi3.Schema) e
 func validateBooleanParameter(paramName, value string) error {
 	_, err := strconv.ParseBool(value)
 	if err != nil {
-		return fmt.Errorf("parameter '%s' must be a valid boolean (true or false)", paramName)
+		return invalidParameter(reasonInvalidType, "boolean", "parameter '%s' must be a valid boolean (true or false)", paramName)
 	}
 
 	return nil
 }
-
-// matchesPattern checks if a request path matches an OpenAPI path pattern.
-// Example: "/api/v1/fct_block/123" matches "/api/v1/fct_block/{slot_start_date_time}".
-func matchesPattern(requestPath, patternPath string) bool {
-	// Split paths into segments
-	requestParts := strings.Split(strings.Trim(requestPath, "/"), "/")
-	patternParts := strings.Split(strings.Trim(patternPath, "/"), "/")
-
-	// Must have same number of segments
-	if len(requestParts) != len(patternParts) {
-		return false
-	}
-
-	// Match each segment
-	for i := range requestParts {
-		// Pattern segments starting with { are wildcards (path parameters)
-		if strings.HasPrefix(patternParts[i], "{") && strings.HasSuffix(patternParts[i], "}") {
-			continue
-		}
-
-		// Otherwise must be exact match
-		if requestParts[i] != patternParts[i] {
-			return false
-		}
-	}
-
-	return true
-}
@@ -2,12 +2,19 @@ package middleware
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	apierrors "github.com/ethpandaops/cbt-api/internal/errors"
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -182,3 +189,566 @@ func TestQueryParameterValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryParameterValidation_FieldViolations(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	handler := QueryParameterValidation(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(
+		http.MethodGet,
+		"/api/v1/fct_data_types_integers?timestamp_eq=abc&id_gtw=1&timestamp_gte=-1",
+		nil,
+	)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var status apierrors.Status
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Contains(t, status.Message, "unknown query parameter(s): id_gtw")
+	assert.Contains(t, status.Message, "parameter 'timestamp_eq' must be a valid unsigned 32-bit integer")
+	assert.Contains(t, status.Message, "parameter 'timestamp_gte' must be a valid unsigned 32-bit integer")
+
+	require.Len(t, status.Details, 2)
+	assert.Equal(t, "type.googleapis.com/ErrorInfo", status.Details[0]["@type"], "ErrorInfo stays first")
+	assert.Equal(t, "type.googleapis.com/BadRequest", status.Details[1]["@type"])
+
+	violations, ok := status.Details[1]["fieldViolations"].([]any)
+	require.True(t, ok)
+	require.Len(t, violations, 3)
+
+	assert.Equal(t, map[string]any{
+		"field":       "id_gtw",
+		"description": "unknown query parameter",
+		"reason":      "UNKNOWN_PARAMETER",
+	}, violations[0])
+	assert.Equal(t, map[string]any{
+		"field":       "timestamp_eq",
+		"description": "parameter 'timestamp_eq' must be a valid unsigned 32-bit integer",
+		"reason":      "INVALID_TYPE",
+		"value":       "abc",
+		"expected":    "uint32",
+	}, violations[1])
+	assert.Equal(t, "timestamp_gte", violations[2].(map[string]any)["field"])
+}
+
+func TestQueryParameterValidation_DateTime(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	swagger := &openapi3.T{Paths: openapi3.NewPaths()}
+	swagger.Paths.Set("/api/v1/fct_block", &openapi3.PathItem{Get: &openapi3.Operation{
+		OperationID: "FctBlockService_List",
+		Parameters: openapi3.Parameters{
+			{Value: &openapi3.Parameter{
+				Name:       "slot_start_date_time_gte",
+				In:         openapi3.ParameterInQuery,
+				Schema:     &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+				Extensions: map[string]any{"x-datetime-format": "uint32"},
+			}},
+			{Value: &openapi3.Parameter{
+				Name:       "event_date_time_lt",
+				In:         openapi3.ParameterInQuery,
+				Schema:     &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+				Extensions: map[string]any{"x-datetime-format": "int64", "x-datetime-precision": float64(3)},
+			}},
+		},
+	}})
+
+	var query url.Values
+
+	handler := RouteMatcher(NewRouter(swagger))(QueryParameterValidation(logger)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query = r.URL.Query()
+
+			w.WriteHeader(http.StatusOK)
+		}),
+	))
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expected       url.Values
+	}{
+		{
+			name:           "unix time is passed through",
+			query:          "slot_start_date_time_gte=1704067200",
+			expectedStatus: http.StatusOK,
+			expected:       url.Values{"slot_start_date_time_gte": {"1704067200"}},
+		},
+		{
+			name:           "rfc 3339 is normalized",
+			query:          "slot_start_date_time_gte=2024-01-01T00:00:00Z&event_date_time_lt=2024-01-01T00:00:00.25Z",
+			expectedStatus: http.StatusOK,
+			expected: url.Values{
+				"slot_start_date_time_gte": {"1704067200"},
+				"event_date_time_lt":       {"1704067200250"},
+			},
+		},
+		{
+			name:           "invalid value",
+			query:          "slot_start_date_time_gte=yesterday",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "out of range",
+			query:          "slot_start_date_time_gte=1960-01-01T00:00:00Z",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query = nil
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/fct_block?"+tt.query, nil))
+
+			require.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expected != nil {
+				assert.Equal(t, tt.expected, query)
+			}
+		})
+	}
+
+	t.Run("relative expression is resolved", func(t *testing.T) {
+		before := time.Now().Add(-time.Hour).Unix()
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/fct_block?slot_start_date_time_gte=now-1h", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		got, err := strconv.ParseInt(query.Get("slot_start_date_time_gte"), 10, 64)
+		require.NoError(t, err)
+		assert.InDelta(t, before, got, 2)
+	})
+
+	t.Run("invalid value is reported with the accepted formats", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/fct_block?slot_start_date_time_gte=2024-01-01", nil))
+
+		var status apierrors.Status
+
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+		assert.Contains(t, status.Message, "parameter 'slot_start_date_time_gte' must be Unix time, RFC 3339")
+	})
+}
+
+func TestQueryParameterValidation_OrderBy(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	swagger := &openapi3.T{Paths: openapi3.NewPaths()}
+	swagger.Paths.Set("/api/v1/fct_block", &openapi3.PathItem{Get: &openapi3.Operation{
+		OperationID: "FctBlockService_List",
+		Parameters: openapi3.Parameters{
+			{Value: &openapi3.Parameter{
+				Name:       "order_by",
+				In:         openapi3.ParameterInQuery,
+				Schema:     &openapi3.SchemaRef{Value: &openapi3.Schema{Type: &openapi3.Types{"string"}}},
+				Extensions: map[string]any{"x-sortable-columns": []any{"block_root", "slot", "slot_start_date_time"}},
+			}},
+		},
+	}})
+
+	handler := RouteMatcher(NewRouter(swagger))(QueryParameterValidation(logger)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	))
+
+	tests := []struct {
+		name           string
+		orderBy        string
+		expectedStatus int
+		expectedReason string
+	}{
+		{name: "single column", orderBy: "slot", expectedStatus: http.StatusOK},
+		{name: "columns with directions", orderBy: "slot desc,block_root asc", expectedStatus: http.StatusOK},
+		{name: "case-insensitive direction", orderBy: "slot_start_date_time DESC, slot", expectedStatus: http.StatusOK},
+		{name: "unknown column", orderBy: "slot desc,proposer asc", expectedStatus: http.StatusBadRequest, expectedReason: "UNKNOWN_COLUMN"},
+		{name: "invalid direction", orderBy: "slot down", expectedStatus: http.StatusBadRequest, expectedReason: "INVALID_FORMAT"},
+		{name: "empty term", orderBy: "slot,", expectedStatus: http.StatusBadRequest, expectedReason: "INVALID_FORMAT"},
+		{name: "duplicate column", orderBy: "slot asc,slot desc", expectedStatus: http.StatusBadRequest, expectedReason: "INVALID_FORMAT"},
+		{name: "sql is rejected", orderBy: "slot; DROP TABLE fct_block", expectedStatus: http.StatusBadRequest, expectedReason: "INVALID_FORMAT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+				"/api/v1/fct_block?order_by="+url.QueryEscape(tt.orderBy), nil))
+
+			require.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedReason == "" {
+				return
+			}
+
+			var status apierrors.Status
+
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+
+			violations, ok := status.Details[0]["fieldViolations"].([]any)
+			require.True(t, ok)
+			require.Len(t, violations, 1)
+			assert.Equal(t, tt.expectedReason, violations[0].(map[string]any)["reason"])
+		})
+	}
+
+	t.Run("unknown column lists the sortable columns", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/fct_block?order_by=proposer", nil))
+
+		var status apierrors.Status
+
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+		assert.Contains(t, status.Message, "parameter 'order_by' cannot sort by unknown column 'proposer'")
+
+		violations, ok := status.Details[0]["fieldViolations"].([]any)
+		require.True(t, ok)
+		assert.Equal(t, "block_root, slot, slot_start_date_time", violations[0].(map[string]any)["expected"])
+	})
+}
+
+func TestRouterLookup(t *testing.T) {
+	swagger := benchmarkSwagger(3)
+	swagger.Paths.Set("/api/v1/fct_table_2/distinct/{column}", &openapi3.PathItem{
+		Get: &openapi3.Operation{
+			OperationID: "fct_table_2_Distinct",
+			Parameters: openapi3.Parameters{
+				{Value: &openapi3.Parameter{Name: "column", In: openapi3.ParameterInPath}},
+			},
+		},
+	})
+
+	router := NewRouter(swagger)
+
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		expectedTemplate string
+	}{
+		{
+			name:             "static list route",
+			method:           http.MethodGet,
+			path:             "/api/v1/fct_table_1",
+			expectedTemplate: "/api/v1/fct_table_1",
+		},
+		{
+			name:             "trailing slash",
+			method:           http.MethodGet,
+			path:             "/api/v1/fct_table_1/",
+			expectedTemplate: "/api/v1/fct_table_1",
+		},
+		{
+			name:             "path parameter route",
+			method:           http.MethodGet,
+			path:             "/api/v1/fct_table_2/12345",
+			expectedTemplate: "/api/v1/fct_table_2/{slot}",
+		},
+		{
+			name:             "static segment under a path parameter",
+			method:           http.MethodGet,
+			path:             "/api/v1/fct_table_2/distinct/status",
+			expectedTemplate: "/api/v1/fct_table_2/distinct/{column}",
+		},
+		{
+			name:             "path parameter matching a static segment",
+			method:           http.MethodGet,
+			path:             "/api/v1/fct_table_2/distinct",
+			expectedTemplate: "/api/v1/fct_table_2/{slot}",
+		},
+		{
+			name:   "unknown table",
+			method: http.MethodGet,
+			path:   "/api/v1/does_not_exist",
+		},
+		{
+			name:   "too many segments",
+			method: http.MethodGet,
+			path:   "/api/v1/fct_table_2/12345/extra",
+		},
+		{
+			name:   "method not in spec",
+			method: http.MethodPost,
+			path:   "/api/v1/fct_table_1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := router.Lookup(tt.method, tt.path)
+
+			if tt.expectedTemplate == "" {
+				assert.Nil(t, route)
+
+				return
+			}
+
+			require.NotNil(t, route)
+			assert.Equal(t, tt.expectedTemplate, route.Template)
+			assert.Equal(t, tt.method, route.Method)
+		})
+	}
+}
+
+func TestRouterTables(t *testing.T) {
+	router := NewRouter(benchmarkSwagger(3))
+	router.AddStatic(http.MethodGet, "/health", "health")
+
+	assert.Equal(t, []string{"fct_table_0", "fct_table_1", "fct_table_2"}, router.Tables())
+}
+
+func TestRouterTables_Alias(t *testing.T) {
+	swagger := &openapi3.T{Paths: openapi3.NewPaths()}
+	swagger.Paths.Set("/api/v1/blocks", &openapi3.PathItem{Get: &openapi3.Operation{
+		OperationID: "FctBlockService_List",
+		Extensions:  map[string]any{"x-table": "fct_block"},
+	}})
+	swagger.Paths.Set("/api/v1/blocks/{slot}", &openapi3.PathItem{Get: &openapi3.Operation{
+		OperationID: "FctBlockService_Get",
+		Extensions:  map[string]any{"x-table": "fct_block"},
+	}})
+	swagger.Paths.Set("/api/v1/blocks/distinct/{column}", &openapi3.PathItem{Get: &openapi3.Operation{
+		OperationID: "FctBlockService_Distinct",
+		Extensions:  map[string]any{"x-table": "fct_block"},
+	}})
+
+	router := NewRouter(swagger)
+
+	route := router.Lookup(http.MethodGet, "/api/v1/blocks")
+	require.NotNil(t, route)
+	assert.Equal(t, "fct_block", route.Table)
+	assert.Equal(t, []string{"fct_block"}, router.Tables())
+
+	// The distinct segment takes precedence over the Get path parameter
+	route = router.Lookup(http.MethodGet, "/api/v1/blocks/distinct/meta_client_name")
+	require.NotNil(t, route)
+	assert.Equal(t, "/api/v1/blocks/distinct/{column}", route.Template)
+	assert.Equal(t, "fct_block", route.Table)
+	assert.Equal(t, "Distinct", route.OperationName)
+	assert.Equal(t, "/api/v1/blocks/{slot}", router.Lookup(http.MethodGet, "/api/v1/blocks/42").Template)
+}
+
+func TestCompileParameterValidator_Pattern(t *testing.T) {
+	validate := compileParameterValidator(&openapi3.Parameter{
+		Name: "block_root_eq",
+		Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{
+			Type:    &openapi3.Types{"string"},
+			Pattern: "^0x[0-9a-f]+$",
+		}},
+	})
+
+	require.NoError(t, validate("0xabc"))
+	require.EqualError(t, validate("abc"), "parameter 'block_root_eq' has invalid format")
+
+	invalid := compileParameterValidator(&openapi3.Parameter{
+		Name: "broken",
+		Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{
+			Type:    &openapi3.Types{"string"},
+			Pattern: "([",
+		}},
+	})
+
+	require.EqualError(t, invalid("x"), "parameter 'broken' has invalid pattern in schema")
+}
+
+// benchmarkTables approximates the number of tables exposed in production.
+const benchmarkTables = 300
+
+// benchmarkSwagger builds a spec with a List and Get operation for n tables.
+func benchmarkSwagger(n int) *openapi3.T {
+	paths := openapi3.NewPaths()
+
+	for i := range n {
+		table := fmt.Sprintf("fct_table_%d", i)
+
+		listParams := openapi3.Parameters{
+			queryParam("slot_eq", "integer", "uint32", ""),
+			queryParam("slot_gte", "integer", "uint32", ""),
+			queryParam("block_root_eq", "string", "", "^0x[0-9a-f]{64}$"),
+			queryParam("page_size", "integer", "uint32", ""),
+			queryParam("page_token", "string", "", ""),
+		}
+
+		paths.Set("/api/v1/"+table, &openapi3.PathItem{
+			Get: &openapi3.Operation{OperationID: table + "_List", Parameters: listParams},
+		})
+		paths.Set("/api/v1/"+table+"/{slot}", &openapi3.PathItem{
+			Get: &openapi3.Operation{
+				OperationID: table + "_Get",
+				Parameters: openapi3.Parameters{
+					{Value: &openapi3.Parameter{Name: "slot", In: openapi3.ParameterInPath}},
+				},
+			},
+		})
+	}
+
+	return &openapi3.T{Paths: paths}
+}
+
+// queryParam builds a query parameter definition for benchmark specs.
+func queryParam(name, typ, format, pattern string) *openapi3.ParameterRef {
+	return &openapi3.ParameterRef{Value: &openapi3.Parameter{
+		Name: name,
+		In:   openapi3.ParameterInQuery,
+		Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{
+			Type:    &openapi3.Types{typ},
+			Format:  format,
+			Pattern: pattern,
+		}},
+	}}
+}
+
+// linearScanValidate reproduces the previous per-request behaviour: scan every path
+// template, split both paths, and compile string patterns on each validation.
+func linearScanValidate(swagger *openapi3.T, method, path string, query url.Values) error {
+	var operation *openapi3.Operation
+
+	for template, pathItem := range swagger.Paths.Map() {
+		requestParts := strings.Split(strings.Trim(path, "/"), "/")
+		patternParts := strings.Split(strings.Trim(template, "/"), "/")
+
+		if len(requestParts) != len(patternParts) {
+			continue
+		}
+
+		matched := true
+
+		for i := range requestParts {
+			if strings.HasPrefix(patternParts[i], "{") {
+				continue
+			}
+
+			if requestParts[i] != patternParts[i] {
+				matched = false
+
+				break
+			}
+		}
+
+		if matched {
+			operation = pathItem.GetOperation(method)
+
+			break
+		}
+	}
+
+	if operation == nil {
+		return nil
+	}
+
+	for _, paramRef := range operation.Parameters {
+		schema := paramRef.Value.Schema.Value
+
+		for _, value := range query[paramRef.Value.Name] {
+			if schema.Pattern != "" {
+				if ok, _ := regexp.MatchString(schema.Pattern, value); !ok {
+					return fmt.Errorf("parameter '%s' has invalid format", paramRef.Value.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+var benchmarkQuery = url.Values{
+	"slot_gte":      {"100"},
+	"block_root_eq": {"0x" + strings.Repeat("ab", 32)},
+}
+
+func BenchmarkRouteValidation_LinearScan(b *testing.B) {
+	swagger := benchmarkSwagger(benchmarkTables)
+	path := fmt.Sprintf("/api/v1/fct_table_%d", benchmarkTables-1)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		_ = linearScanValidate(swagger, http.MethodGet, path, benchmarkQuery)
+	}
+}
+
+func BenchmarkRouteValidation_Precompiled(b *testing.B) {
+	router := NewRouter(benchmarkSwagger(benchmarkTables))
+	path := fmt.Sprintf("/api/v1/fct_table_%d", benchmarkTables-1)
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		route := router.Lookup(http.MethodGet, path)
+
+		for name, values := range benchmarkQuery {
+			for _, value := range values {
+				_ = route.params[name](value)
+			}
+		}
+	}
+}
+
+func TestRouteMatcher(t *testing.T) {
+	router := NewRouter(benchmarkSwagger(1))
+	router.AddStatic(http.MethodGet, "/health", "health")
+
+	tests := []struct {
+		name              string
+		path              string
+		expectedRoute     string
+		expectedTable     string
+		expectedOperation string
+	}{
+		{
+			name:              "list route",
+			path:              "/api/v1/fct_table_0?slot_eq=1",
+			expectedRoute:     "/api/v1/fct_table_0",
+			expectedTable:     "fct_table_0",
+			expectedOperation: "List",
+		},
+		{
+			name:              "get route collapses keys into template",
+			path:              "/api/v1/fct_table_0/9876",
+			expectedRoute:     "/api/v1/fct_table_0/{slot}",
+			expectedTable:     "fct_table_0",
+			expectedOperation: "Get",
+		},
+		{
+			name:              "static route",
+			path:              "/health",
+			expectedRoute:     "/health",
+			expectedOperation: "health",
+		},
+		{
+			name:          "unmatched path",
+			path:          "/wp-admin/login.php",
+			expectedRoute: UnmatchedRoute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var route, table, operation string
+
+			handler := RouteMatcher(router)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				route, table, operation = RouteLabels(r.Context())
+			}))
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expectedRoute, route)
+			assert.Equal(t, tt.expectedTable, table)
+			assert.Equal(t, tt.expectedOperation, operation)
+		})
+	}
+}
@@ -0,0 +1,432 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethpandaops/cbt-api/internal/handlers"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// UnmatchedRoute is the label used for requests that match no known route, bounding metric cardinality.
+const UnmatchedRoute = "unmatched"
+
+// Route is an OpenAPI operation resolved for a method and path template.
+// Query parameter validators are compiled once when the route is built.
+type Route struct {
+	Method        string
+	Template      string // "/api/v1/fct_block/{slot_start_date_time}"
+	Table         string // "fct_block"
+	OperationName string // "List", "Get", or a static route name such as "health"
+	Operation     *openapi3.Operation
+
+	params      map[string]parameterValidator
+	dateTimes   map[string]dateTimeParameter // DateTime filters normalized to Unix time
+	validParams []string                     // sorted, for error responses
+}
+
+// dateTimeParameter is a DateTime filter parameter, marked by openapi-preprocess with
+// x-datetime-format (the integer format of the column's filter) and x-datetime-precision.
+type dateTimeParameter struct {
+	format    string
+	precision int
+}
+
+// Router resolves request paths to OpenAPI routes using a segment trie built at startup.
+type Router struct {
+	root   *routeNode
+	tables map[string]struct{}
+}
+
+// routeNode is a single path segment in the routing trie.
+type routeNode struct {
+	children map[string]*routeNode
+	wildcard *routeNode // path parameter segment, e.g. "{slot}"
+	routes   map[string]*Route
+}
+
+// NewRouter builds a Router for every operation in the OpenAPI specification.
+func NewRouter(swagger *openapi3.T) *Router {
+	router := &Router{root: newRouteNode(), tables: make(map[string]struct{})}
+
+	if swagger == nil || swagger.Paths == nil {
+		return router
+	}
+
+	for template, pathItem := range swagger.Paths.Map() {
+		for method, operation := range pathItem.Operations() {
+			router.add(method, template, operation)
+		}
+	}
+
+	return router
+}
+
+// AddStatic registers a route that is not described by the OpenAPI specification, such as /health.
+func (rt *Router) AddStatic(method, path, name string) {
+	rt.insert(method, path, &Route{
+		Method:        method,
+		Template:      path,
+		OperationName: name,
+		params:        make(map[string]parameterValidator),
+	})
+}
+
+// Lookup returns the route matching the request method and path, or nil if none matches. Static
+// segments take precedence over path parameters, falling back to the parameter when the static
+// subtree has no match, so /blocks/distinct still matches /blocks/{slot} when only
+// /blocks/distinct/{column} is registered.
+func (rt *Router) Lookup(method, path string) *Route {
+	return rt.root.lookup(method, splitPath(path))
+}
+
+// lookup matches the remaining path segments below the node.
+func (n *routeNode) lookup(method string, segments []string) *Route {
+	if len(segments) == 0 {
+		return n.routes[method]
+	}
+
+	if next, ok := n.children[segments[0]]; ok {
+		if route := next.lookup(method, segments[1:]); route != nil {
+			return route
+		}
+	}
+
+	if n.wildcard == nil {
+		return nil
+	}
+
+	return n.wildcard.lookup(method, segments[1:])
+}
+
+// Tables returns the sorted names of the tables exposed by the OpenAPI routes.
+func (rt *Router) Tables() []string {
+	tables := make([]string, 0, len(rt.tables))
+	for table := range rt.tables {
+		tables = append(tables, table)
+	}
+
+	sort.Strings(tables)
+
+	return tables
+}
+
+// add registers an operation under the given method and path template.
+func (rt *Router) add(method, template string, operation *openapi3.Operation) {
+	route := newRoute(method, template, operation)
+	if route.Table != "" {
+		rt.tables[route.Table] = struct{}{}
+	}
+
+	rt.insert(method, template, route)
+}
+
+// insert places a route in the trie under the given method and path template.
+func (rt *Router) insert(method, template string, route *Route) {
+	node := rt.root
+
+	for _, segment := range splitPath(template) {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			if node.wildcard == nil {
+				node.wildcard = newRouteNode()
+			}
+
+			node = node.wildcard
+
+			continue
+		}
+
+		next, ok := node.children[segment]
+		if !ok {
+			next = newRouteNode()
+			node.children[segment] = next
+		}
+
+		node = next
+	}
+
+	node.routes[method] = route
+}
+
+// newRouteNode creates an empty trie node.
+func newRouteNode() *routeNode {
+	return &routeNode{
+		children: make(map[string]*routeNode),
+		routes:   make(map[string]*Route),
+	}
+}
+
+// newRoute builds a Route and compiles validators for its query parameters.
+func newRoute(method, template string, operation *openapi3.Operation) *Route {
+	route := &Route{
+		Method:        method,
+		Template:      template,
+		Table:         tableFromOperation(template, operation),
+		OperationName: operationName(operation.OperationID),
+		Operation:     operation,
+		params:        make(map[string]parameterValidator),
+		dateTimes:     make(map[string]dateTimeParameter),
+	}
+
+	for _, paramRef := range operation.Parameters {
+		if paramRef.Value == nil || paramRef.Value.In != openapi3.ParameterInQuery {
+			continue
+		}
+
+		if dt, ok := dateTimeFromParameter(paramRef.Value); ok {
+			route.dateTimes[paramRef.Value.Name] = dt
+			route.params[paramRef.Value.Name] = compileDateTimeValidator(paramRef.Value.Name, dt)
+		} else if columns, ok := sortableColumnsFromParameter(paramRef.Value); ok {
+			route.params[paramRef.Value.Name] = compileOrderByValidator(paramRef.Value.Name, columns)
+		} else {
+			route.params[paramRef.Value.Name] = compileParameterValidator(paramRef.Value)
+		}
+
+		route.validParams = append(route.validParams, paramRef.Value.Name)
+	}
+
+	sort.Strings(route.validParams)
+
+	return route
+}
+
+// tableFromOperation returns the table named by the operation's x-table extension, set by
+// openapi-preprocess when the path uses an alias, falling back to the path template.
+func tableFromOperation(template string, operation *openapi3.Operation) string {
+	if table, ok := operation.Extensions["x-table"].(string); ok && table != "" {
+		return table
+	}
+
+	return tableFromTemplate(template)
+}
+
+// tableFromTemplate returns the last literal segment of a path template.
+// e.g. "/api/v1/fct_block/{slot}" → "fct_block".
+func tableFromTemplate(template string) string {
+	segments := splitPath(template)
+
+	for i := len(segments) - 1; i >= 0; i-- {
+		if !strings.HasPrefix(segments[i], "{") {
+			return segments[i]
+		}
+	}
+
+	return ""
+}
+
+// operationName returns the method part of an operation ID.
+// e.g. "FctBlockService_List" → "List".
+func operationName(operationID string) string {
+	if i := strings.LastIndex(operationID, "_"); i >= 0 {
+		return operationID[i+1:]
+	}
+
+	return operationID
+}
+
+// splitPath splits a path into its segments, ignoring leading and trailing slashes.
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
+
+// parameterValidator validates a single query parameter value.
+type parameterValidator func(value string) error
+
+// compileParameterValidator builds a validator for a query parameter from its OpenAPI schema.
+// Patterns are compiled once here rather than on every request.
+func compileParameterValidator(param *openapi3.Parameter) parameterValidator {
+	paramName := param.Name
+
+	if param.Schema == nil || param.Schema.Value == nil {
+		return func(string) error { return nil } // No schema to validate against
+	}
+
+	schema := param.Schema.Value
+
+	if schema.Type == nil || len(schema.Type.Slice()) == 0 {
+		return func(string) error { return nil } // No type specified
+	}
+
+	switch schema.Type.Slice()[0] {
+	case "integer":
+		return func(value string) error {
+			return validateIntegerParameter(paramName, value, schema.Format, schema)
+		}
+	case "number":
+		return func(value string) error {
+			return validateNumberParameter(paramName, value, schema)
+		}
+	case "string":
+		var (
+			pattern    *regexp.Regexp
+			patternErr error
+		)
+
+		if schema.Pattern != "" {
+			pattern, patternErr = regexp.Compile(schema.Pattern)
+		}
+
+		return func(value string) error {
+			return validateStringParameter(paramName, value, schema, pattern, patternErr)
+		}
+	case "boolean":
+		return func(value string) error {
+			return validateBooleanParameter(paramName, value)
+		}
+	}
+
+	return func(string) error { return nil }
+}
+
+// dateTimeFromParameter returns the DateTime encoding of a parameter marked with x-datetime-format.
+func dateTimeFromParameter(param *openapi3.Parameter) (dateTimeParameter, bool) {
+	format, ok := param.Extensions["x-datetime-format"].(string)
+	if !ok || format == "" {
+		return dateTimeParameter{}, false
+	}
+
+	dt := dateTimeParameter{format: format}
+
+	// Extensions decoded from JSON hold numbers as float64
+	switch precision := param.Extensions["x-datetime-precision"].(type) {
+	case float64:
+		dt.precision = int(precision)
+	case int:
+		dt.precision = precision
+	}
+
+	return dt, true
+}
+
+// compileDateTimeValidator builds a validator for a DateTime filter, which accepts Unix time,
+// RFC 3339 timestamps and relative expressions such as "now-1h".
+func compileDateTimeValidator(paramName string, dt dateTimeParameter) parameterValidator {
+	return func(value string) error {
+		_, err := handlers.ParseDateTime(value, dt.format, dt.precision, time.Now())
+		if err == nil {
+			return nil
+		}
+
+		if errors.Is(err, handlers.ErrInvalidDateTime) {
+			return invalidParameter(reasonInvalidFormat, handlers.DateTimeFormats,
+				"parameter '%s' must be %s", paramName, handlers.DateTimeFormats)
+		}
+
+		return invalidParameter(reasonOutOfRange, "", "parameter '%s' %s", paramName, err)
+	}
+}
+
+// sortableColumnsFromParameter returns the columns listed in an order_by parameter's
+// x-sortable-columns extension, set by openapi-preprocess.
+func sortableColumnsFromParameter(param *openapi3.Parameter) ([]string, bool) {
+	// Extensions decoded from JSON hold arrays as []any
+	switch columns := param.Extensions["x-sortable-columns"].(type) {
+	case []string:
+		return columns, len(columns) > 0
+	case []any:
+		names := make([]string, 0, len(columns))
+
+		for _, column := range columns {
+			if name, ok := column.(string); ok {
+				names = append(names, name)
+			}
+		}
+
+		return names, len(names) > 0
+	}
+
+	return nil, false
+}
+
+// compileOrderByValidator builds a validator for an order_by parameter, a comma-separated list of
+// sortable columns each optionally followed by asc or desc, e.g. "slot desc,block_root asc".
+func compileOrderByValidator(paramName string, columns []string) parameterValidator {
+	sortable := make(map[string]struct{}, len(columns))
+	for _, column := range columns {
+		sortable[column] = struct{}{}
+	}
+
+	expectedColumns := strings.Join(columns, ", ")
+
+	return func(value string) error {
+		seen := make(map[string]struct{})
+
+		for _, term := range strings.Split(value, ",") {
+			fields := strings.Fields(term)
+			if len(fields) == 0 || len(fields) > 2 {
+				return invalidParameter(reasonInvalidFormat, orderBySyntax,
+					"parameter '%s' must be %s", paramName, orderBySyntax)
+			}
+
+			if len(fields) == 2 && !strings.EqualFold(fields[1], "asc") && !strings.EqualFold(fields[1], "desc") {
+				return invalidParameter(reasonInvalidFormat, orderBySyntax,
+					"parameter '%s' has invalid direction '%s', must be asc or desc", paramName, fields[1])
+			}
+
+			column := fields[0]
+			if _, ok := sortable[column]; !ok {
+				return invalidParameter(reasonUnknownColumn, expectedColumns,
+					"parameter '%s' cannot sort by unknown column '%s'", paramName, column)
+			}
+
+			if _, ok := seen[column]; ok {
+				return invalidParameter(reasonInvalidFormat, orderBySyntax,
+					"parameter '%s' lists column '%s' more than once", paramName, column)
+			}
+
+			seen[column] = struct{}{}
+		}
+
+		return nil
+	}
+}
+
+// routeKey is the context key for the matched route.
+type routeKey struct{}
+
+// WithRoute returns a context carrying the matched route.
+func WithRoute(ctx context.Context, route *Route) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+// RouteFromContext returns the route matched for the request, or nil if none matched.
+func RouteFromContext(ctx context.Context) *Route {
+	route, _ := ctx.Value(routeKey{}).(*Route)
+
+	return route
+}
+
+// RouteLabels returns the route template, table and operation labels for a request.
+// Unmatched requests collapse into a single "unmatched" route so metric cardinality stays bounded.
+func RouteLabels(ctx context.Context) (route, table, operation string) {
+	matched := RouteFromContext(ctx)
+	if matched == nil {
+		return UnmatchedRoute, "", ""
+	}
+
+	return matched.Template, matched.Table, matched.OperationName
+}
+
+// RouteMatcher returns a middleware that resolves the request's route once and stores it in the request
+// context for metrics, tracing and validation. It must wrap those middlewares.
+func RouteMatcher(router *Router) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route := router.Lookup(r.Method, r.URL.Path); route != nil {
+				r = r.WithContext(WithRoute(r.Context(), route))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+// Package network carries the network a request is served from, so the query builders, database
+// client, metrics and traces can use its ClickHouse database.
+package network
+
+import "context"
+
+// Network is a network served by the API and the ClickHouse database holding its tables.
+type Network struct {
+	Name     string
+	Database string
+}
+
+// contextKey is the context key for the network.
+type contextKey struct{}
+
+// WithContext returns a context carrying the network.
+func WithContext(ctx context.Context, n Network) context.Context {
+	return context.WithValue(ctx, contextKey{}, n)
+}
+
+// FromContext returns the network on the context, if any.
+func FromContext(ctx context.Context) (Network, bool) {
+	n, ok := ctx.Value(contextKey{}).(Network)
+
+	return n, ok
+}
+
+// Name returns the name of the network on the context, or "" if none.
+func Name(ctx context.Context) string {
+	n, _ := FromContext(ctx)
+
+	return n.Name
+}
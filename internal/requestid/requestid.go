@@ -0,0 +1,86 @@
+// Package requestid carries the per-request correlation ID shared by HTTP middleware,
+// error responses and ClickHouse queries.
+package requestid
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// Header is the HTTP header used to accept and return request IDs.
+const Header = "X-Request-ID"
+
+// maxLength bounds accepted client-supplied IDs, which are also part of ClickHouse query IDs.
+const maxLength = 128
+
+// contextKey is the context key for the request state.
+type contextKey struct{}
+
+// state holds the request ID, the server-generated prefix of its query IDs and the number of
+// queries issued for it.
+type state struct {
+	id          string
+	queryPrefix string
+	queries     atomic.Int64
+}
+
+// New generates a new request ID.
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid reports whether a client-supplied request ID is acceptable.
+// Only URL- and log-safe characters are allowed so the ID can be embedded in a query_id.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// WithContext returns a context carrying the request ID.
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &state{id: id, queryPrefix: uuid.NewString() + "-" + id})
+}
+
+// FromContext returns the request ID on the context, or "" if none.
+func FromContext(ctx context.Context) string {
+	if s, ok := ctx.Value(contextKey{}).(*state); ok {
+		return s.id
+	}
+
+	return ""
+}
+
+// NextQueryID returns a ClickHouse query_id for the next query of the request: a server-generated
+// UUID followed by the request ID ("<uuid>-<id>"), so queries can be found in system.query_log by
+// request ID. The request ID alone can't be used, as clients choose it: two requests sending the
+// same ID would collide, and a client could have another request's query killed on disconnect.
+// Later queries in the same request get a numeric suffix ("<uuid>-<id>-2", "<uuid>-<id>-3", ...).
+// Returns "" if the context carries no request ID.
+func NextQueryID(ctx context.Context) string {
+	s, ok := ctx.Value(contextKey{}).(*state)
+	if !ok {
+		return ""
+	}
+
+	n := s.queries.Add(1)
+	if n == 1 {
+		return s.queryPrefix
+	}
+
+	return s.queryPrefix + "-" + strconv.FormatInt(n, 10)
+}
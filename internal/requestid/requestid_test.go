@@ -0,0 +1,53 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		expected bool
+	}{
+		{name: "uuid", id: "3f2c1b9e-8d4a-4e2f-9c1a-7b6d5e4f3a2b", expected: true},
+		{name: "dotted with colon", id: "lb.edge:1234_abc", expected: true},
+		{name: "empty", id: "", expected: false},
+		{name: "too long", id: strings.Repeat("a", maxLength+1), expected: false},
+		{name: "quote", id: "abc'; DROP", expected: false},
+		{name: "newline", id: "abc\ndef", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Valid(tt.id))
+		})
+	}
+}
+
+func TestNextQueryID(t *testing.T) {
+	assert.Empty(t, NextQueryID(context.Background()))
+
+	ctx := WithContext(context.Background(), "req-1")
+	assert.Equal(t, "req-1", FromContext(ctx))
+
+	first := NextQueryID(ctx)
+	require.True(t, strings.HasSuffix(first, "-req-1"), first)
+	assert.Equal(t, first+"-2", NextQueryID(ctx))
+	assert.Equal(t, first+"-3", NextQueryID(ctx))
+
+	// Requests sending the same ID get distinct query IDs
+	other := WithContext(context.Background(), "req-1")
+	assert.NotEqual(t, first, NextQueryID(other))
+}
+
+func TestNew(t *testing.T) {
+	id := New()
+	assert.True(t, Valid(id))
+	assert.NotEqual(t, id, New())
+}
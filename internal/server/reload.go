@@ -0,0 +1,225 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
+	"github.com/ethpandaops/cbt-api/internal/middleware"
+	"github.com/ethpandaops/cbt-api/internal/middleware/headers"
+	"github.com/ethpandaops/cbt-api/internal/telemetry"
+)
+
+// reloadableKeys are the config keys (or key prefixes ending in ".") applied without a restart.
+var reloadableKeys = []string{
+	"log_level",
+	"headers.policies",
+	"cors.",
+	"clickhouse.slow_queries.threshold",
+}
+
+var (
+	configHash = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "cbt_api",
+			Subsystem: "config",
+			Name:      "hash",
+			Help:      "Hash of the applied configuration (first 52 bits of its SHA-256)",
+		},
+	)
+
+	configLastReloadSuccessful = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "cbt_api",
+			Subsystem: "config",
+			Name:      "last_reload_successful",
+			Help:      "Whether the last configuration reload attempt succeeded",
+		},
+	)
+
+	configLastReloadSuccessTimestamp = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: "cbt_api",
+			Subsystem: "config",
+			Name:      "last_reload_success_timestamp_seconds",
+			Help:      "Timestamp of the last successful configuration load",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(configHash)
+	prometheus.MustRegister(configLastReloadSuccessful)
+	prometheus.MustRegister(configLastReloadSuccessTimestamp)
+}
+
+// Reloader applies the reloadable parts of a new configuration to a running server: header
+// policies, CORS, the log level and the slow query threshold. Other changes are logged and
+// take effect on the next restart.
+type Reloader struct {
+	mu      sync.Mutex
+	startup *config.Config // configuration the server was built with
+	current *config.Config // last applied configuration
+	log     logrus.FieldLogger
+	logger  *logrus.Logger
+
+	headers     *headers.Manager
+	cors        *middleware.CORS
+	slowQueries *telemetry.SlowQueryLog
+}
+
+// newReloader creates a Reloader for the components built from cfg.
+func newReloader(
+	cfg *config.Config,
+	logger logrus.FieldLogger,
+	headersManager *headers.Manager,
+	corsPolicy *middleware.CORS,
+	slowQueries *telemetry.SlowQueryLog,
+) *Reloader {
+	rl := &Reloader{
+		startup:     cfg,
+		current:     cfg,
+		log:         logger.WithField("component", "config_reload"),
+		logger:      baseLogger(logger),
+		headers:     headersManager,
+		cors:        corsPolicy,
+		slowQueries: slowQueries,
+	}
+
+	rl.recordApplied(cfg)
+
+	return rl
+}
+
+// Reload loads the configuration file at path and applies it.
+func (rl *Reloader) Reload(path string) error {
+	cfg, err := config.Load(path)
+	if err != nil {
+		configLastReloadSuccessful.Set(0)
+
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return rl.Apply(cfg)
+}
+
+// Apply validates cfg and, if every reloadable part is valid, swaps them in. Nothing is changed
+// when validation fails.
+func (rl *Reloader) Apply(cfg *config.Config) error {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	changed := config.Diff(rl.current, cfg)
+	if len(changed) == 0 {
+		rl.log.Debug("Configuration unchanged")
+		configLastReloadSuccessful.Set(1)
+
+		return nil
+	}
+
+	if err := rl.validate(cfg); err != nil {
+		configLastReloadSuccessful.Set(0)
+
+		return err
+	}
+
+	var applied, restartRequired []string
+
+	for _, key := range changed {
+		if isReloadable(key) {
+			applied = append(applied, key)
+		}
+	}
+
+	// Differences from the startup config are reported on every reload until the next restart.
+	for _, key := range config.Diff(rl.startup, cfg) {
+		if !isReloadable(key) {
+			restartRequired = append(restartRequired, key)
+		}
+	}
+
+	// Validated above, so the updates cannot fail.
+	_ = rl.headers.Update(cfg.Headers.Policies)
+	_ = rl.cors.Update(cfg.CORS)
+
+	rl.slowQueries.SetThreshold(cfg.ClickHouse.SlowQueries.Threshold)
+
+	if rl.logger != nil {
+		level, _ := logrus.ParseLevel(cfg.LogLevel)
+		rl.logger.SetLevel(level)
+	}
+
+	rl.current = cfg
+	rl.recordApplied(cfg)
+
+	entry := rl.log.WithFields(logrus.Fields{
+		"applied": strings.Join(applied, ", "),
+		"hash":    cfg.Hash()[:12],
+	})
+
+	if len(restartRequired) > 0 {
+		entry.WithField("restart_required", strings.Join(restartRequired, ", ")).
+			Warn("Configuration reloaded, some changes need a restart")
+
+		return nil
+	}
+
+	entry.Info("Configuration reloaded")
+
+	return nil
+}
+
+// validate checks every reloadable part of cfg.
+func (rl *Reloader) validate(cfg *config.Config) error {
+	if _, err := logrus.ParseLevel(cfg.LogLevel); err != nil {
+		return fmt.Errorf("invalid log_level: %w", err)
+	}
+
+	if _, err := headers.NewManager(cfg.Headers.Policies); err != nil {
+		return fmt.Errorf("invalid headers config: %w", err)
+	}
+
+	if _, err := middleware.NewCORS(cfg.CORS); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// recordApplied updates the config metrics after cfg has been applied.
+func (rl *Reloader) recordApplied(cfg *config.Config) {
+	hash, _ := strconv.ParseUint(cfg.Hash()[:13], 16, 64)
+
+	configHash.Set(float64(hash))
+	configLastReloadSuccessful.Set(1)
+	configLastReloadSuccessTimestamp.Set(float64(time.Now().Unix()))
+}
+
+// isReloadable reports whether a changed config key is applied without a restart.
+func isReloadable(key string) bool {
+	for _, reloadable := range reloadableKeys {
+		if key == reloadable || (strings.HasSuffix(reloadable, ".") && strings.HasPrefix(key, reloadable)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// baseLogger returns the logger whose level a FieldLogger writes at, or nil if it has none.
+func baseLogger(logger logrus.FieldLogger) *logrus.Logger {
+	switch l := logger.(type) {
+	case *logrus.Logger:
+		return l
+	case *logrus.Entry:
+		return l.Logger
+	default:
+		return nil
+	}
+}
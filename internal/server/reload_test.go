@@ -0,0 +1,109 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
+	"github.com/ethpandaops/cbt-api/internal/middleware"
+	"github.com/ethpandaops/cbt-api/internal/middleware/headers"
+	"github.com/ethpandaops/cbt-api/internal/telemetry"
+)
+
+func newTestReloader(t *testing.T, cfg *config.Config) (*Reloader, *logrus.Logger, *headers.Manager, *telemetry.SlowQueryLog) {
+	t.Helper()
+
+	logger := logrus.New()
+
+	headersManager, err := headers.NewManager(cfg.Headers.Policies)
+	require.NoError(t, err)
+
+	corsPolicy, err := middleware.NewCORS(cfg.CORS)
+	require.NoError(t, err)
+
+	slowQueries, err := telemetry.NewSlowQueryLog(cfg.ClickHouse.SlowQueries, logger)
+	require.NoError(t, err)
+
+	return newReloader(cfg, logger, headersManager, corsPolicy, slowQueries), logger, headersManager, slowQueries
+}
+
+func TestReloader_Apply(t *testing.T) {
+	cfg := &config.Config{LogLevel: "info"}
+	rl, logger, headersManager, slowQueries := newTestReloader(t, cfg)
+
+	updated := &config.Config{
+		LogLevel: "debug",
+		Server:   config.ServerConfig{Port: 9999}, // needs a restart
+		Headers: config.HeadersConfig{Policies: []config.HeaderPolicy{
+			{Name: "api", PathPattern: "^/api/", Headers: map[string]string{"Cache-Control": "no-cache"}},
+		}},
+		ClickHouse: config.ClickHouseConfig{SlowQueries: config.SlowQueryConfig{Threshold: 2 * time.Second}},
+	}
+
+	require.NoError(t, rl.Apply(updated))
+
+	assert.Equal(t, logrus.DebugLevel, logger.GetLevel())
+	assert.Equal(t, 2*time.Second, slowQueries.Threshold())
+
+	policyName, _ := headersManager.Match("/api/v1/foo")
+	assert.Equal(t, "api", policyName)
+}
+
+func TestReloader_ApplyInvalid(t *testing.T) {
+	cfg := &config.Config{LogLevel: "info"}
+	rl, logger, headersManager, _ := newTestReloader(t, cfg)
+
+	allow := true
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+	}{
+		{
+			name: "invalid log level",
+			cfg:  &config.Config{LogLevel: "loud"},
+		},
+		{
+			name: "invalid header pattern",
+			cfg: &config.Config{
+				LogLevel: "debug",
+				Headers:  config.HeadersConfig{Policies: []config.HeaderPolicy{{Name: "bad", PathPattern: "[invalid"}}},
+			},
+		},
+		{
+			name: "credentials with any origin",
+			cfg: &config.Config{
+				LogLevel: "debug",
+				CORS: config.CORSConfig{CORSOptions: config.CORSOptions{
+					AllowedOrigins:   []string{"*"},
+					AllowCredentials: &allow,
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, rl.Apply(tt.cfg))
+
+			// Nothing is applied when any part is invalid
+			assert.Equal(t, logrus.InfoLevel, logger.GetLevel())
+
+			policyName, _ := headersManager.Match("/api/v1/foo")
+			assert.Empty(t, policyName)
+		})
+	}
+}
+
+func TestIsReloadable(t *testing.T) {
+	assert.True(t, isReloadable("log_level"))
+	assert.True(t, isReloadable("headers.policies"))
+	assert.True(t, isReloadable("cors.max_age"))
+	assert.True(t, isReloadable("clickhouse.slow_queries.threshold"))
+	assert.False(t, isReloadable("clickhouse.slow_queries.file"))
+	assert.False(t, isReloadable("server.port"))
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -14,34 +15,70 @@ import (
 	"github.com/ethpandaops/cbt-api/internal/handlers"
 	"github.com/ethpandaops/cbt-api/internal/middleware"
 	"github.com/ethpandaops/cbt-api/internal/middleware/headers"
+	"github.com/ethpandaops/cbt-api/internal/network"
 	"github.com/ethpandaops/cbt-api/internal/telemetry"
 )
 
 //go:embed openapi.yaml
 var openapiSpec embed.FS
 
-// New creates a new HTTP server with all routes and middleware configured.
-func New(cfg *config.Config, logger logrus.FieldLogger) (*http.Server, error) {
+// New creates a new HTTP server with all routes and middleware configured, a Reloader that
+// applies configuration changes to it, and a function closing the slow query log and ClickHouse
+// connections once the server has shut down.
+func New(cfg *config.Config, logger logrus.FieldLogger) (*http.Server, *Reloader, func() error, error) {
+	networks, defaultNetwork := cfg.ResolveNetworks()
+	servedNetworks := make([]network.Network, 0, len(networks))
+	defaultDatabase := cfg.ClickHouse.Database
+
+	for _, n := range networks {
+		servedNetworks = append(servedNetworks, network.Network{Name: n.Name, Database: n.Database})
+
+		if n.Name == defaultNetwork {
+			defaultDatabase = n.Database
+		}
+	}
+
 	// Connect to ClickHouse
-	db, err := database.NewClient(&cfg.ClickHouse, logger)
+	db, guardedDB, healthComponents, err := openClickHouse(&cfg.ClickHouse, "", logger)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create ClickHouse client: %w", err)
+		return nil, nil, nil, err
+	}
+
+	guardedDB, networkComponents, err := openNetworkClients(cfg, networks, guardedDB, logger)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	healthComponents = append(healthComponents, networkComponents...)
+
+	slowQueries, err := telemetry.NewSlowQueryLog(cfg.ClickHouse.SlowQueries, logger)
+	if err != nil {
+		_ = guardedDB.Close()
+
+		return nil, nil, nil, fmt.Errorf("failed to create slow query log: %w", err)
 	}
 
 	// Wrap database client with tracing
-	var tracedDB database.DatabaseClient = telemetry.NewTracedClient(db, cfg.ClickHouse.Database, logger)
+	var tracedDB database.DatabaseClient = telemetry.NewTracedClient(guardedDB, cfg.ClickHouse.Database, logger).
+		WithSlowQueryLog(slowQueries)
+
+	closeResources := func() error {
+		return errors.Join(slowQueries.Close(), tracedDB.Close())
+	}
 
 	// Create generated server implementation.
 	impl := &Server{
 		db:     tracedDB,
 		config: cfg,
+		logger: logger,
 	}
 
 	// Setup router using native http.ServeMux with method routing
 	mux := http.NewServeMux()
 
 	// Health endpoint
-	mux.HandleFunc("GET /health", handlers.Health)
+	mux.HandleFunc("GET /health", handlers.NewHealth(healthComponents...))
+	mux.HandleFunc("GET /livez", handlers.Health)
 
 	// OpenAPI spec endpoint
 	mux.HandleFunc("GET /openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
@@ -51,6 +88,11 @@ func New(cfg *config.Config, logger logrus.FieldLogger) (*http.Server, error) {
 		_, _ = w.Write(data)
 	})
 
+	// Recent slow queries (opt-in, as entries contain SQL and bound args)
+	if cfg.ClickHouse.SlowQueries.AdminEndpoint {
+		mux.HandleFunc("GET /admin/slow-queries", slowQueries.Handler)
+	}
+
 	// Scalar API documentation at /docs
 	mux.HandleFunc("GET /docs", serveScalarDocs)
 	mux.HandleFunc("GET /docs/", serveScalarDocs)
@@ -61,25 +103,63 @@ func New(cfg *config.Config, logger logrus.FieldLogger) (*http.Server, error) {
 		ErrorHandlerFunc: apierrors.DefaultErrorHandler(logger),
 	})
 
-	// Initialize headers manager from config
-	var headersManager *headers.Manager
+	// Initialize headers manager from config, even without policies so a reload can add them
+	headersManager, err := headers.NewManager(cfg.Headers.Policies)
+	if err != nil {
+		_ = closeResources()
+
+		return nil, nil, nil, fmt.Errorf("failed to initialize headers manager: %w", err)
+	}
 
 	if len(cfg.Headers.Policies) > 0 {
-		var err error
+		logger.WithField("count", len(cfg.Headers.Policies)).Info("initialized headers manager with policies")
+	}
 
-		headersManager, err = headers.NewManager(cfg.Headers.Policies)
-		if err != nil {
-			return nil, fmt.Errorf("failed to initialize headers manager: %w", err)
-		}
+	corsPolicy, err := middleware.NewCORS(cfg.CORS)
+	if err != nil {
+		_ = closeResources()
 
-		logger.WithField("count", len(cfg.Headers.Policies)).Info("initialized headers manager with policies")
+		return nil, nil, nil, fmt.Errorf("failed to initialize CORS: %w", err)
+	}
+
+	// Build the route table once for metrics labels, span names and validation
+	swagger, err := handlers.GetSwagger()
+	if err != nil {
+		_ = closeResources()
+
+		return nil, nil, nil, fmt.Errorf("failed to load OpenAPI specification: %w", err)
 	}
 
+	router := middleware.NewRouter(swagger)
+	router.AddStatic(http.MethodGet, "/health", "health")
+	router.AddStatic(http.MethodGet, "/livez", "livez")
+	router.AddStatic(http.MethodGet, "/readyz", "readyz")
+	router.AddStatic(http.MethodGet, "/openapi.yaml", "openapi")
+	router.AddStatic(http.MethodGet, "/docs", "docs")
+
+	if cfg.ClickHouse.SlowQueries.AdminEndpoint {
+		router.AddStatic(http.MethodGet, "/admin/slow-queries", "slow_queries")
+	}
+
+	// Readiness pings ClickHouse directly and checks a sample of the exposed tables exist in the
+	// default network, which is always on clickhouse.dsn
+	mux.Handle("GET /readyz", handlers.NewReadiness(db, handlers.ReadinessConfig{
+		Database:     defaultDatabase,
+		Tables:       router.Tables(),
+		SampleTables: cfg.Server.ReadinessSampleTables,
+		CacheTTL:     cfg.Server.ReadinessCacheTTL,
+	}))
+
 	// Apply middleware stack (wrap the mux)
 	handler := middleware.Logging(logger)(mux)
+
+	if cfg.API.QueryStatsHeaders {
+		handler = middleware.QueryStatsHeaders()(handler)
+	}
+
 	handler = middleware.NotFoundHandler()(handler)
 	handler = middleware.QueryParameterValidation(logger)(handler)
-	handler = middleware.CORS()(handler)
+	handler = corsPolicy.Middleware()(handler)
 	handler = middleware.Recovery(logger)(handler)
 	handler = middleware.Metrics()(handler)
 
@@ -90,10 +170,21 @@ func New(cfg *config.Config, logger logrus.FieldLogger) (*http.Server, error) {
 
 	handler = middleware.Gzip()(handler)
 
-	// Apply headers middleware if configured
-	if headersManager != nil {
-		handler = headersManager.Middleware(logger.WithField("component", "headers"))(handler)
-	}
+	handler = headersManager.Middleware(logger.WithField("component", "headers"))(handler)
+
+	// Write errors as problem details for clients that ask for them
+	handler = middleware.ErrorFormat()(handler)
+
+	// Assign a request ID so logs, error responses and ClickHouse query_id can be correlated
+	handler = middleware.RequestID()(handler)
+
+	// Resolve the route first so every middleware above can label by route template
+	handler = middleware.RouteMatcher(router)(handler)
+
+	// Strip the network segment before routing: /api/v1/{network}/fct_block
+	handler = middleware.Networks(cfg.API.BasePath, servedNetworks, defaultNetwork)(handler)
+
+	reloader := newReloader(cfg, logger, headersManager, corsPolicy, slowQueries)
 
 	return &http.Server{
 		Addr:              fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port),
@@ -102,7 +193,7 @@ func New(cfg *config.Config, logger logrus.FieldLogger) (*http.Server, error) {
 		ReadTimeout:       cfg.Server.ReadTimeout,
 		WriteTimeout:      cfg.Server.WriteTimeout,
 		IdleTimeout:       cfg.Server.IdleTimeout,
-	}, nil
+	}, reloader, closeResources, nil
 }
 
 // serveScalarDocs serves the Scalar API documentation UI.
@@ -348,3 +439,108 @@ func NewMetrics(cfg *config.Config) *http.Server {
 		IdleTimeout:       cfg.Server.IdleTimeout,
 	}
 }
+
+// openClickHouse connects to ClickHouse and wraps the client with query cancellation and, if
+// enabled, the circuit breaker. It returns the raw client for readiness pings, the wrapped
+// client and its health components, named after network for clusters other than the main one.
+func openClickHouse(
+	cfg *config.ClickHouseConfig,
+	network string,
+	logger logrus.FieldLogger,
+) (database.ReplicaClient, database.DatabaseClient, []handlers.HealthComponent, error) {
+	if network != "" {
+		logger = logger.WithField("network", network)
+	}
+
+	db, err := database.Open(cfg, logger)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create ClickHouse client: %w", err)
+	}
+
+	// Separate connection used to kill queries whose HTTP client has gone away
+	killDB, err := database.NewKillClient(cfg, logger)
+	if err != nil {
+		_ = db.Close()
+
+		return nil, nil, nil, fmt.Errorf("failed to create ClickHouse kill client: %w", err)
+	}
+
+	var (
+		guardedDB        database.DatabaseClient = database.NewCancellingClient(db, killDB, logger)
+		healthComponents []handlers.HealthComponent
+	)
+
+	// Fast-fail queries while ClickHouse is unhealthy
+	if cfg.CircuitBreaker.Enabled {
+		breaker := database.NewCircuitBreaker(guardedDB, cfg.CircuitBreaker, network, logger)
+		guardedDB = breaker
+
+		if network != "" {
+			healthComponents = append(healthComponents, networkComponent{HealthComponent: breaker, network: network})
+		} else {
+			healthComponents = append(healthComponents, breaker)
+		}
+	}
+
+	return db, guardedDB, healthComponents, nil
+}
+
+// openNetworkClients connects to the ClickHouse clusters of networks with their own DSN, sharing a
+// client between networks with the same DSN. It returns defaultClient wrapped to route their
+// queries, or unchanged if every network uses clickhouse.dsn. On error defaultClient is closed.
+func openNetworkClients(
+	cfg *config.Config,
+	networks []config.Network,
+	defaultClient database.DatabaseClient,
+	logger logrus.FieldLogger,
+) (database.DatabaseClient, []handlers.HealthComponent, error) {
+	var (
+		clients          = make(map[string]database.DatabaseClient) // by network
+		clusters         = make(map[string]database.DatabaseClient) // by DSN
+		healthComponents []handlers.HealthComponent
+	)
+
+	for _, n := range networks {
+		if n.DSN == cfg.ClickHouse.DSN {
+			continue
+		}
+
+		client, ok := clusters[n.DSN]
+		if !ok {
+			clusterCfg := cfg.ClickHouse
+			clusterCfg.DSN = n.DSN
+			clusterCfg.Database = n.Database
+			clusterCfg.Hosts = nil // Hosts replace the host of clickhouse.dsn only
+
+			_, guarded, components, err := openClickHouse(&clusterCfg, n.Name, logger)
+			if err != nil {
+				_ = database.NewNetworkClient(defaultClient, clients).Close()
+
+				return nil, nil, fmt.Errorf("network %s: %w", n.Name, err)
+			}
+
+			client = guarded
+			clusters[n.DSN] = client
+			healthComponents = append(healthComponents, components...)
+		}
+
+		clients[n.Name] = client
+	}
+
+	if len(clients) == 0 {
+		return defaultClient, nil, nil
+	}
+
+	return database.NewNetworkClient(defaultClient, clients), healthComponents, nil
+}
+
+// networkComponent names a health component after the network whose cluster it belongs to.
+type networkComponent struct {
+	handlers.HealthComponent
+	network string
+}
+
+// Name returns the component name suffixed with the network.
+func (c networkComponent) Name() string {
+	return c.HealthComponent.Name() + "_" + c.network
+}
@@ -34,6 +34,17 @@ const (
 	AttrDBRowsReturned = attribute.Key("db.rows_returned")
 	AttrDBUseFinal     = attribute.Key("db.use_final")
 
+	// ClickHouse query statistics attributes.
+	AttrDBQueryID     = attribute.Key("db.clickhouse.query_id")
+	AttrDBReadRows    = attribute.Key("db.clickhouse.read_rows")
+	AttrDBReadBytes   = attribute.Key("db.clickhouse.read_bytes")
+	AttrDBMemoryUsage = attribute.Key("db.clickhouse.memory_usage")
+	AttrDBElapsedMs   = attribute.Key("db.clickhouse.elapsed_ms")
+
+	// Route attributes.
+	AttrTableName     = attribute.Key("cbt.table")
+	AttrOperationName = attribute.Key("cbt.operation")
+
 	// Service attributes.
 	AttrNetworkName = attribute.Key("network.name")
 )
@@ -16,6 +16,7 @@ import (
 	oteltrace "go.opentelemetry.io/otel/trace"
 
 	"github.com/ethpandaops/cbt-api/internal/database"
+	"github.com/ethpandaops/cbt-api/internal/network"
 )
 
 // tableNameRegex matches table names in FROM and INTO clauses.
@@ -41,7 +42,7 @@ var (
 			Help:      "ClickHouse query duration in seconds",
 			Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
 		},
-		[]string{"operation", "sql_operation", "table"},
+		[]string{"operation", "sql_operation", "table", "network"},
 	)
 
 	clickhouseQueriesTotal = prometheus.NewCounterVec(
@@ -51,7 +52,7 @@ var (
 			Name:      "queries_total",
 			Help:      "Total number of ClickHouse queries",
 		},
-		[]string{"operation", "sql_operation", "table"},
+		[]string{"operation", "sql_operation", "table", "network"},
 	)
 
 	clickhouseQueryErrorsTotal = prometheus.NewCounterVec(
@@ -61,7 +62,7 @@ var (
 			Name:      "query_errors_total",
 			Help:      "Total number of ClickHouse query errors",
 		},
-		[]string{"operation", "sql_operation", "table"},
+		[]string{"operation", "sql_operation", "table", "network"},
 	)
 
 	clickhouseRowsReturned = prometheus.NewHistogramVec(
@@ -72,7 +73,40 @@ var (
 			Help:      "Number of rows returned by ClickHouse queries",
 			Buckets:   []float64{1, 10, 50, 100, 500, 1000, 5000, 10000, 50000, 100000},
 		},
-		[]string{"operation", "sql_operation", "table"},
+		[]string{"operation", "sql_operation", "table", "network"},
+	)
+
+	clickhouseReadRows = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "cbt_api",
+			Subsystem: "clickhouse",
+			Name:      "read_rows",
+			Help:      "Number of rows read by ClickHouse to answer a query",
+			Buckets:   prometheus.ExponentialBuckets(100, 10, 9),
+		},
+		[]string{"table", "network"},
+	)
+
+	clickhouseReadBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "cbt_api",
+			Subsystem: "clickhouse",
+			Name:      "read_bytes",
+			Help:      "Number of bytes read by ClickHouse to answer a query",
+			Buckets:   prometheus.ExponentialBuckets(1024, 10, 9),
+		},
+		[]string{"table", "network"},
+	)
+
+	clickhouseMemoryUsage = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "cbt_api",
+			Subsystem: "clickhouse",
+			Name:      "memory_usage_bytes",
+			Help:      "Peak memory used by ClickHouse to answer a query",
+			Buckets:   prometheus.ExponentialBuckets(1<<20, 4, 9),
+		},
+		[]string{"table", "network"},
 	)
 )
 
@@ -81,21 +115,25 @@ func init() {
 	prometheus.MustRegister(clickhouseQueriesTotal)
 	prometheus.MustRegister(clickhouseQueryErrorsTotal)
 	prometheus.MustRegister(clickhouseRowsReturned)
+	prometheus.MustRegister(clickhouseReadRows)
+	prometheus.MustRegister(clickhouseReadBytes)
+	prometheus.MustRegister(clickhouseMemoryUsage)
 }
 
-// TracedClient wraps database.Client with OpenTelemetry instrumentation.
+// TracedClient wraps a database.DatabaseClient with OpenTelemetry instrumentation.
 type TracedClient struct {
-	client *database.Client
-	tracer oteltrace.Tracer
-	dbName string
-	log    logrus.FieldLogger
+	client  database.DatabaseClient
+	tracer  oteltrace.Tracer
+	dbName  string
+	log     logrus.FieldLogger
+	slowLog *SlowQueryLog
 }
 
 // Ensure TracedClient implements database.DatabaseClient interface.
 var _ database.DatabaseClient = (*TracedClient)(nil)
 
 // NewTracedClient wraps a database client with tracing.
-func NewTracedClient(client *database.Client, dbName string, logger logrus.FieldLogger) *TracedClient {
+func NewTracedClient(client database.DatabaseClient, dbName string, logger logrus.FieldLogger) *TracedClient {
 	return &TracedClient{
 		client: client,
 		tracer: otel.Tracer("github.com/ethpandaops/cbt-api/database"),
@@ -104,6 +142,13 @@ func NewTracedClient(client *database.Client, dbName string, logger logrus.Field
 	}
 }
 
+// WithSlowQueryLog records queries exceeding the slow query threshold to l.
+func (c *TracedClient) WithSlowQueryLog(l *SlowQueryLog) *TracedClient {
+	c.slowLog = l
+
+	return c
+}
+
 // Query executes a query with tracing.
 func (c *TracedClient) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
 	start := time.Now()
@@ -111,31 +156,49 @@ func (c *TracedClient) Query(ctx context.Context, query string, args ...any) (dr
 	table := extractTableName(query)
 
 	ctx, span := c.startSpan(ctx, "Query", query, args...)
-	defer span.End()
+
+	collector := database.NewStatsCollector()
+	ctx = database.WithStatsCollector(ctx, collector)
 
 	// Record query count
-	clickhouseQueriesTotal.WithLabelValues("Query", sqlOp, table).Inc()
+	clickhouseQueriesTotal.WithLabelValues("Query", sqlOp, table, network.Name(ctx)).Inc()
 
 	rows, err := c.client.Query(ctx, query, args...)
 	duration := time.Since(start).Seconds()
 
 	// Record query duration
-	clickhouseQueryDuration.WithLabelValues("Query", sqlOp, table).Observe(duration)
+	clickhouseQueryDuration.WithLabelValues("Query", sqlOp, table, network.Name(ctx)).Observe(duration)
 
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		clickhouseQueryErrorsTotal.WithLabelValues("Query", sqlOp, table).Inc()
+		clickhouseQueryErrorsTotal.WithLabelValues("Query", sqlOp, table, network.Name(ctx)).Inc()
+		recordQueryStats(span, table, network.Name(ctx), collector.Stats())
+		c.slowLog.observeStats(ctx, SlowQuery{
+			Table:     table,
+			Operation: "Query",
+			SQL:       query,
+			Error:     err.Error(),
+		}, collector.Stats(), args, time.Since(start))
+		span.End()
 
 		return nil, err
 	}
 
+	// The span stays open while rows are streamed; progress and profile
+	// packets arrive during iteration and are recorded on Close.
 	return &tracedRows{
 		Rows:      rows,
 		span:      span,
+		collector: collector,
 		operation: "Query",
 		sqlOp:     sqlOp,
 		table:     table,
+		ctx:       ctx,
+		slowLog:   c.slowLog,
+		start:     start,
+		query:     query,
+		args:      args,
 	}, nil
 }
 
@@ -149,13 +212,19 @@ func (c *TracedClient) QueryRow(ctx context.Context, query string, args ...any)
 	defer span.End()
 
 	// Record query count
-	clickhouseQueriesTotal.WithLabelValues("QueryRow", sqlOp, table).Inc()
+	clickhouseQueriesTotal.WithLabelValues("QueryRow", sqlOp, table, network.Name(ctx)).Inc()
 
 	row := c.client.QueryRow(ctx, query, args...)
 	duration := time.Since(start).Seconds()
 
 	// Record query duration
-	clickhouseQueryDuration.WithLabelValues("QueryRow", sqlOp, table).Observe(duration)
+	clickhouseQueryDuration.WithLabelValues("QueryRow", sqlOp, table, network.Name(ctx)).Observe(duration)
+
+	c.slowLog.observe(ctx, SlowQuery{
+		Table:     table,
+		Operation: "QueryRow",
+		SQL:       query,
+	}, args, time.Since(start))
 
 	// Note: driver.Row doesn't expose errors until Scan() is called
 	// We record the operation but can't capture row-level errors here.
@@ -173,19 +242,31 @@ func (c *TracedClient) Select(ctx context.Context, dest any, query string, args
 	ctx, span := c.startSpan(ctx, "Select", query, args...)
 	defer span.End()
 
+	collector := database.NewStatsCollector()
+	ctx = database.WithStatsCollector(ctx, collector)
+
+	defer func() { recordQueryStats(span, table, network.Name(ctx), collector.Stats()) }()
+
 	// Record query count
-	clickhouseQueriesTotal.WithLabelValues("Select", sqlOp, table).Inc()
+	clickhouseQueriesTotal.WithLabelValues("Select", sqlOp, table, network.Name(ctx)).Inc()
 
 	err := c.client.Select(ctx, dest, query, args...)
 	duration := time.Since(start).Seconds()
 
 	// Record query duration
-	clickhouseQueryDuration.WithLabelValues("Select", sqlOp, table).Observe(duration)
+	clickhouseQueryDuration.WithLabelValues("Select", sqlOp, table, network.Name(ctx)).Observe(duration)
+
+	c.slowLog.observeStats(ctx, SlowQuery{
+		Table:     table,
+		Operation: "Select",
+		SQL:       query,
+		Error:     errorString(err),
+	}, collector.Stats(), args, time.Since(start))
 
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		clickhouseQueryErrorsTotal.WithLabelValues("Select", sqlOp, table).Inc()
+		clickhouseQueryErrorsTotal.WithLabelValues("Select", sqlOp, table, network.Name(ctx)).Inc()
 
 		return err
 	}
@@ -204,19 +285,31 @@ func (c *TracedClient) Exec(ctx context.Context, query string, args ...any) erro
 	ctx, span := c.startSpan(ctx, "Exec", query, args...)
 	defer span.End()
 
+	collector := database.NewStatsCollector()
+	ctx = database.WithStatsCollector(ctx, collector)
+
+	defer func() { recordQueryStats(span, table, network.Name(ctx), collector.Stats()) }()
+
 	// Record query count
-	clickhouseQueriesTotal.WithLabelValues("Exec", sqlOp, table).Inc()
+	clickhouseQueriesTotal.WithLabelValues("Exec", sqlOp, table, network.Name(ctx)).Inc()
 
 	err := c.client.Exec(ctx, query, args...)
 	duration := time.Since(start).Seconds()
 
 	// Record query duration
-	clickhouseQueryDuration.WithLabelValues("Exec", sqlOp, table).Observe(duration)
+	clickhouseQueryDuration.WithLabelValues("Exec", sqlOp, table, network.Name(ctx)).Observe(duration)
+
+	c.slowLog.observeStats(ctx, SlowQuery{
+		Table:     table,
+		Operation: "Exec",
+		SQL:       query,
+		Error:     errorString(err),
+	}, collector.Stats(), args, time.Since(start))
 
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		clickhouseQueryErrorsTotal.WithLabelValues("Exec", sqlOp, table).Inc()
+		clickhouseQueryErrorsTotal.WithLabelValues("Exec", sqlOp, table, network.Name(ctx)).Inc()
 
 		return err
 	}
@@ -241,13 +334,25 @@ func (c *TracedClient) startSpan(ctx context.Context, operation, query string, a
 		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
 	)
 
+	// Queries for a network run against its database
+	dbName := c.dbName
+
+	n, hasNetwork := network.FromContext(ctx)
+	if hasNetwork {
+		dbName = n.Database
+	}
+
 	// Add standard database attributes
 	attrs := AddDatabaseAttributes([]attribute.KeyValue{},
-		c.dbName,
+		dbName,
 		sqlOp,
 		truncateString(query, 2048),
 	)
 
+	if hasNetwork {
+		attrs = append(attrs, AttrNetworkName.String(n.Name))
+	}
+
 	// Add query parameters as attributes (if not too many)
 	if len(args) > 0 && len(args) <= 10 {
 		paramsStr := fmt.Sprintf("%v", args)
@@ -260,6 +365,33 @@ func (c *TracedClient) startSpan(ctx context.Context, operation, query string, a
 	return ctx, span
 }
 
+// recordQueryStats attaches ClickHouse progress and profile information to the span and
+// records read rows, read bytes and memory usage histograms for the table and network.
+func recordQueryStats(span oteltrace.Span, table, networkName string, stats database.QueryStats) {
+	if stats.QueryID != "" {
+		span.SetAttributes(AttrDBQueryID.String(stats.QueryID))
+	}
+
+	// No progress means the query never ran, or ran over a protocol without progress packets
+	if stats.ReadRows == 0 && stats.ReadBytes == 0 && stats.PeakMemoryUsage == 0 {
+		return
+	}
+
+	span.SetAttributes(
+		AttrDBReadRows.Int64(int64(stats.ReadRows)),   //nolint:gosec // row counts fit in int64
+		AttrDBReadBytes.Int64(int64(stats.ReadBytes)), //nolint:gosec // byte counts fit in int64
+		AttrDBMemoryUsage.Int64(stats.PeakMemoryUsage),
+		AttrDBElapsedMs.Int64(stats.Elapsed.Milliseconds()),
+	)
+
+	clickhouseReadRows.WithLabelValues(table, networkName).Observe(float64(stats.ReadRows))
+	clickhouseReadBytes.WithLabelValues(table, networkName).Observe(float64(stats.ReadBytes))
+
+	if stats.PeakMemoryUsage > 0 {
+		clickhouseMemoryUsage.WithLabelValues(table, networkName).Observe(float64(stats.PeakMemoryUsage))
+	}
+}
+
 // extractSQLOperation extracts the SQL operation from a query.
 func extractSQLOperation(query string) string {
 	query = strings.TrimSpace(query)
@@ -273,6 +405,15 @@ func extractSQLOperation(query string) string {
 	return "UNKNOWN"
 }
 
+// errorString returns err's message, or "" for a nil error.
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
 // truncateString truncates a string to maxLen characters.
 func truncateString(s string, maxLen int) string {
 	if len(s) > maxLen {
@@ -282,14 +423,23 @@ func truncateString(s string, maxLen int) string {
 	return s
 }
 
-// tracedRows wraps driver.Rows to record row count on close.
+// tracedRows wraps driver.Rows to record row count and query statistics, and end the span, on close.
 type tracedRows struct {
 	driver.Rows
 	span      oteltrace.Span
+	collector *database.StatsCollector
 	rowCount  int64
+	closed    bool
 	operation string
 	sqlOp     string
 	table     string
+
+	// Slow query logging happens on Close, once the full streaming time is known
+	ctx     context.Context //nolint:containedctx // request context needed when the rows are closed
+	slowLog *SlowQueryLog
+	start   time.Time
+	query   string
+	args    []any
 }
 
 // Next wraps the original Next and counts rows.
@@ -304,7 +454,7 @@ func (r *tracedRows) Next() bool {
 
 		// Record rows returned metric
 		if r.rowCount > 0 {
-			clickhouseRowsReturned.WithLabelValues(r.operation, r.sqlOp, r.table).Observe(float64(r.rowCount))
+			clickhouseRowsReturned.WithLabelValues(r.operation, r.sqlOp, r.table, network.Name(r.ctx)).Observe(float64(r.rowCount))
 		}
 	}
 
@@ -313,11 +463,30 @@ func (r *tracedRows) Next() bool {
 
 // Close wraps the original Close.
 func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+
+	if r.closed {
+		return err
+	}
+
+	r.closed = true
+
 	// Record final row count if not already done
 	if r.rowCount > 0 {
 		r.span.SetAttributes(AttrDBRowsReturned.Int64(r.rowCount))
-		clickhouseRowsReturned.WithLabelValues(r.operation, r.sqlOp, r.table).Observe(float64(r.rowCount))
+		clickhouseRowsReturned.WithLabelValues(r.operation, r.sqlOp, r.table, network.Name(r.ctx)).Observe(float64(r.rowCount))
 	}
 
-	return r.Rows.Close()
+	stats := r.collector.Stats()
+	recordQueryStats(r.span, r.table, network.Name(r.ctx), stats)
+	r.slowLog.observeStats(r.ctx, SlowQuery{
+		Table:        r.table,
+		Operation:    r.operation,
+		SQL:          r.query,
+		RowsReturned: r.rowCount,
+		Error:        errorString(err),
+	}, stats, r.args, time.Since(r.start))
+	r.span.End()
+
+	return err
 }
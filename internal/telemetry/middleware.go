@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/ethpandaops/cbt-api/internal/middleware"
+	"github.com/ethpandaops/cbt-api/internal/network"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
@@ -15,15 +17,9 @@ import (
 // Uses otelhttp for standard HTTP instrumentation.
 func HTTPMiddleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		// Use otelhttp.NewHandler with custom options
-		handler := otelhttp.NewHandler(next, "cbt-api",
-			otelhttp.WithTracerProvider(otel.GetTracerProvider()),
-			otelhttp.WithSpanNameFormatter(spanNameFormatter),
-			otelhttp.WithSpanOptions(oteltrace.WithSpanKind(oteltrace.SpanKindServer)),
-		)
-
-		// Wrap with custom attribute extraction and status setting
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Wrap with custom attribute extraction and status setting.
+		// This runs inside otelhttp so the server span is the one on the request context.
+		inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract and add custom attributes
 			ctx := r.Context()
 			span := oteltrace.SpanFromContext(ctx)
@@ -39,13 +35,20 @@ func HTTPMiddleware() func(http.Handler) http.Handler {
 			}
 
 			// Serve request
-			handler.ServeHTTP(wrapped, r)
+			next.ServeHTTP(wrapped, r)
 
 			// Set span status based on HTTP status code
 			if span.SpanContext().IsValid() {
 				setSpanStatus(span, wrapped.statusCode)
 			}
 		})
+
+		// Use otelhttp.NewHandler with custom options
+		return otelhttp.NewHandler(inner, "cbt-api",
+			otelhttp.WithTracerProvider(otel.GetTracerProvider()),
+			otelhttp.WithSpanNameFormatter(spanNameFormatter),
+			otelhttp.WithSpanOptions(oteltrace.WithSpanKind(oteltrace.SpanKindServer)),
+		)
 	}
 }
 
@@ -61,6 +64,11 @@ func (r *statusRecorder) WriteHeader(statusCode int) {
 	r.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Unwrap returns the wrapped ResponseWriter, for http.ResponseController and error format negotiation.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // setSpanStatus sets the span status based on HTTP status code.
 func setSpanStatus(span oteltrace.Span, statusCode int) {
 	switch {
@@ -76,13 +84,32 @@ func setSpanStatus(span oteltrace.Span, statusCode int) {
 	}
 }
 
-// spanNameFormatter formats span names as "HTTP {METHOD} {ROUTE}".
-func spanNameFormatter(operation string, r *http.Request) string {
-	return "HTTP " + r.Method + " " + r.URL.Path
+// spanNameFormatter formats span names as "HTTP {METHOD} {ROUTE}", using the matched route template
+// so span names stay bounded for Get-by-key URLs.
+func spanNameFormatter(_ string, r *http.Request) string {
+	route, _, _ := middleware.RouteLabels(r.Context())
+
+	return "HTTP " + r.Method + " " + route
 }
 
 // addCustomHTTPAttributes adds cbt-api specific attributes.
 func addCustomHTTPAttributes(span oteltrace.Span, r *http.Request) {
+	// Add matched route, table and operation
+	route, table, operation := middleware.RouteLabels(r.Context())
+	span.SetAttributes(HTTPRouteKey.String(route))
+
+	if table != "" {
+		span.SetAttributes(AttrTableName.String(table))
+	}
+
+	if operation != "" {
+		span.SetAttributes(AttrOperationName.String(operation))
+	}
+
+	if n, ok := network.FromContext(r.Context()); ok {
+		span.SetAttributes(AttrNetworkName.String(n.Name))
+	}
+
 	// Add query parameters as attribute (truncated if too long)
 	queryParams := r.URL.RawQuery
 	if len(queryParams) > 0 {
@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethpandaops/cbt-api/internal/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpanNameFormatter(t *testing.T) {
+	tests := []struct {
+		name     string
+		route    *middleware.Route
+		expected string
+	}{
+		{
+			name:     "matched route uses template",
+			route:    &middleware.Route{Template: "/api/v1/fct_block/{slot}"},
+			expected: "HTTP GET /api/v1/fct_block/{slot}",
+		},
+		{
+			name:     "unmatched route is collapsed",
+			expected: "HTTP GET unmatched",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/fct_block/12345", nil)
+			if tt.route != nil {
+				req = req.WithContext(middleware.WithRoute(req.Context(), tt.route))
+			}
+
+			assert.Equal(t, tt.expected, spanNameFormatter("", req))
+		})
+	}
+}
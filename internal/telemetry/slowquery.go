@@ -0,0 +1,368 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
+	"github.com/ethpandaops/cbt-api/internal/database"
+	apierrors "github.com/ethpandaops/cbt-api/internal/errors"
+	"github.com/ethpandaops/cbt-api/internal/middleware"
+	"github.com/ethpandaops/cbt-api/internal/requestid"
+)
+
+// redactedArg replaces bound args hidden by the slow query redaction settings.
+const redactedArg = "[REDACTED]"
+
+// defaultSlowQueryBufferSize is used when no buffer size is configured.
+const defaultSlowQueryBufferSize = 100
+
+// SlowQuery is a structured record of a query that exceeded the slow query threshold.
+type SlowQuery struct {
+	Time            time.Time `json:"time"`
+	QueryID         string    `json:"query_id,omitempty"`
+	RequestID       string    `json:"request_id,omitempty"`
+	Endpoint        string    `json:"endpoint,omitempty"`
+	Table           string    `json:"table"`
+	Operation       string    `json:"operation"`
+	SQL             string    `json:"sql"`
+	Args            []string  `json:"args,omitempty"`
+	DurationMs      float64   `json:"duration_ms"`
+	ServerElapsedMs int64     `json:"server_elapsed_ms,omitempty"`
+	ReadRows        uint64    `json:"read_rows,omitempty"`
+	ReadBytes       uint64    `json:"read_bytes,omitempty"`
+	PeakMemoryUsage int64     `json:"peak_memory_usage,omitempty"`
+	RowsReturned    int64     `json:"rows_returned,omitempty"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// SlowQueryLog records queries slower than a threshold to the logger, an optional rotating
+// JSONL file and an in-memory ring buffer of the most recent entries.
+type SlowQueryLog struct {
+	threshold atomic.Int64
+	redactAll bool
+	redact    []*regexp.Regexp
+	log       logrus.FieldLogger
+	file      *rotatingFile
+
+	mu      sync.Mutex
+	entries []SlowQuery
+	next    int
+	full    bool
+}
+
+// NewSlowQueryLog creates a SlowQueryLog from configuration.
+func NewSlowQueryLog(cfg config.SlowQueryConfig, logger logrus.FieldLogger) (*SlowQueryLog, error) {
+	size := cfg.BufferSize
+	if size <= 0 {
+		size = defaultSlowQueryBufferSize
+	}
+
+	l := &SlowQueryLog{
+		redactAll: cfg.RedactArgs,
+		log:       logger.WithField("component", "slow_queries"),
+		entries:   make([]SlowQuery, size),
+	}
+
+	l.SetThreshold(cfg.Threshold)
+
+	for _, pattern := range cfg.RedactPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slow query redact pattern %q: %w", pattern, err)
+		}
+
+		l.redact = append(l.redact, re)
+	}
+
+	if cfg.File != "" {
+		file, err := newRotatingFile(cfg.File, int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open slow query log file: %w", err)
+		}
+
+		l.file = file
+	}
+
+	return l, nil
+}
+
+// SetThreshold changes the slow query threshold. A threshold of 0 disables logging.
+func (l *SlowQueryLog) SetThreshold(threshold time.Duration) {
+	l.threshold.Store(int64(threshold))
+}
+
+// Threshold returns the current slow query threshold.
+func (l *SlowQueryLog) Threshold() time.Duration {
+	return time.Duration(l.threshold.Load())
+}
+
+// observe records the query if it took at least the threshold. Request details are taken from ctx.
+func (l *SlowQueryLog) observe(ctx context.Context, entry SlowQuery, args []any, duration time.Duration) {
+	if l == nil {
+		return
+	}
+
+	threshold := l.Threshold()
+	if threshold <= 0 || duration < threshold {
+		return
+	}
+
+	route, _, _ := middleware.RouteLabels(ctx)
+	if route != middleware.UnmatchedRoute {
+		entry.Endpoint = route
+	}
+
+	entry.Time = time.Now().UTC()
+	entry.RequestID = requestid.FromContext(ctx)
+	entry.DurationMs = float64(duration.Microseconds()) / 1000
+	entry.Args = l.redactArgs(args)
+
+	l.log.WithFields(logrus.Fields{
+		"query_id":          entry.QueryID,
+		"request_id":        entry.RequestID,
+		"endpoint":          entry.Endpoint,
+		"table":             entry.Table,
+		"operation":         entry.Operation,
+		"sql":               entry.SQL,
+		"args":              entry.Args,
+		"duration_ms":       entry.DurationMs,
+		"server_elapsed_ms": entry.ServerElapsedMs,
+		"read_rows":         entry.ReadRows,
+		"read_bytes":        entry.ReadBytes,
+		"peak_memory_usage": entry.PeakMemoryUsage,
+		"rows_returned":     entry.RowsReturned,
+	}).Warn("Slow query")
+
+	if l.file != nil {
+		if err := l.file.writeJSON(entry); err != nil {
+			l.log.WithError(err).Error("Failed to write slow query log file")
+		}
+	}
+
+	l.mu.Lock()
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+	l.full = l.full || l.next == 0
+	l.mu.Unlock()
+}
+
+// observeStats records the query with server-side statistics from the collector.
+func (l *SlowQueryLog) observeStats(
+	ctx context.Context,
+	entry SlowQuery,
+	stats database.QueryStats,
+	args []any,
+	duration time.Duration,
+) {
+	entry.QueryID = stats.QueryID
+	entry.ServerElapsedMs = stats.Elapsed.Milliseconds()
+	entry.ReadRows = stats.ReadRows
+	entry.ReadBytes = stats.ReadBytes
+	entry.PeakMemoryUsage = stats.PeakMemoryUsage
+
+	l.observe(ctx, entry, args, duration)
+}
+
+// Recent returns up to n of the most recent entries, newest first. n <= 0 returns all of them.
+func (l *SlowQueryLog) Recent(n int) []SlowQuery {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	count := l.next
+	if l.full {
+		count = len(l.entries)
+	}
+
+	if n <= 0 || n > count {
+		n = count
+	}
+
+	recent := make([]SlowQuery, 0, n)
+	for i := 1; i <= n; i++ {
+		recent = append(recent, l.entries[(l.next-i+len(l.entries))%len(l.entries)])
+	}
+
+	return recent
+}
+
+// Handler serves the most recent slow queries as JSON. An optional ?limit= caps the number of entries.
+func (l *SlowQueryLog) Handler(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			apierrors.BadRequest("limit must be a non-negative integer").WriteJSON(w)
+
+			return
+		}
+
+		limit = parsed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		ThresholdMs int64       `json:"threshold_ms"`
+		Entries     []SlowQuery `json:"entries"`
+	}{
+		ThresholdMs: l.Threshold().Milliseconds(),
+		Entries:     l.Recent(limit),
+	})
+}
+
+// Close closes the slow query log file, if any.
+func (l *SlowQueryLog) Close() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+
+	return l.file.close()
+}
+
+// redactArgs formats bound args for logging, hiding those covered by the redaction settings.
+func (l *SlowQueryLog) redactArgs(args []any) []string {
+	if len(args) == 0 {
+		return nil
+	}
+
+	formatted := make([]string, len(args))
+
+	for i, arg := range args {
+		value := fmt.Sprintf("%v", arg)
+
+		if l.redactAll || l.matchesRedactPattern(value) {
+			value = redactedArg
+		}
+
+		formatted[i] = value
+	}
+
+	return formatted
+}
+
+// matchesRedactPattern reports whether value matches any configured redact pattern.
+func (l *SlowQueryLog) matchesRedactPattern(value string) bool {
+	for _, re := range l.redact {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rotatingFile is an append-only JSONL file rotated to path.1 ... path.N once it exceeds maxSize.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// newRotatingFile opens (or creates) path for appending.
+func newRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// open opens the current file and records its size.
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644) //nolint:gosec // path comes from operator config
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+
+	return nil
+}
+
+// writeJSON appends v as a single JSON line, rotating first if the line would exceed maxSize.
+func (f *rotatingFile) writeJSON(v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(line)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := f.file.Write(line)
+	f.size += int64(n)
+
+	return err
+}
+
+// rotate shifts path.N-1 -> path.N ... path -> path.1, dropping the oldest backup, and reopens path.
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	if f.maxBackups <= 0 {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return f.open()
+	}
+
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		src := fmt.Sprintf("%s.%d", f.path, i)
+		if err := os.Rename(src, fmt.Sprintf("%s.%d", f.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := os.Rename(f.path, f.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return f.open()
+}
+
+// close closes the underlying file.
+func (f *rotatingFile) close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
@@ -0,0 +1,137 @@
+package telemetry
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ethpandaops/cbt-api/internal/config"
+	"github.com/ethpandaops/cbt-api/internal/requestid"
+)
+
+func newTestSlowQueryLog(t *testing.T, cfg config.SlowQueryConfig) *SlowQueryLog {
+	t.Helper()
+
+	l, err := NewSlowQueryLog(cfg, logrus.New())
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = l.Close() })
+
+	return l
+}
+
+func TestSlowQueryLog_Threshold(t *testing.T) {
+	l := newTestSlowQueryLog(t, config.SlowQueryConfig{Threshold: 100 * time.Millisecond})
+
+	l.observe(context.Background(), SlowQuery{SQL: "SELECT 1"}, nil, 50*time.Millisecond)
+	assert.Empty(t, l.Recent(0))
+
+	l.observe(context.Background(), SlowQuery{SQL: "SELECT 2"}, nil, 150*time.Millisecond)
+	require.Len(t, l.Recent(0), 1)
+	assert.InDelta(t, 150.0, l.Recent(0)[0].DurationMs, 0.001)
+
+	// A zero threshold disables logging.
+	l.SetThreshold(0)
+	l.observe(context.Background(), SlowQuery{SQL: "SELECT 3"}, nil, time.Hour)
+	assert.Len(t, l.Recent(0), 1)
+
+	// A nil log is a no-op.
+	var nilLog *SlowQueryLog
+	nilLog.observe(context.Background(), SlowQuery{}, nil, time.Hour)
+}
+
+func TestSlowQueryLog_Redaction(t *testing.T) {
+	args := []any{uint32(100), "0xdeadbeef", "lighthouse"}
+
+	l := newTestSlowQueryLog(t, config.SlowQueryConfig{RedactPatterns: []string{"^0x"}})
+	assert.Equal(t, []string{"100", redactedArg, "lighthouse"}, l.redactArgs(args))
+
+	l = newTestSlowQueryLog(t, config.SlowQueryConfig{RedactArgs: true})
+	assert.Equal(t, []string{redactedArg, redactedArg, redactedArg}, l.redactArgs(args))
+
+	_, err := NewSlowQueryLog(config.SlowQueryConfig{RedactPatterns: []string{"("}}, logrus.New())
+	require.Error(t, err)
+}
+
+func TestSlowQueryLog_RingBuffer(t *testing.T) {
+	l := newTestSlowQueryLog(t, config.SlowQueryConfig{Threshold: time.Millisecond, BufferSize: 3})
+	ctx := requestid.WithContext(context.Background(), "req-1")
+
+	for _, sql := range []string{"q1", "q2", "q3", "q4"} {
+		l.observe(ctx, SlowQuery{SQL: sql}, nil, time.Second)
+	}
+
+	recent := l.Recent(0)
+	require.Len(t, recent, 3)
+	assert.Equal(t, "q4", recent[0].SQL)
+	assert.Equal(t, "q2", recent[2].SQL)
+	assert.Equal(t, "req-1", recent[0].RequestID)
+
+	assert.Len(t, l.Recent(2), 2)
+}
+
+func TestSlowQueryLog_Handler(t *testing.T) {
+	l := newTestSlowQueryLog(t, config.SlowQueryConfig{Threshold: time.Second})
+	l.observe(context.Background(), SlowQuery{SQL: "q1"}, nil, 2*time.Second)
+	l.observe(context.Background(), SlowQuery{SQL: "q2"}, nil, 2*time.Second)
+
+	rec := httptest.NewRecorder()
+	l.Handler(rec, httptest.NewRequest(http.MethodGet, "/admin/slow-queries?limit=1", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body struct {
+		ThresholdMs int64       `json:"threshold_ms"`
+		Entries     []SlowQuery `json:"entries"`
+	}
+
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, int64(1000), body.ThresholdMs)
+	require.Len(t, body.Entries, 1)
+	assert.Equal(t, "q2", body.Entries[0].SQL)
+
+	rec = httptest.NewRecorder()
+	l.Handler(rec, httptest.NewRequest(http.MethodGet, "/admin/slow-queries?limit=abc", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slow.jsonl")
+
+	f, err := newRotatingFile(path, 64, 2)
+	require.NoError(t, err)
+
+	defer func() { _ = f.close() }()
+
+	for i := range 5 {
+		require.NoError(t, f.writeJSON(map[string]any{"n": i, "pad": "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"}))
+	}
+
+	assert.FileExists(t, path)
+	assert.FileExists(t, path+".1")
+	assert.FileExists(t, path+".2")
+	assert.NoFileExists(t, path+".3")
+
+	// The current file holds the newest line as valid JSON.
+	file, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	require.True(t, scanner.Scan())
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+	assert.InDelta(t, 4.0, line["n"], 0)
+}
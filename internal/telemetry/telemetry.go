@@ -7,6 +7,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -31,7 +32,7 @@ type service struct {
 	log           logrus.FieldLogger
 	traceProvider *sdktrace.TracerProvider
 	exporter      sdktrace.SpanExporter
-	networkName   string // Database name to use as network label
+	networkName   string // Network label for every span, empty when serving several networks
 }
 
 // Compile-time interface compliance check.
@@ -39,7 +40,8 @@ var _ Service = (*service)(nil)
 
 // NewService creates a new telemetry service
 // Returns the interface, not the struct (ethPandaOps pattern)
-// networkName should be the database name from ClickHouse config.
+// networkName labels every span; pass "" when serving several networks, whose spans are labelled
+// per request.
 func NewService(cfg *config.TelemetryConfig, networkName string, logger logrus.FieldLogger) Service {
 	return &service{
 		config: cfg,
@@ -165,12 +167,14 @@ func (s *service) createExporter(ctx context.Context) (sdktrace.SpanExporter, er
 
 // createResource creates resource with service identification.
 func (s *service) createResource() (*resource.Resource, error) {
-	return resource.Merge(
-		resource.Default(),
-		resource.NewSchemaless(
-			semconv.ServiceName(s.config.ServiceName),
-			semconv.ServiceVersion(version.Short()),
-			AttrNetworkName.String(s.networkName),
-		),
-	)
+	attrs := []attribute.KeyValue{
+		semconv.ServiceName(s.config.ServiceName),
+		semconv.ServiceVersion(version.Short()),
+	}
+
+	if s.networkName != "" {
+		attrs = append(attrs, AttrNetworkName.String(s.networkName))
+	}
+
+	return resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
 }